@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"gonews/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var convertDryRun bool
+
+var convertDescriptionsCmd = &cobra.Command{
+	Use:   "convert-descriptions",
+	Short: "convert legacy HTML descriptions into content blocks",
+	Long:  "convert legacy HTML descriptions into sanitized content blocks",
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunConvertDescriptions(convertDryRun)
+	},
+}
+
+func init() {
+	convertDescriptionsCmd.Flags().BoolVar(&convertDryRun, "dry-run", false, "report how many contents would be converted without writing")
+	rootCmd.AddCommand(convertDescriptionsCmd)
+}
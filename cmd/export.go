@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"gonews/internal/app"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportOut      string
+	exportFrom     string
+	exportTo       string
+	exportCategory string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "export users, categories and contents to a site archive",
+	Long:  "export users, categories, contents and images to a zip archive of NDJSON files with a media manifest; restore it with import --format archive",
+	Run: func(cmd *cobra.Command, args []string) {
+		if exportOut == "" {
+			exportOut = fmt.Sprintf("gonews-export-%s.zip", time.Now().UTC().Format("20060102-150405"))
+		}
+		app.RunExport(exportOut, exportFrom, exportTo, exportCategory)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportOut, "out", "", "archive file to write (default gonews-export-<time>.zip)")
+	exportCmd.Flags().StringVar(&exportFrom, "from", "", "only contents created on or after this date (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportTo, "to", "", "only contents created on or before this date (YYYY-MM-DD)")
+	exportCmd.Flags().StringVar(&exportCategory, "category", "", "only contents of the category with this slug")
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"gonews/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	importFormat string
+	importFile   string
+	importUser   string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import contents from a WordPress, JSON or CSV export, or restore a site archive",
+	Long:  "import users, categories, contents and images from a WordPress WXR, JSON or CSV export; already imported contents are skipped. With --format archive, restore a site archive written by export into an empty database",
+	Run: func(cmd *cobra.Command, args []string) {
+		if importFormat == "archive" {
+			app.RunRestore(importFile)
+			return
+		}
+		app.RunImport(importFormat, importFile, importUser)
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", "wxr", "export format: wxr, json, csv or archive")
+	importCmd.Flags().StringVar(&importFile, "file", "", "path to the export file")
+	importCmd.Flags().StringVar(&importUser, "user", "", "email of the user contents without an author are attributed to")
+	importCmd.MarkFlagRequired("file")
+	rootCmd.AddCommand(importCmd)
+}
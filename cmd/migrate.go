@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"gonews/internal/app"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var migrateDir string
+
+var errStepsInvalid = errors.New("N must be a positive number")
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "apply or revert database migrations",
+	Long:  "apply or revert the SQL migrations embedded in the binary; concurrent runs wait for each other",
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up [N]",
+	Short: "apply all pending migrations, or the next N",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := 0
+		if len(args) == 1 {
+			n, err := parseSteps(args[0])
+			if err != nil {
+				return err
+			}
+			steps = n
+		}
+
+		app.RunMigrateUp(steps)
+		return nil
+	},
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down N",
+	Short: "revert the last N migrations",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps, err := parseSteps(args[0])
+		if err != nil {
+			return err
+		}
+
+		app.RunMigrateDown(steps)
+		return nil
+	},
+}
+
+var migrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "list migrations and whether they are applied",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunMigrateStatus()
+	},
+}
+
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create name",
+	Short: "create an empty up and down migration in the source tree",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunMigrateCreate(migrateDir, args[0])
+	},
+}
+
+func parseSteps(raw string) (int, error) {
+	steps, err := strconv.Atoi(raw)
+	if err != nil || steps <= 0 {
+		return 0, errStepsInvalid
+	}
+
+	return steps, nil
+}
+
+func init() {
+	migrateCreateCmd.Flags().StringVar(&migrateDir, "dir", filepath.Join("database", "migrations"), "migrations directory")
+
+	migrateCmd.AddCommand(migrateUpCmd, migrateDownCmd, migrateStatusCmd, migrateCreateCmd)
+	rootCmd.AddCommand(migrateCmd)
+}
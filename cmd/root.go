@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"gonews/config"
+	"gonews/lib/logger"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -41,4 +43,6 @@ func initConfig()  {
 	if err := viper.ReadInConfig(); err != nil {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	logger.Setup(logger.New(config.NewConfig()))
 }
\ No newline at end of file
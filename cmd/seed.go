@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"gonews/internal/app"
+
+	"github.com/spf13/cobra"
+)
+
+var seedDev bool
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "seed the database with development data",
+	Long:  "seed the database with development data, including an admin@mail.com / admin123 account; refused without --dev and in production",
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunSeed(seedDev)
+	},
+}
+
+func init() {
+	seedCmd.Flags().BoolVar(&seedDev, "dev", false, "confirm seeding development data with a well-known admin password")
+	rootCmd.AddCommand(seedCmd)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"gonews/internal/app"
+	"gonews/internal/core/domain/entity"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var (
+	userEmail    string
+	userName     string
+	userRole     string
+	userPassword string
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "manage user accounts",
+	Long:  "create and manage user accounts in the configured database",
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a user",
+	Long:  "create a user; the password is prompted for unless --password is given",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := passwordFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		app.RunUserCreate(userEmail, userName, userRole, password)
+		return nil
+	},
+}
+
+var userSetPasswordCmd = &cobra.Command{
+	Use:   "set-password",
+	Short: "set the password of a user",
+	Long:  "set the password of a user; the password is prompted for unless --password is given",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := passwordFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		app.RunUserSetPassword(userEmail, password)
+		return nil
+	},
+}
+
+var userSetRoleCmd = &cobra.Command{
+	Use:   "set-role",
+	Short: "set the role of a user",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunUserSetRole(userEmail, userRole)
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list users",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunUserList()
+	},
+}
+
+var userDeactivateCmd = &cobra.Command{
+	Use:   "deactivate",
+	Short: "stop a user from logging in",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		app.RunUserDeactivate(userEmail)
+	},
+}
+
+// passwordFlag returns --password, or asks for the password so it does not
+// end up in the shell history. Piped input is read as a single line.
+func passwordFlag(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Changed("password") {
+		return userPassword, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New("no password given on stdin")
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(os.Stderr, "Repeat password: ")
+	repeated, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if string(password) != string(repeated) {
+		return "", errors.New("passwords do not match")
+	}
+
+	return string(password), nil
+}
+
+func init() {
+	roles := strings.Join(entity.Roles, ", ")
+
+	userCreateCmd.Flags().StringVar(&userEmail, "email", "", "email the user logs in with")
+	userCreateCmd.Flags().StringVar(&userName, "name", "", "display name")
+	userCreateCmd.Flags().StringVar(&userRole, "role", entity.RoleAuthor, "role: "+roles)
+	userCreateCmd.Flags().StringVar(&userPassword, "password", "", "password (prompted for when omitted)")
+	userCreateCmd.MarkFlagRequired("email")
+	userCreateCmd.MarkFlagRequired("name")
+
+	userSetPasswordCmd.Flags().StringVar(&userEmail, "email", "", "email of the user")
+	userSetPasswordCmd.Flags().StringVar(&userPassword, "password", "", "password (prompted for when omitted)")
+	userSetPasswordCmd.MarkFlagRequired("email")
+
+	userSetRoleCmd.Flags().StringVar(&userEmail, "email", "", "email of the user")
+	userSetRoleCmd.Flags().StringVar(&userRole, "role", "", "role: "+roles)
+	userSetRoleCmd.MarkFlagRequired("email")
+	userSetRoleCmd.MarkFlagRequired("role")
+
+	userDeactivateCmd.Flags().StringVar(&userEmail, "email", "", "email of the user")
+	userDeactivateCmd.MarkFlagRequired("email")
+
+	userCmd.AddCommand(userCreateCmd, userSetPasswordCmd, userSetRoleCmd, userListCmd, userDeactivateCmd)
+	rootCmd.AddCommand(userCmd)
+}
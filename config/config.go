@@ -8,6 +8,13 @@ type App struct {
 
 	JwtSecretKey string `json:"jwt_secret_key"`
 	JwtIssuer    string `json:"jwt_issuer"`
+
+	FrontendUrl string `json:"frontend_url"`
+
+	BodyLimit int `json:"body_limit"`
+
+	ProxyHeader    string `json:"proxy_header"`
+	TrustedProxies string `json:"trusted_proxies"`
 }
 
 type PsqlDB struct {
@@ -26,10 +33,142 @@ type ImageKitConfig struct {
     UrlEndpoint string `json:"url_endpoint"`
 }
 
+type Upload struct {
+	TusDir        string `json:"tus_dir"`
+	MaxSize       int64  `json:"max_size"`
+	MaxChunkSize  int    `json:"max_chunk_size"`
+	ExpireInHours int    `json:"expire_in_hours"`
+}
+
+type Image struct {
+	SigningKey   string `json:"signing_key"`
+	CacheDir     string `json:"cache_dir"`
+	MaxDimension int    `json:"max_dimension"`
+}
+
+type Feed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ItemCount   int    `json:"item_count"`
+}
+
+type Sitemap struct {
+	BaseUrl             string `json:"base_url"`
+	ChunkSize           int    `json:"chunk_size"`
+	NewsPublicationName string `json:"news_publication_name"`
+	NewsLanguage        string `json:"news_language"`
+}
+
+type Preview struct {
+	SigningKey    string `json:"signing_key"`
+	ExpireInHours int    `json:"expire_in_hours"`
+}
+
+type Trash struct {
+	RetentionDays int `json:"retention_days"`
+}
+
+type EditLock struct {
+	TtlSeconds int `json:"ttl_seconds"`
+}
+
+type Import struct {
+	Dir          string `json:"dir"`
+	MaxImageSize int64  `json:"max_image_size"`
+}
+
+type Health struct {
+	CheckTimeoutMs         int `json:"check_timeout_ms"`
+	StorageIntervalSeconds int `json:"storage_interval_seconds"`
+	ShutdownDelaySeconds   int `json:"shutdown_delay_seconds"`
+}
+
+type Metrics struct {
+	Token string `json:"token"`
+}
+
+type Tracing struct {
+	Exporter     string  `json:"exporter"`
+	OtlpEndpoint string  `json:"otlp_endpoint"`
+	ServiceName  string  `json:"service_name"`
+	SampleRatio  float64 `json:"sample_ratio"`
+}
+
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+type Redis struct {
+	Url string `json:"url"`
+}
+
+type RateLimitPolicy struct {
+	Requests      int `json:"requests"`
+	PeriodSeconds int `json:"period_seconds"`
+	Burst         int `json:"burst"`
+}
+
+type RateLimit struct {
+	Store  string          `json:"store"`
+	Login  RateLimitPolicy `json:"login"`
+	Public RateLimitPolicy `json:"public"`
+	Admin  RateLimitPolicy `json:"admin"`
+}
+
+type Cache struct {
+	Store      string `json:"store"`
+	TtlSeconds int    `json:"ttl_seconds"`
+	Size       int    `json:"size"`
+}
+
+type HttpCache struct {
+	MaxAgeSeconds               int    `json:"max_age_seconds"`
+	SharedMaxAgeSeconds         int    `json:"shared_max_age_seconds"`
+	StaleWhileRevalidateSeconds int    `json:"stale_while_revalidate_seconds"`
+	StaleIfErrorSeconds         int    `json:"stale_if_error_seconds"`
+	SurrogateKeyHeader          string `json:"surrogate_key_header"`
+}
+
+type CdnPurge struct {
+	Adapter        string `json:"adapter"`
+	WebhookUrl     string `json:"webhook_url"`
+	Token          string `json:"token"`
+	BaseUrl        string `json:"base_url"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+	Attempts       int    `json:"attempts"`
+	BackoffSeconds int    `json:"backoff_seconds"`
+}
+
+type Webhook struct {
+	TimeoutSeconds       int `json:"timeout_seconds"`
+	MaxAttempts          int `json:"max_attempts"`
+	BackoffSeconds       int `json:"backoff_seconds"`
+	DisableAfterFailures int `json:"disable_after_failures"`
+}
+
 type Config struct {
-	App  App
-	Psql PsqlDB
-	IK   ImageKitConfig `json:"imagekit"`
+	App       App
+	Psql      PsqlDB
+	IK        ImageKitConfig `json:"imagekit"`
+	Upload    Upload
+	Image     Image
+	Feed      Feed
+	Sitemap   Sitemap
+	Preview   Preview
+	Trash     Trash
+	EditLock  EditLock
+	Import    Import
+	Health    Health
+	Metrics   Metrics
+	Tracing   Tracing
+	Log       Log
+	Redis     Redis
+	RateLimit RateLimit
+	Cache     Cache
+	HttpCache HttpCache
+	CdnPurge  CdnPurge
+	Webhook   Webhook
 }
 
 func NewConfig() *Config {
@@ -40,6 +179,13 @@ func NewConfig() *Config {
 
 			JwtSecretKey: viper.GetString("JWT_SECRET_KEY"),
 			JwtIssuer:    viper.GetString("JWT_ISSUER"),
+
+			FrontendUrl: viper.GetString("APP_FRONTEND_URL"),
+
+			BodyLimit: viper.GetInt("APP_BODY_LIMIT"),
+
+			ProxyHeader:    viper.GetString("APP_PROXY_HEADER"),
+			TrustedProxies: viper.GetString("APP_TRUSTED_PROXIES"),
 		},
 		Psql: PsqlDB{
 			Host:      viper.GetString("DATABASE_HOST"),
@@ -55,5 +201,103 @@ func NewConfig() *Config {
 			PrivateKey:  viper.GetString("IMAGEKIT_PRIVATE_KEY"),
 			UrlEndpoint: viper.GetString("IMAGEKIT_URL_ENDPOINT"),
 		},
+		Upload: Upload{
+			TusDir:        viper.GetString("UPLOAD_TUS_DIR"),
+			MaxSize:       viper.GetInt64("UPLOAD_MAX_SIZE"),
+			MaxChunkSize:  viper.GetInt("UPLOAD_MAX_CHUNK_SIZE"),
+			ExpireInHours: viper.GetInt("UPLOAD_EXPIRE_IN_HOURS"),
+		},
+		Image: Image{
+			SigningKey:   viper.GetString("IMAGE_SIGNING_KEY"),
+			CacheDir:     viper.GetString("IMAGE_CACHE_DIR"),
+			MaxDimension: viper.GetInt("IMAGE_MAX_DIMENSION"),
+		},
+		Feed: Feed{
+			Title:       viper.GetString("FEED_TITLE"),
+			Description: viper.GetString("FEED_DESCRIPTION"),
+			ItemCount:   viper.GetInt("FEED_ITEM_COUNT"),
+		},
+		Sitemap: Sitemap{
+			BaseUrl:             viper.GetString("SITEMAP_BASE_URL"),
+			ChunkSize:           viper.GetInt("SITEMAP_CHUNK_SIZE"),
+			NewsPublicationName: viper.GetString("SITEMAP_NEWS_PUBLICATION_NAME"),
+			NewsLanguage:        viper.GetString("SITEMAP_NEWS_LANGUAGE"),
+		},
+		Preview: Preview{
+			SigningKey:    viper.GetString("PREVIEW_SIGNING_KEY"),
+			ExpireInHours: viper.GetInt("PREVIEW_EXPIRE_IN_HOURS"),
+		},
+		Trash: Trash{
+			RetentionDays: viper.GetInt("TRASH_RETENTION_DAYS"),
+		},
+		EditLock: EditLock{
+			TtlSeconds: viper.GetInt("EDIT_LOCK_TTL_SECONDS"),
+		},
+		Import: Import{
+			Dir:          viper.GetString("IMPORT_DIR"),
+			MaxImageSize: viper.GetInt64("IMPORT_MAX_IMAGE_SIZE"),
+		},
+		Health: Health{
+			CheckTimeoutMs:         viper.GetInt("HEALTH_CHECK_TIMEOUT_MS"),
+			StorageIntervalSeconds: viper.GetInt("HEALTH_STORAGE_INTERVAL_SECONDS"),
+			ShutdownDelaySeconds:   viper.GetInt("HEALTH_SHUTDOWN_DELAY_SECONDS"),
+		},
+		Metrics: Metrics{
+			Token: viper.GetString("METRICS_TOKEN"),
+		},
+		Tracing: Tracing{
+			Exporter:     viper.GetString("TRACING_EXPORTER"),
+			OtlpEndpoint: viper.GetString("TRACING_OTLP_ENDPOINT"),
+			ServiceName:  viper.GetString("TRACING_SERVICE_NAME"),
+			SampleRatio:  viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+		},
+		Log: Log{
+			Level:  viper.GetString("LOG_LEVEL"),
+			Format: viper.GetString("LOG_FORMAT"),
+		},
+		Redis: Redis{
+			Url: viper.GetString("REDIS_URL"),
+		},
+		RateLimit: RateLimit{
+			Store:  viper.GetString("RATE_LIMIT_STORE"),
+			Login:  rateLimitPolicy("LOGIN"),
+			Public: rateLimitPolicy("PUBLIC"),
+			Admin:  rateLimitPolicy("ADMIN"),
+		},
+		Cache: Cache{
+			Store:      viper.GetString("CACHE_STORE"),
+			TtlSeconds: viper.GetInt("CACHE_TTL_SECONDS"),
+			Size:       viper.GetInt("CACHE_SIZE"),
+		},
+		HttpCache: HttpCache{
+			MaxAgeSeconds:               viper.GetInt("HTTP_CACHE_MAX_AGE_SECONDS"),
+			SharedMaxAgeSeconds:         viper.GetInt("HTTP_CACHE_SHARED_MAX_AGE_SECONDS"),
+			StaleWhileRevalidateSeconds: viper.GetInt("HTTP_CACHE_STALE_WHILE_REVALIDATE_SECONDS"),
+			StaleIfErrorSeconds:         viper.GetInt("HTTP_CACHE_STALE_IF_ERROR_SECONDS"),
+			SurrogateKeyHeader:          viper.GetString("HTTP_CACHE_SURROGATE_KEY_HEADER"),
+		},
+		CdnPurge: CdnPurge{
+			Adapter:        viper.GetString("CDN_PURGE_ADAPTER"),
+			WebhookUrl:     viper.GetString("CDN_PURGE_WEBHOOK_URL"),
+			Token:          viper.GetString("CDN_PURGE_TOKEN"),
+			BaseUrl:        viper.GetString("CDN_PURGE_BASE_URL"),
+			TimeoutSeconds: viper.GetInt("CDN_PURGE_TIMEOUT_SECONDS"),
+			Attempts:       viper.GetInt("CDN_PURGE_ATTEMPTS"),
+			BackoffSeconds: viper.GetInt("CDN_PURGE_BACKOFF_SECONDS"),
+		},
+		Webhook: Webhook{
+			TimeoutSeconds:       viper.GetInt("WEBHOOK_TIMEOUT_SECONDS"),
+			MaxAttempts:          viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+			BackoffSeconds:       viper.GetInt("WEBHOOK_BACKOFF_SECONDS"),
+			DisableAfterFailures: viper.GetInt("WEBHOOK_DISABLE_AFTER_FAILURES"),
+		},
+	}
+}
+
+func rateLimitPolicy(group string) RateLimitPolicy {
+	return RateLimitPolicy{
+		Requests:      viper.GetInt("RATE_LIMIT_" + group + "_REQUESTS"),
+		PeriodSeconds: viper.GetInt("RATE_LIMIT_" + group + "_PERIOD_SECONDS"),
+		Burst:         viper.GetInt("RATE_LIMIT_" + group + "_BURST"),
 	}
 }
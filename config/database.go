@@ -2,7 +2,7 @@ package config
 
 import (
 	"fmt"
-	"gonews/database/seeds"
+	"gonews/lib/metrics"
 
 	"github.com/rs/zerolog/log"
 	"gorm.io/driver/postgres"
@@ -33,7 +33,10 @@ func (cfg Config) ConnectionPostgres() (*Postgres, error) {
 		return nil, err
 	}
 
-	seeds.SeedRoles(db)
+	if err = db.Use(metrics.GormPlugin{}); err != nil {
+		log.Error().Err(err).Msg("[ConnectionPostgres-3] Failed register metrics plugin")
+		return nil, err
+	}
 
 	sqlDB.SetMaxOpenConns(cfg.Psql.DBMaxOpen)
 	sqlDB.SetMaxIdleConns(cfg.Psql.DBMaxIdle)
@@ -0,0 +1,12 @@
+package database
+
+import "embed"
+
+// Migrations holds the SQL migrations compiled into the binary.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
+
+// MigrationsDir is where Migrations live, both in Migrations and in the
+// source tree.
+const MigrationsDir = "migrations"
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "content_attachments";
\ No newline at end of file
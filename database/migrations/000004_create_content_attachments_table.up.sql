@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS "content_attachments" (
+    id SERIAL PRIMARY KEY,
+    content_id INT REFERENCES contents(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    video_codec VARCHAR(20) NULL,
+    audio_codec VARCHAR(20) NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    sample_rate INT NOT NULL DEFAULT 0,
+    channels INT NOT NULL DEFAULT 0,
+    poster_url TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_attachments_content_id ON content_attachments(content_id);
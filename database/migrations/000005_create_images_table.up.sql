@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS "images" (
+    id SERIAL PRIMARY KEY,
+    url TEXT NOT NULL,
+    focal_x NUMERIC(4,3) NOT NULL DEFAULT 0.5,
+    focal_y NUMERIC(4,3) NOT NULL DEFAULT 0.5,
+    created_by_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_images_url ON images(url);
@@ -0,0 +1,2 @@
+ALTER TABLE "contents" DROP COLUMN IF EXISTS plain_text;
+ALTER TABLE "contents" DROP COLUMN IF EXISTS body;
@@ -0,0 +1,2 @@
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS body JSONB NULL;
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS plain_text TEXT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE "contents" DROP COLUMN IF EXISTS table_of_contents;
+ALTER TABLE "contents" DROP COLUMN IF EXISTS description_source;
+ALTER TABLE "contents" DROP COLUMN IF EXISTS body_format;
@@ -0,0 +1,4 @@
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS body_format VARCHAR(20) NOT NULL DEFAULT 'html';
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS description_source TEXT NULL;
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS table_of_contents JSONB NULL;
+UPDATE "contents" SET body_format = 'blocks' WHERE body IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS slug VARCHAR(250) NULL;
+UPDATE "contents" SET slug = trim(both '-' from lower(regexp_replace(title, '[^a-zA-Z0-9]+', '-', 'g'))) || '-' || id WHERE slug IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_contents_slug ON contents(slug);
+CREATE INDEX IF NOT EXISTS idx_contents_status_created_at ON contents(status, created_at);
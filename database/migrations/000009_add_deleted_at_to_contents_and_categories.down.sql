@@ -0,0 +1,10 @@
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS categories_created_by_id_fkey;
+ALTER TABLE "categories" ADD CONSTRAINT categories_created_by_id_fkey FOREIGN KEY (created_by_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE "contents" DROP CONSTRAINT IF EXISTS contents_created_by_id_fkey;
+ALTER TABLE "contents" ADD CONSTRAINT contents_created_by_id_fkey FOREIGN KEY (created_by_id) REFERENCES users(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_contents_deleted_at;
+
+ALTER TABLE "categories" DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE "contents" DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,11 @@
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_contents_deleted_at ON contents(deleted_at);
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories(deleted_at);
+
+-- removing an author must not take their articles and categories with them
+ALTER TABLE "contents" DROP CONSTRAINT IF EXISTS contents_created_by_id_fkey;
+ALTER TABLE "contents" ADD CONSTRAINT contents_created_by_id_fkey FOREIGN KEY (created_by_id) REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS categories_created_by_id_fkey;
+ALTER TABLE "categories" ADD CONSTRAINT categories_created_by_id_fkey FOREIGN KEY (created_by_id) REFERENCES users(id) ON DELETE SET NULL;
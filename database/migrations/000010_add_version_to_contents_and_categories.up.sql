@@ -0,0 +1,2 @@
+ALTER TABLE "contents" ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
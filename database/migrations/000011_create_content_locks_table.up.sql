@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS "content_locks" (
+    content_id INT PRIMARY KEY REFERENCES contents(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    acquired_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    heartbeat_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_content_locks_expires_at ON content_locks(expires_at);
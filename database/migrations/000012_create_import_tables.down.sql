@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "redirects";
+DROP TABLE IF EXISTS "import_mappings";
+DROP TABLE IF EXISTS "import_jobs";
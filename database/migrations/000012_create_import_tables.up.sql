@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS "import_jobs" (
+    id SERIAL PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    file_path TEXT NOT NULL DEFAULT '',
+    source_url TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    stats JSONB NOT NULL DEFAULT '{}',
+    errors JSONB NOT NULL DEFAULT '[]',
+    error TEXT NOT NULL DEFAULT '',
+    created_by_id INT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP NULL,
+    finished_at TIMESTAMP NULL
+);
+
+CREATE INDEX idx_import_jobs_status ON import_jobs(status, id);
+
+-- remembers what an earlier run already created so re-runs skip it
+CREATE TABLE IF NOT EXISTS "import_mappings" (
+    id SERIAL PRIMARY KEY,
+    source_type VARCHAR(20) NOT NULL,
+    source_key TEXT NOT NULL,
+    target_id INT NULL,
+    target_url TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (source_type, source_key)
+);
+
+CREATE TABLE IF NOT EXISTS "redirects" (
+    id SERIAL PRIMARY KEY,
+    from_path TEXT NOT NULL UNIQUE,
+    content_id INT NOT NULL REFERENCES contents(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_redirects_content_id ON redirects(content_id);
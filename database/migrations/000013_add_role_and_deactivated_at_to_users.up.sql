@@ -0,0 +1,4 @@
+-- every account so far was created as an admin
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'admin';
+ALTER TABLE "users" ALTER COLUMN role SET DEFAULT 'author';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP NULL;
@@ -0,0 +1,2 @@
+-- running jobs stamp it while they make progress; a stale one was cut off
+ALTER TABLE "import_jobs" ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP NULL;
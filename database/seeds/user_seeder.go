@@ -1,6 +1,7 @@
 package seeds
 
 import (
+	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/domain/model"
 	"gonews/lib/conv"
 
@@ -8,7 +9,10 @@ import (
 	"gorm.io/gorm"
 )
 
-func SeedRoles(db *gorm.DB) {
+// SeedDevAdmin creates the well-known admin@mail.com / admin123 account.
+// It is for local development only; real accounts are created with the
+// user command.
+func SeedDevAdmin(db *gorm.DB) {
 	bytes, err := conv.HashPassword("admin123")
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error when creating hash password")
@@ -18,6 +22,7 @@ func SeedRoles(db *gorm.DB) {
 		Name: "Admin",
 		Email: "admin@mail.com",
 		Password: string(bytes),
+		Role: entity.RoleAdmin,
 	}
 
 	if err := db.FirstOrCreate(&admin, model.User{Email: "admin@mail.com"}).Error; err != nil {
@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache stores encoded values under a key for a while. Each value carries
+// tags, and invalidating a tag drops every value stored with it, so writers
+// do not need to know which keys readers built.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+type noopCache struct{}
+
+// NewNoopCache returns a cache that stores nothing, for when caching is
+// turned off.
+func NewNoopCache() Cache {
+	return noopCache{}
+}
+
+// Get implements Cache.
+func (noopCache) Get(_ context.Context, _ string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+// Set implements Cache.
+func (noopCache) Set(_ context.Context, _ string, _ []byte, _ time.Duration, _ []string) error {
+	return nil
+}
+
+// InvalidateTags implements Cache.
+func (noopCache) InvalidateTags(_ context.Context, _ ...string) error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	tags      []string
+	expiresAt time.Time
+}
+
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	order *list.List
+	tags  map[string]map[string]struct{}
+}
+
+// NewLRUCache keeps up to size values in process, dropping the least
+// recently used first. Invalidation only reaches this instance, so with
+// several instances the others serve stale values until they expire.
+func NewLRUCache(size int) Cache {
+	return &lruCache{
+		size:  size,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		tags:  make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Cache.
+func (l *lruCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		l.remove(elem)
+		return nil, false, nil
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set implements Cache.
+func (l *lruCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		l.remove(elem)
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{
+		key:       key,
+		value:     value,
+		tags:      tags,
+		expiresAt: time.Now().Add(ttl),
+	})
+	for _, tag := range tags {
+		keys, ok := l.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			l.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for l.order.Len() > l.size {
+		l.remove(l.order.Back())
+	}
+
+	return nil
+}
+
+// InvalidateTags implements Cache.
+func (l *lruCache) InvalidateTags(_ context.Context, tags ...string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range l.tags[tag] {
+			if elem, ok := l.items[key]; ok {
+				l.remove(elem)
+			}
+		}
+		delete(l.tags, tag)
+	}
+
+	return nil
+}
+
+func (l *lruCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	l.order.Remove(elem)
+	delete(l.items, entry.key)
+
+	for _, tag := range entry.tags {
+		keys := l.tags[tag]
+		delete(keys, entry.key)
+		if len(keys) == 0 {
+			delete(l.tags, tag)
+		}
+	}
+}
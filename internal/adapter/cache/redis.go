@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const redisKeyPrefix = "gonews:cache:"
+
+// setScript stores the value and adds its key to a set per tag. A tag set
+// lives as long as the longest value in it; keys that expired before their
+// tag was invalidated are left in the set and deleting them is a no-op.
+const setScript = `
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+for i = 2, #KEYS do
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if redis.call('PTTL', KEYS[i]) < tonumber(ARGV[2]) then
+		redis.call('PEXPIRE', KEYS[i], ARGV[2])
+	end
+end
+return 1`
+
+// invalidateScript deletes the keys of each tag set, then the set.
+const invalidateScript = `
+local deleted = 0
+for i = 1, #KEYS do
+	local keys = redis.call('SMEMBERS', KEYS[i])
+	for j = 1, #keys, 500 do
+		deleted = deleted + redis.call('DEL', unpack(keys, j, math.min(j + 499, #keys)))
+	end
+	redis.call('DEL', KEYS[i])
+end
+return deleted`
+
+var (
+	setScriptRedis        = redis.NewScript(setScript)
+	invalidateScriptRedis = redis.NewScript(invalidateScript)
+)
+
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache shares values between instances through Redis or a
+// compatible server. The scripts touch several keys, so a Redis Cluster is
+// not supported.
+func NewRedisCache(client *redis.Client) Cache {
+	return &redisCache{client: client}
+}
+
+// Get implements Cache.
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+// Set implements Cache.
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	keys := []string{redisKeyPrefix + key}
+	for _, tag := range tags {
+		keys = append(keys, tagKey(tag))
+	}
+
+	return setScriptRedis.Run(ctx, r.client, keys, value, strconv.FormatInt(ttl.Milliseconds(), 10)).Err()
+}
+
+// InvalidateTags implements Cache.
+func (r *redisCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, tagKey(tag))
+	}
+
+	return invalidateScriptRedis.Run(ctx, r.client, keys).Err()
+}
+
+func tagKey(tag string) string {
+	return redisKeyPrefix + "tag:" + tag
+}
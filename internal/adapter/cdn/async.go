@@ -0,0 +1,154 @@
+package cdn
+
+import (
+	"context"
+	"gonews/lib/metrics"
+	"gonews/lib/tracing"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultQueueSize = 256
+	maxBackoff       = time.Minute
+)
+
+type purgeJob struct {
+	ctx     context.Context
+	req     PurgeRequest
+	attempt int
+	delay   time.Duration
+}
+
+// AsyncPurger queues purges and sends them to the wrapped purger from a
+// background worker, so writes do not wait on the CDN. A failed purge is
+// retried with exponential backoff, up to attempts tries in all; it waits
+// on a timer, so the purges queued behind it go out in the meantime.
+type AsyncPurger struct {
+	purger   CachePurger
+	attempts int
+	backoff  time.Duration
+
+	mu     sync.Mutex
+	closed bool
+	queue  chan purgeJob
+	retry  chan purgeJob
+	done   chan struct{}
+
+	// pending counts the retries waiting on a timer; only the worker
+	// touches it
+	pending int
+}
+
+// NewAsyncPurger starts the worker. Close stops it.
+func NewAsyncPurger(purger CachePurger, attempts int, backoff time.Duration, size int) *AsyncPurger {
+	if attempts <= 0 {
+		attempts = 1
+	}
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if size <= 0 {
+		size = defaultQueueSize
+	}
+
+	a := &AsyncPurger{
+		purger:   purger,
+		attempts: attempts,
+		backoff:  backoff,
+		queue:    make(chan purgeJob, size),
+		retry:    make(chan purgeJob),
+		done:     make(chan struct{}),
+	}
+	go a.run()
+
+	return a
+}
+
+// Purge implements CachePurger. It only queues the purge; the request
+// context is detached, keeping its logger and trace, as the purge outlives
+// the request.
+func (a *AsyncPurger) Purge(ctx context.Context, req PurgeRequest) error {
+	if len(req.Paths) == 0 && len(req.Prefixes) == 0 && len(req.Keys) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return ErrQueueClosed
+	}
+
+	job := purgeJob{ctx: zerolog.Ctx(ctx).WithContext(tracing.Detach(ctx)), req: req, delay: a.backoff}
+	select {
+	case a.queue <- job:
+		return nil
+	default:
+		metrics.CdnPurges.WithLabelValues(metrics.CdnPurgeDropped).Inc()
+		zerolog.Ctx(ctx).Error().Err(ErrQueueFull).Strs("keys", req.Keys).Strs("paths", req.Paths).Strs("prefixes", req.Prefixes).Msg("[ADAPTER] AsyncPurger - 3")
+		return ErrQueueFull
+	}
+}
+
+// Close stops taking purges and waits for the queued ones and their retries
+// to be sent, or for ctx to end.
+func (a *AsyncPurger) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (a *AsyncPurger) run() {
+	defer close(a.done)
+
+	queue := a.queue
+	for queue != nil || a.pending > 0 {
+		select {
+		case job, ok := <-queue:
+			if !ok {
+				queue = nil
+				continue
+			}
+			a.send(job)
+		case job := <-a.retry:
+			a.pending--
+			a.send(job)
+		}
+	}
+}
+
+func (a *AsyncPurger) send(job purgeJob) {
+	job.attempt++
+	err := a.purger.Purge(job.ctx, job.req)
+	if err == nil {
+		metrics.CdnPurges.WithLabelValues(metrics.CdnPurgeSucceeded).Inc()
+		return
+	}
+
+	if job.attempt >= a.attempts {
+		metrics.CdnPurges.WithLabelValues(metrics.CdnPurgeFailed).Inc()
+		zerolog.Ctx(job.ctx).Error().Err(err).Int("attempt", job.attempt).Strs("keys", job.req.Keys).Msg("[ADAPTER] AsyncPurger - 1")
+		return
+	}
+
+	zerolog.Ctx(job.ctx).Warn().Err(err).Int("attempt", job.attempt).Dur("retry_in", job.delay).Msg("[ADAPTER] AsyncPurger - 2")
+	a.pending++
+	delay := job.delay
+	job.delay = min(job.delay*2, maxBackoff)
+	time.AfterFunc(delay, func() {
+		a.retry <- job
+	})
+}
@@ -0,0 +1,39 @@
+package cdn
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrQueueFull   = errors.New("cdn purge queue is full")
+	ErrQueueClosed = errors.New("cdn purge queue is closed")
+)
+
+// PurgeRequest names what a CDN should drop: the responses under Paths, every
+// response whose path starts with one of Prefixes, both relative to the
+// public URL of the API, and every response tagged with one of the surrogate
+// Keys.
+type PurgeRequest struct {
+	Paths    []string
+	Prefixes []string
+	Keys     []string
+}
+
+// CachePurger drops stale copies from the CDN in front of the API.
+type CachePurger interface {
+	Purge(ctx context.Context, req PurgeRequest) error
+}
+
+type noopPurger struct{}
+
+// NewNoopPurger returns a purger that does nothing, for when no CDN is
+// configured.
+func NewNoopPurger() CachePurger {
+	return noopPurger{}
+}
+
+// Purge implements CachePurger.
+func (noopPurger) Purge(_ context.Context, _ PurgeRequest) error {
+	return nil
+}
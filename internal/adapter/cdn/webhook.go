@@ -0,0 +1,88 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gonews/config"
+	"gonews/lib/tracing"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+type webhookPurger struct {
+	cfg    *config.Config
+	client *http.Client
+}
+
+// NewWebhookPurger posts purges as JSON to CDN_PURGE_WEBHOOK_URL, for a CDN
+// API or a small function translating to one:
+//
+//	{"urls": ["https://api.example.com/api/fe/contents/42"], "prefixes": [], "keys": ["content:42"]}
+//
+// Any 2xx answer counts as done.
+func NewWebhookPurger(cfg *config.Config) CachePurger {
+	timeout := time.Duration(cfg.CdnPurge.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+
+	return &webhookPurger{
+		cfg:    cfg,
+		client: &http.Client{Transport: tracing.Transport(http.DefaultTransport), Timeout: timeout},
+	}
+}
+
+// Purge implements CachePurger.
+func (w *webhookPurger) Purge(ctx context.Context, req PurgeRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "cdn.purge")
+	defer tracing.End(span, &err)
+
+	baseUrl := strings.TrimRight(w.cfg.CdnPurge.BaseUrl, "/")
+	payload := struct {
+		Urls     []string `json:"urls"`
+		Prefixes []string `json:"prefixes"`
+		Keys     []string `json:"keys"`
+	}{Urls: []string{}, Prefixes: []string{}, Keys: req.Keys}
+	for _, path := range req.Paths {
+		payload.Urls = append(payload.Urls, baseUrl+path)
+	}
+	for _, prefix := range req.Prefixes {
+		payload.Prefixes = append(payload.Prefixes, baseUrl+prefix)
+	}
+	if payload.Keys == nil {
+		payload.Keys = []string{}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode purge: %w", err)
+	}
+
+	reqHttp, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.CdnPurge.WebhookUrl, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	reqHttp.Header.Set("Content-Type", "application/json")
+	if w.cfg.CdnPurge.Token != "" {
+		reqHttp.Header.Set("Authorization", "Bearer "+w.cfg.CdnPurge.Token)
+	}
+
+	resp, err := w.client.Do(reqHttp)
+	if err != nil {
+		return fmt.Errorf("purge failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("purge failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type ImageCache interface {
+	GetImage(key string) ([]byte, bool)
+	PutImage(key string, data []byte) error
+}
+
+type imageCache struct {
+	dir string
+}
+
+func NewImageCache(dir string) (ImageCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache dir: %w", err)
+	}
+
+	return &imageCache{dir: dir}, nil
+}
+
+func (ic *imageCache) path(key string) string {
+	// Spread entries over sub directories to keep listings small.
+	return filepath.Join(ic.dir, key[:2], key)
+}
+
+func (ic *imageCache) GetImage(key string) ([]byte, bool) {
+	data, err := os.ReadFile(ic.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	return data, true
+}
+
+func (ic *imageCache) PutImage(key string, data []byte) error {
+	path := ic.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create image cache dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	tmp.Close()
+
+	if err = os.Rename(tmp.Name(), path); err != nil && !errors.Is(err, os.ErrExist) {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store cache file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package filestore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/tus"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type TusStore interface {
+	NewUpload(info entity.UploadEntity) error
+	GetUpload(id string) (*entity.UploadEntity, error)
+	SaveUpload(info entity.UploadEntity) error
+	WriteChunk(id string, offset int64, src io.Reader) (*entity.UploadEntity, error)
+	ClaimCompletion(id string) (*entity.UploadEntity, bool, error)
+	ListUploads() ([]entity.UploadEntity, error)
+	DeleteUpload(id string) error
+	DeleteData(id string) error
+	BinPath(id string) string
+}
+
+type tusStore struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func NewTusStore(dir string) (TusStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload dir: %w", err)
+	}
+
+	return &tusStore{dir: dir, locks: map[string]*sync.Mutex{}}, nil
+}
+
+func (s *tusStore) lock(id string) func() {
+	s.mu.Lock()
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+	s.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+func (s *tusStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+func (s *tusStore) BinPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *tusStore) NewUpload(info entity.UploadEntity) error {
+	file, err := os.OpenFile(s.BinPath(info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create upload file: %w", err)
+	}
+	file.Close()
+
+	return s.SaveUpload(info)
+}
+
+func (s *tusStore) GetUpload(id string) (*entity.UploadEntity, error) {
+	if strings.ContainsAny(id, `/\.`) {
+		return nil, tus.ErrUploadNotFound
+	}
+
+	data, err := os.ReadFile(s.infoPath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, tus.ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to read upload info: %w", err)
+	}
+
+	var info entity.UploadEntity
+	if err = json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode upload info: %w", err)
+	}
+
+	return &info, nil
+}
+
+func (s *tusStore) SaveUpload(info entity.UploadEntity) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to encode upload info: %w", err)
+	}
+
+	tmpPath := s.infoPath(info.ID) + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write upload info: %w", err)
+	}
+
+	return os.Rename(tmpPath, s.infoPath(info.ID))
+}
+
+// WriteChunk appends src to the upload at offset. The offset has to match the
+// stored one, and whatever was written is kept even if src fails halfway.
+func (s *tusStore) WriteChunk(id string, offset int64, src io.Reader) (*entity.UploadEntity, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	info, err := s.GetUpload(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Offset != offset {
+		return info, tus.ErrOffsetMismatch
+	}
+
+	// The data of a finished upload may already be gone, so a retried final
+	// PATCH only reports the offset.
+	if info.IsComplete() {
+		var extra [1]byte
+		if n, _ := src.Read(extra[:]); n > 0 {
+			return info, tus.ErrMaxSizeExceeded
+		}
+		return info, nil
+	}
+
+	file, err := os.OpenFile(s.BinPath(id), os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek upload file: %w", err)
+	}
+
+	remaining := info.Size - info.Offset
+	written, copyErr := io.Copy(file, io.LimitReader(src, remaining))
+	info.Offset += written
+
+	if err = s.SaveUpload(*info); err != nil {
+		return nil, err
+	}
+
+	if copyErr != nil {
+		return info, fmt.Errorf("failed to write chunk: %w", copyErr)
+	}
+
+	if written == remaining {
+		var extra [1]byte
+		if n, _ := src.Read(extra[:]); n > 0 {
+			return info, tus.ErrMaxSizeExceeded
+		}
+	}
+
+	return info, nil
+}
+
+// ClaimCompletion marks a complete upload as completing and reports whether
+// this call did so, which only one caller ever sees. Other callers get the
+// upload as it is, being completed elsewhere or already done.
+func (s *tusStore) ClaimCompletion(id string) (*entity.UploadEntity, bool, error) {
+	unlock := s.lock(id)
+	defer unlock()
+
+	info, err := s.GetUpload(id)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !info.IsComplete() || info.State != "" || info.Url != "" {
+		return info, false, nil
+	}
+
+	info.State = entity.UploadStateCompleting
+	if err = s.SaveUpload(*info); err != nil {
+		return nil, false, err
+	}
+
+	return info, true, nil
+}
+
+func (s *tusStore) ListUploads() ([]entity.UploadEntity, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.info"))
+	if err != nil {
+		return nil, err
+	}
+
+	uploads := []entity.UploadEntity{}
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), ".info")
+		info, err := s.GetUpload(id)
+		if err != nil {
+			continue
+		}
+		uploads = append(uploads, *info)
+	}
+
+	return uploads, nil
+}
+
+func (s *tusStore) DeleteUpload(id string) error {
+	unlock := s.lock(id)
+	defer unlock()
+
+	for _, path := range []string{s.BinPath(id), s.infoPath(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove upload file: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.locks, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// DeleteData drops the chunk data but keeps the upload info, so a finished
+// upload can still be looked up until it expires.
+func (s *tusStore) DeleteData(id string) error {
+	if err := os.Remove(s.BinPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove upload file: %w", err)
+	}
+
+	return nil
+}
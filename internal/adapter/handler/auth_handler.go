@@ -9,7 +9,7 @@ import (
 
 	// "github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 )
 
 var err error
@@ -32,7 +32,7 @@ func (a *authHandler) Login(c *fiber.Ctx) error {
 
 	if err = c.BodyParser(&req); err != nil {
 		code = "[HANDLER] Login - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -41,7 +41,7 @@ func (a *authHandler) Login(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(req); err != nil {
 		code = "[HANDLER] Login - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -53,10 +53,10 @@ func (a *authHandler) Login(c *fiber.Ctx) error {
 		Password: req.Password,
 	}
 
-	result, err := a.authService.GetUserByEmail(c.Context(), reqLogin)
+	result, err := a.authService.GetUserByEmail(c.UserContext(), reqLogin)
 	if err != nil {
 		code = "[HANDLER] Login - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/backup"
+	"gonews/lib/tracing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type BackupHandler interface {
+	Export(c *fiber.Ctx) error
+}
+
+type backupHandler struct {
+	backupService service.BackupService
+}
+
+// Export implements BackupHandler. The archive is streamed as it is
+// written; an export that fails halfway ends without a manifest, which a
+// restore refuses.
+func (bh *backupHandler) Export(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] Export - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	filter, err := backup.ParseFilter(c.Query("from"), c.Query("to"), c.Query("category"))
+	if err != nil {
+		code = "[HANDLER] Export - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	fileName := fmt.Sprintf("gonews-export-%s.zip", time.Now().UTC().Format("20060102-150405"))
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, fileName))
+
+	// the stream is written after the handler returns, so only the trace and
+	// logger of the request are carried over
+	ctx := zerolog.Ctx(c.UserContext()).WithContext(tracing.Detach(c.UserContext()))
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := bh.backupService.Export(ctx, w, filter); err != nil {
+			code = "[HANDLER] Export - 3"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+func NewBackupHandler(backupService service.BackupService) BackupHandler {
+	return &backupHandler{backupService: backupService}
+}
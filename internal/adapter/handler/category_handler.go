@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"gonews/internal/adapter/handler/request"
 	"gonews/internal/adapter/handler/response"
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/service"
 	"gonews/lib/conv"
+	"gonews/lib/httpcache"
+	"gonews/lib/revision"
 	validatorLib "gonews/lib/validator"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
 )
 
 var defaultSuccessResponse response.DefaultSuccessResponse
@@ -20,6 +25,9 @@ type CategoryHandler interface {
 	CreateCategory(c *fiber.Ctx) error
 	EditCategory(c *fiber.Ctx) error
 	DeleteCategory(c *fiber.Ctx) error
+	GetTrashedCategories(c *fiber.Ctx) error
+	RestoreCategory(c *fiber.Ctx) error
+	PurgeCategory(c *fiber.Ctx) error
 
 	GetCategoryFE(c *fiber.Ctx) error
 }
@@ -29,18 +37,25 @@ type categoryHandler struct {
 }
 
 func (ch *categoryHandler) GetCategoryFE(c *fiber.Ctx) error {
-	results, err := ch.categoryService.GetCategories(c.Context())
+	results, err := ch.categoryService.GetPublicCategories(c.UserContext())
 	if err != nil {
 		code = "[HANDLER] GetCategoryFE - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
+	var lastModified time.Time
 	categoryResponses := []response.SuccessCategoryResponse{}
 	for _, result := range results {
+		for _, changed := range []time.Time{result.UpdatedAt, result.User.UpdatedAt} {
+			if changed.After(lastModified) {
+				lastModified = changed
+			}
+		}
+
 		categoryResponse := response.SuccessCategoryResponse{
 			ID:            result.ID,
 			Title:         result.Title,
@@ -54,6 +69,7 @@ func (ch *categoryHandler) GetCategoryFE(c *fiber.Ctx) error {
 	defaultSuccessResponse.Meta.Message = "Categories fetched successfully"
 	defaultSuccessResponse.Data = categoryResponses
 
+	httpcache.Mark(c, lastModified, service.CacheTagCategories)
 	return c.JSON(defaultSuccessResponse)
 }
 
@@ -65,7 +81,7 @@ func (ch *categoryHandler) CreateCategory(c *fiber.Ctx) error {
 
 	if userID == 0 {
 		code = "[HANDLER] GetCategories - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -74,7 +90,7 @@ func (ch *categoryHandler) CreateCategory(c *fiber.Ctx) error {
 
 	if err = c.BodyParser(&req); err != nil {
 		code = "[HANDLER] CreateCategory - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -83,7 +99,7 @@ func (ch *categoryHandler) CreateCategory(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(req); err != nil {
 		code = "[HANDLER] CreateCategory -3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -97,10 +113,10 @@ func (ch *categoryHandler) CreateCategory(c *fiber.Ctx) error {
 		},
 	}
 
-	err = ch.categoryService.CreateCategory(c.Context(), reqEntity) 
+	err = ch.categoryService.CreateCategory(c.UserContext(), reqEntity) 
 	if err != nil {
 		code = "[HANDLER] CreateCategory - 4"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Message = err.Error()
 
@@ -121,7 +137,7 @@ func (ch *categoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	
 	if userID == 0 {
 		code = "[HANDLER] DeleteCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -132,19 +148,33 @@ func (ch *categoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	id, err := conv.StringToInt64(idParams)
 	if err != nil {
 		code = "[HANDLER] DeleteCategory - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	err = ch.categoryService.DeleteCategory(c.Context(), id)
+	version, err := revision.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		code = "[HANDLER] DeleteCategory - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return ifMatchFailed(c, err)
+	}
+
+	err = ch.categoryService.DeleteCategory(c.UserContext(), id, version)
 	if err != nil {
 		code = "[HANDLER] DeleteCategory - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
+		switch {
+		case errors.Is(err, revision.ErrRevisionConflict):
+			return ch.categoryPreconditionFailed(c, id)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
@@ -156,6 +186,145 @@ func (ch *categoryHandler) DeleteCategory(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
 }
 
+// GetTrashedCategories implements CategoryHandler.
+func (ch *categoryHandler) GetTrashedCategories(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] GetTrashedCategories - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	results, err := ch.categoryService.GetTrashedCategories(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetTrashedCategories - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	var lastModified time.Time
+	categoryResponses := []response.SuccessCategoryResponse{}
+	for _, result := range results {
+		for _, changed := range []time.Time{result.UpdatedAt, result.User.UpdatedAt} {
+			if changed.After(lastModified) {
+				lastModified = changed
+			}
+		}
+
+		categoryResponse := response.SuccessCategoryResponse{
+			ID:            result.ID,
+			Title:         result.Title,
+			Slug:          result.Slug,
+			CreatedByName: result.User.Name,
+		}
+		if result.DeletedAt != nil {
+			categoryResponse.DeletedAt = result.DeletedAt.Local().String()
+		}
+		categoryResponses = append(categoryResponses, categoryResponse)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Categories fetched successfully"
+	defaultSuccessResponse.Data = categoryResponses
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// RestoreCategory implements CategoryHandler.
+func (ch *categoryHandler) RestoreCategory(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] RestoreCategory - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	id, err := conv.StringToInt64(c.Params("categoryID"))
+	if err != nil {
+		code = "[HANDLER] RestoreCategory - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ch.categoryService.RestoreCategory(c.UserContext(), id)
+	if err != nil {
+		code = "[HANDLER] RestoreCategory - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "Category restored successfully"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// PurgeCategory implements CategoryHandler.
+func (ch *categoryHandler) PurgeCategory(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] PurgeCategory - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	id, err := conv.StringToInt64(c.Params("categoryID"))
+	if err != nil {
+		code = "[HANDLER] PurgeCategory - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ch.categoryService.PurgeCategory(c.UserContext(), id)
+	if err != nil {
+		code = "[HANDLER] PurgeCategory - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "Category purged successfully"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
 // EditCategory implements CategoryHandler.
 func (ch *categoryHandler) EditCategory(c *fiber.Ctx) error {
 	var req request.CategoryRequest
@@ -164,7 +333,7 @@ func (ch *categoryHandler) EditCategory(c *fiber.Ctx) error {
 
 	if userID == 0 {
 		code = "[HANDLER] EditCategoriesByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -173,7 +342,7 @@ func (ch *categoryHandler) EditCategory(c *fiber.Ctx) error {
 
 	if err = c.BodyParser(&req); err != nil {
 		code = "[HANDLER] EditCategoriesByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -182,7 +351,7 @@ func (ch *categoryHandler) EditCategory(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(req); err != nil {
 		code = "[HANDLER] EditCategoriesByID -3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -193,26 +362,42 @@ func (ch *categoryHandler) EditCategory(c *fiber.Ctx) error {
 	id, err := conv.StringToInt64(idParams)
 	if err != nil {
 		code = "[HANDLER] EditCategoryByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
+	version, err := revision.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		code = "[HANDLER] EditCategoryByID - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return ifMatchFailed(c, err)
+	}
+
 	reqEntity := entity.CategoryEntity{
 		ID: id,
 		Title: req.Title,
 		User: entity.UserEntity{
 			ID: int64(userID),
 		},
+		Version: version,
 	}
 
-	err = ch.categoryService.EditCategory(c.Context(), reqEntity)
+	err = ch.categoryService.EditCategory(c.UserContext(), reqEntity)
 	if err != nil {
 		code = "[HANDLER] EditCategoryByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
+
+		switch {
+		case errors.Is(err, revision.ErrRevisionConflict):
+			return ch.categoryPreconditionFailed(c, id)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -229,28 +414,36 @@ func (ch *categoryHandler) GetCategories(c *fiber.Ctx) error {
 	userId := claims.UserID
 	if userId == 0 {
 		code = "[HANDLER] GetCategories - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
 	}
 
-	results, err := ch.categoryService.GetCategories(c.Context())
+	results, err := ch.categoryService.GetCategories(c.UserContext())
 	if err != nil {
 		code = "[HANDLER] GetCategories - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
+	var lastModified time.Time
 	categoryResponses := []response.SuccessCategoryResponse{}
 	for _, result := range results {
+		for _, changed := range []time.Time{result.UpdatedAt, result.User.UpdatedAt} {
+			if changed.After(lastModified) {
+				lastModified = changed
+			}
+		}
+
 		categoryResponse := response.SuccessCategoryResponse{
 			ID: result.ID,
 			Title: result.Title,
 			Slug: result.Slug,
 			CreatedByName: result.User.Name,
+			Version: result.Version,
 		}
 		categoryResponses = append(categoryResponses, categoryResponse)
 	}
@@ -268,7 +461,7 @@ func (ch *categoryHandler) GetCategoryByID(c *fiber.Ctx) error {
 	userId := claims.UserID
 	if userId == 0 {
 		code = "[HANDLER] GetCategoryByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
@@ -278,18 +471,21 @@ func (ch *categoryHandler) GetCategoryByID(c *fiber.Ctx) error {
 	id, err := conv.StringToInt64(idParams)
 	if err != nil {
 		code = "[HANDLER] GetCategoryByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	result, err := ch.categoryService.GetCategoryByID(c.Context(), id)
+	result, err := ch.categoryService.GetCategoryByID(c.UserContext(), id)
 	if err != nil {
 		code = "[HANDLER] GetCategoryByID - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -298,6 +494,7 @@ func (ch *categoryHandler) GetCategoryByID(c *fiber.Ctx) error {
 		Title: result.Title,
 		Slug: result.Slug,
 		CreatedByName: result.User.Name,
+		Version: result.Version,
 	}
 
 	defaultSuccessResponse.Meta.Status = true
@@ -305,9 +502,28 @@ func (ch *categoryHandler) GetCategoryByID(c *fiber.Ctx) error {
 	defaultSuccessResponse.Meta.Message = "categories detail fetched successfuly"
 	defaultSuccessResponse.Data = categoryResponse
 
+	c.Set(fiber.HeaderETag, revision.ETag(result.Version))
 	return c.JSON(defaultSuccessResponse)
 }
 
+// categoryPreconditionFailed answers a stale If-Match with the version the
+// category has now.
+func (ch *categoryHandler) categoryPreconditionFailed(c *fiber.Ctx, id int64) error {
+	current, err := ch.categoryService.GetCategoryByID(c.UserContext(), id)
+	if err != nil {
+		code = "[HANDLER] categoryPreconditionFailed - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	return preconditionFailed(c, current.Version)
+}
+
 func NewCategoryHandler(categoryService service.CategoryService) CategoryHandler {
 	return &categoryHandler{categoryService: categoryService}
 }
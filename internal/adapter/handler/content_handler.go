@@ -1,19 +1,28 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"gonews/internal/adapter/handler/request"
 	"gonews/internal/adapter/handler/response"
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/service"
+	"gonews/lib/blocks"
 	"gonews/lib/conv"
+	"gonews/lib/httpcache"
+	"gonews/lib/metrics"
+	"gonews/lib/mediaprobe"
+	"gonews/lib/preview"
+	"gonews/lib/revision"
 	validatorLib "gonews/lib/validator"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
 )
 
 type ContentHandler interface {
@@ -22,14 +31,29 @@ type ContentHandler interface {
 	CreateContent(c *fiber.Ctx) error
 	UpdateContent(c *fiber.Ctx) error
 	DeleteContent(c *fiber.Ctx) error
+	GetTrashedContents(c *fiber.Ctx) error
+	RestoreContent(c *fiber.Ctx) error
+	PurgeContent(c *fiber.Ctx) error
+	AcquireLock(c *fiber.Ctx) error
+	HeartbeatLock(c *fiber.Ctx) error
+	ReleaseLock(c *fiber.Ctx) error
+	BulkContents(c *fiber.Ctx) error
 	UploadImageR2(c *fiber.Ctx) error
+	CreateAttachment(c *fiber.Ctx) error
+	DeleteAttachment(c *fiber.Ctx) error
+
+	CreatePreview(c *fiber.Ctx) error
 
 	GetContentWithQuery(c *fiber.Ctx) error
 	GetContentDetail(c *fiber.Ctx) error
+	GetContentPreview(c *fiber.Ctx) error
 }
 
 type contentHandler struct {
 	contentService service.ContentService
+	uploadService  service.UploadService
+	imageService   service.ImageService
+	lockService    service.ContentLockService
 }
 
 // GetContentDetail implements ContentHandler.
@@ -38,39 +62,99 @@ func (ch *contentHandler) GetContentDetail(c *fiber.Ctx) error {
 	contentID, err := conv.StringToInt64(idParam)
 	if err != nil {
 		code := "[HANDLER] GetContentDetail - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	result, err := ch.contentService.GetContentById(c.Context(), contentID)
+	result, err := ch.contentService.GetPublishedContentById(c.UserContext(), contentID)
 	if err != nil {
 		code := "[HANDLER] GetContentDetail - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "Success"
+
+	respContent := response.ContentResponse{
+		ID:              result.ID,
+		Title:           result.Title,
+		Slug:            result.Slug,
+		Excerpt:         result.Excerpt,
+		Description:     result.Description,
+		Image:           result.Image,
+		Tags:            result.Tags,
+		Status:          result.Status,
+		CategoryID:      result.CategoryID,
+		CreatedById:     result.CreatedById,
+		CreatedAt:       result.CreatedAt.Local().Format("02 January 2006"),
+		UpdatedAt:       result.UpdatedAt.Local().Format("02 January 2006"),
+		CategoryName:    result.Category.Title,
+		Author:          result.User.Name,
+		Attachments:     toAttachmentResponses(result.Attachments),
+		TableOfContents: toTocResponses(result.TableOfContents),
+	}
+
+	httpcache.Mark(c, lastModified(*result), service.ContentCacheTag(result.ID), service.CategoryCacheTag(result.CategoryID))
+
+	defaultSuccessResponse.Data = respContent
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetContentPreview implements ContentHandler.
+func (ch *contentHandler) GetContentPreview(c *fiber.Ctx) error {
+	result, err := ch.contentService.GetContentByPreviewToken(c.UserContext(), c.Params("token"))
+	if err != nil {
+		code := "[HANDLER] GetContentPreview - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
+		switch {
+		case errors.Is(err, preview.ErrTokenInvalid), errors.Is(err, preview.ErrTokenExpired):
+			return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
+	// previews must never end up in a shared cache or a search index
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	c.Set("X-Robots-Tag", "noindex, nofollow")
+
 	defaultSuccessResponse.Meta.Status = true
 	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Pagination = nil
 
 	respContent := response.ContentResponse{
-		ID:           result.ID,
-		Title:        result.Title,
-		Excerpt:      result.Excerpt,
-		Description:  result.Description,
-		Image:        result.Image,
-		Tags:         result.Tags,
-		Status:       result.Status,
-		CategoryID:   result.CategoryID,
-		CreatedById:  result.CreatedById,
-		CreatedAt:    result.CreatedAt.Local().Format("02 January 2006"),
-		CategoryName: result.Category.Title,
-		Author:       result.User.Name,
+		ID:              result.ID,
+		Title:           result.Title,
+		Slug:            result.Slug,
+		Excerpt:         result.Excerpt,
+		Description:     result.Description,
+		Image:           result.Image,
+		Tags:            result.Tags,
+		Status:          result.Status,
+		CategoryID:      result.CategoryID,
+		CreatedById:     result.CreatedById,
+		CreatedAt:       result.CreatedAt.Local().Format("02 January 2006"),
+		UpdatedAt:       result.UpdatedAt.Local().Format("02 January 2006"),
+		CategoryName:    result.Category.Title,
+		Author:          result.User.Name,
+		Attachments:     toAttachmentResponses(result.Attachments),
+		TableOfContents: toTocResponses(result.TableOfContents),
 	}
 
 	defaultSuccessResponse.Data = respContent
@@ -84,7 +168,7 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		page, err = conv.StringToInt(c.Query("page"))
 		if err != nil {
 			code := "[HANDLER] GetContentWithQuery - 1"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid page number"
 
@@ -97,7 +181,7 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		limit, err = conv.StringToInt(c.Query("limit"))
 		if err != nil {
 			code := "[HANDLER] GetContentWithQuery - 2"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid limit number"
 
@@ -125,7 +209,7 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		categoryID, err = conv.StringToInt(c.Query("categoryID"))
 		if err != nil {
 			code := "[HANDLER] GetContentWithQuery - 3"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid category ID"
 
@@ -141,12 +225,13 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		Search:     search,
 		Status:     "PUBLISH",
 		CategoryID: int64(categoryID),
+		Tag:        c.Query("tag"),
 	}
 
-	results, totalData, totalPages, err := ch.contentService.GetContents(c.Context(), reqEntity)
+	results, totalData, totalPages, err := ch.contentService.GetPublishedContents(c.UserContext(), reqEntity)
 	if err != nil {
 		code := "[HANDLER] GetContentWithQuery - 4"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -161,6 +246,7 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		respContent := response.ContentResponse{
 			ID:           content.ID,
 			Title:        content.Title,
+			Slug:         content.Slug,
 			Excerpt:      content.Excerpt,
 			Description:  content.Description,
 			Image:        content.Image,
@@ -169,8 +255,10 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 			CategoryID:   content.CategoryID,
 			CreatedById:  content.CreatedById,
 			CreatedAt:    content.CreatedAt.Local().Format("02 January 2006"),
+			UpdatedAt:    content.UpdatedAt.Local().Format("02 January 2006"),
 			CategoryName: content.Category.Title,
 			Author:       content.User.Name,
+			Attachments:  toAttachmentResponses(content.Attachments),
 		}
 
 		respContents = append(respContents, respContent)
@@ -183,6 +271,8 @@ func (ch *contentHandler) GetContentWithQuery(c *fiber.Ctx) error {
 		PerPage:      limit,
 		TotalPages:   int(totalPages),
 	}
+
+	httpcache.Mark(c, lastModified(results...), service.CacheTagContents)
 	return c.JSON(defaultSuccessResponse)
 }
 
@@ -191,7 +281,7 @@ func (ch *contentHandler) CreateContent(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code = "[HANDLER] CreateContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -202,7 +292,7 @@ func (ch *contentHandler) CreateContent(c *fiber.Ctx) error {
 	var req request.ContentRequest
 	if err  = c.BodyParser(&req); err != nil {
 		code = "[HANDLER] CreateContent - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Invalid Request Body"
 
@@ -211,7 +301,7 @@ func (ch *contentHandler) CreateContent(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(&req); err != nil {
 		code = "[HANDLER] CreateContent - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -223,6 +313,8 @@ func (ch *contentHandler) CreateContent(c *fiber.Ctx) error {
 		Title:       req.Title,
 		Excerpt:     req.Excerpt,
 		Description: req.Description,
+		BodyFormat:  req.BodyFormat,
+		Blocks:      toContentBlocks(req.Blocks),
 		Image:       req.Image,
 		Tags:        tags,
 		Status:      req.Status,
@@ -230,13 +322,17 @@ func (ch *contentHandler) CreateContent(c *fiber.Ctx) error {
 		CreatedById: int64(userID),
 	}
 
-	err = ch.contentService.CreateContent(c.Context(), reqEntity)
+	err = ch.contentService.CreateContent(c.UserContext(), reqEntity)
 	if err != nil {
 		code = "[HANDLER] CreateContent - 4"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
+		if errors.Is(err, blocks.ErrBlocksInvalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -253,7 +349,7 @@ func (ch *contentHandler) DeleteContent(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code = "[HANDLER] DeleteContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -264,19 +360,40 @@ func (ch *contentHandler) DeleteContent(c *fiber.Ctx) error {
 	contentID, err := conv.StringToInt64(idParam)
 	if err != nil {
 		code = "[HANDLER] DeleteContent - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	err = ch.contentService.DeleteContent(c.Context(), contentID)
+	version, err := revision.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		code = "[HANDLER] DeleteContent - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return ifMatchFailed(c, err)
+	}
+
+	if lock, err := ch.lockService.CheckLock(c.UserContext(), contentID, int64(claims.UserID), c.QueryBool("takeOver")); err != nil {
+		code = "[HANDLER] DeleteContent - 5"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return lockFailed(c, lock, err)
+	}
+
+	err = ch.contentService.DeleteContent(c.UserContext(), contentID, version)
 
 	if err != nil {
 		code = "[HANDLER] DeleteContent - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
+
+		switch {
+		case errors.Is(err, revision.ErrRevisionConflict):
+			return ch.contentPreconditionFailed(c, contentID)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -288,13 +405,289 @@ func (ch *contentHandler) DeleteContent(c *fiber.Ctx) error {
 	return c.JSON(defaultSuccessResponse)
 }
 
+// GetTrashedContents implements ContentHandler.
+func (ch *contentHandler) GetTrashedContents(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] GetTrashedContents - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized access"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	page := 1
+	if c.Query("page") != "" {
+		page, err = conv.StringToInt(c.Query("page"))
+		if err != nil {
+			code = "[HANDLER] GetTrashedContents - 2"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Invalid page number"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+	}
+
+	limit := 10
+	if c.Query("limit") != "" {
+		limit, err = conv.StringToInt(c.Query("limit"))
+		if err != nil {
+			code = "[HANDLER] GetTrashedContents - 3"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Invalid limit number"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+	}
+
+	reqEntity := entity.QueryString{
+		Limit:     limit,
+		Page:      page,
+		OrderBy:   "deleted_at",
+		OrderType: "desc",
+		Search:    c.Query("search"),
+		Trashed:   true,
+	}
+
+	results, totalData, totalPages, err := ch.contentService.GetContents(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] GetTrashedContents - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "Success"
+
+	respContents := []response.ContentResponse{}
+	for _, content := range results {
+		respContent := response.ContentResponse{
+			ID:           content.ID,
+			Title:        content.Title,
+			Slug:         content.Slug,
+			Excerpt:      content.Excerpt,
+			Image:        content.Image,
+			Tags:         content.Tags,
+			Status:       content.Status,
+			CategoryID:   content.CategoryID,
+			CreatedById:  content.CreatedById,
+			CreatedAt:    content.CreatedAt.Local().Format("02 January 2006"),
+			UpdatedAt:    content.UpdatedAt.Local().Format("02 January 2006"),
+			DeletedAt:    formatDeletedAt(content.DeletedAt),
+			CategoryName: content.Category.Title,
+			Author:       content.User.Name,
+		}
+
+		respContents = append(respContents, respContent)
+	}
+
+	defaultSuccessResponse.Data = respContents
+	defaultSuccessResponse.Pagination = &response.PaginationResponse{
+		TotalRecords: int(totalData),
+		Page:         page,
+		PerPage:      limit,
+		TotalPages:   int(totalPages),
+	}
+	return c.JSON(defaultSuccessResponse)
+}
+
+// RestoreContent implements ContentHandler.
+func (ch *contentHandler) RestoreContent(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] RestoreContent - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	id, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] RestoreContent - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ch.contentService.RestoreContent(c.UserContext(), id)
+	if err != nil {
+		code = "[HANDLER] RestoreContent - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "content restored successfully"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// PurgeContent implements ContentHandler.
+func (ch *contentHandler) PurgeContent(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] PurgeContent - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	id, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] PurgeContent - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ch.contentService.PurgeContent(c.UserContext(), id)
+	if err != nil {
+		code = "[HANDLER] PurgeContent - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "content purged successfully"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// BulkContents implements ContentHandler.
+func (ch *contentHandler) BulkContents(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] BulkContents - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	var req request.ContentBulkRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] BulkContents - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(req); err != nil {
+		code = "[HANDLER] BulkContents - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := entity.ContentBulkEntity{
+		UserID:     int64(claims.UserID),
+		Action:     req.Action,
+		IDs:        req.IDs,
+		CategoryID: req.CategoryID,
+		Tags:       req.Tags,
+		DryRun:     req.DryRun,
+	}
+	if req.Filter != nil {
+		reqEntity.Filter = &entity.QueryString{
+			Search:     req.Filter.Search,
+			CategoryID: req.Filter.CategoryID,
+			Status:     req.Filter.Status,
+			Tag:        req.Filter.Tag,
+		}
+	}
+
+	result, err := ch.contentService.BulkContents(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] BulkContents - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		switch {
+		case errors.Is(err, service.ErrBulkTargetRequired), errors.Is(err, service.ErrBulkTooManyItems),
+			errors.Is(err, service.ErrBulkCategoryRequired), errors.Is(err, service.ErrBulkTagsRequired):
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	resp := response.ContentBulkResponse{
+		Action:  result.Action,
+		DryRun:  result.DryRun,
+		Applied: result.Applied,
+		Total:   len(result.Items),
+		Items:   []response.ContentBulkItemResponse{},
+	}
+	for _, item := range result.Items {
+		if item.Status == entity.BulkItemSucceeded {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+
+		resp.Items = append(resp.Items, response.ContentBulkItemResponse{
+			ID:     item.ID,
+			Title:  item.Title,
+			Status: item.Status,
+			Error:  item.Error,
+		})
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "bulk action processed"
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = resp
+
+	return c.JSON(defaultSuccessResponse)
+}
+
 // GetContentById implements ContentHandler.
 func (ch *contentHandler) GetContentById(c *fiber.Ctx) error {
 
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code = "[HANDLER] GetContentById - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -305,18 +698,21 @@ func (ch *contentHandler) GetContentById(c *fiber.Ctx) error {
 	contentID, err := conv.StringToInt64(idParam)
 	if err != nil {
 		code = "[HANDLER] GetContentByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	result, err := ch.contentService.GetContentById(c.Context(), contentID)
+	result, err := ch.contentService.GetContentById(c.UserContext(), contentID)
 	if err != nil {
 		code = "[HANDLER] GetContentByID - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -325,31 +721,69 @@ func (ch *contentHandler) GetContentById(c *fiber.Ctx) error {
 	defaultSuccessResponse.Meta.Message = "content fetched successfuly"
 
 	respContent := response.ContentResponse{
-		ID:           result.ID,
-		Title:        result.Title,
-		Excerpt:      result.Excerpt,
-		Description:  result.Description,
-		Image:        result.Image,
-		Tags:         result.Tags,
-		Status:       result.Status,
-		CategoryID:   result.CategoryID,
-		CreatedById:  result.CreatedById,
-		CreatedAt:    result.CreatedAt.Local().String(),
-		CategoryName: result.Category.Title,
-		Author:       result.User.Name,
+		ID:                result.ID,
+		Title:             result.Title,
+		Slug:              result.Slug,
+		Excerpt:           result.Excerpt,
+		Description:       result.Description,
+		DescriptionHtml:   result.Description,
+		DescriptionSource: result.DescriptionSource,
+		BodyFormat:        result.BodyFormat,
+		Blocks:            toBlockResponses(result.Blocks),
+		TableOfContents:   toTocResponses(result.TableOfContents),
+		Image:             result.Image,
+		Tags:              result.Tags,
+		Status:            result.Status,
+		CategoryID:        result.CategoryID,
+		CreatedById:       result.CreatedById,
+		CreatedAt:         result.CreatedAt.Local().String(),
+		UpdatedAt:         result.UpdatedAt.Local().String(),
+		Version:           result.Version,
+		CategoryName:      result.Category.Title,
+		Author:            result.User.Name,
+		Attachments:       toAttachmentResponses(result.Attachments),
+	}
+
+	lock, err := ch.lockService.GetLock(c.UserContext(), contentID)
+	if err != nil {
+		code = "[HANDLER] GetContentByID - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
+	respContent.Lock = toLockResponse(lock)
 
 	defaultSuccessResponse.Data = respContent
 
+	c.Set(fiber.HeaderETag, revision.ETag(result.Version))
 	return c.JSON(defaultSuccessResponse)
 }
 
+// contentPreconditionFailed answers a stale If-Match with the version the
+// content has now.
+func (ch *contentHandler) contentPreconditionFailed(c *fiber.Ctx, contentID int64) error {
+	current, err := ch.contentService.GetContentById(c.UserContext(), contentID)
+	if err != nil {
+		code = "[HANDLER] contentPreconditionFailed - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	return preconditionFailed(c, current.Version)
+}
+
 // GetContents implements ContentHandler.
 func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code := "[HANDLER] GetContents - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized access"
 
@@ -361,7 +795,7 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 		page, err = conv.StringToInt(c.Query("page"))
 		if err != nil {
 			code := "[HANDLER] GetContents - 2"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid page number"
 
@@ -374,7 +808,7 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 		limit, err = conv.StringToInt(c.Query("limit"))
 		if err != nil {
 			code := "[HANDLER] GetContents - 3"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid limit number"
 
@@ -402,7 +836,7 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 		categoryID, err = conv.StringToInt(c.Query("categoryID"))
 		if err != nil {
 			code := "[HANDLER] GetContents - 4"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = "Invalid category ID"
 
@@ -419,10 +853,25 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 		CategoryID: int64(categoryID),
 	}
 
-	results, totalData, totalPages, err := ch.contentService.GetContents(c.Context(), reqEntity)
+	results, totalData, totalPages, err := ch.contentService.GetContents(c.UserContext(), reqEntity)
 	if err != nil {
 		code := "[HANDLER] GetContents - 5"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	contentIDs := []int64{}
+	for _, content := range results {
+		contentIDs = append(contentIDs, content.ID)
+	}
+
+	locks, err := ch.lockService.GetLocks(c.UserContext(), contentIDs)
+	if err != nil {
+		code := "[HANDLER] GetContents - 6"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -437,6 +886,7 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 		respContent := response.ContentResponse{
 			ID:           content.ID,
 			Title:        content.Title,
+			Slug:         content.Slug,
 			Excerpt:      content.Excerpt,
 			Description:  content.Description,
 			Image:        content.Image,
@@ -445,8 +895,14 @@ func (ch *contentHandler) GetContents(c *fiber.Ctx) error {
 			CategoryID:   content.CategoryID,
 			CreatedById:  content.CreatedById,
 			CreatedAt:    content.CreatedAt.Local().Format("02 January 2006"),
+			UpdatedAt:    content.UpdatedAt.Local().Format("02 January 2006"),
+			Version:      content.Version,
 			CategoryName: content.Category.Title,
 			Author:       content.User.Name,
+			Attachments:  toAttachmentResponses(content.Attachments),
+		}
+		if lock, ok := locks[content.ID]; ok {
+			respContent.Lock = toLockResponse(&lock)
 		}
 
 		respContents = append(respContents, respContent)
@@ -467,7 +923,7 @@ func (ch *contentHandler) UpdateContent(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code = "[HANDLER] UpdateContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -478,7 +934,7 @@ func (ch *contentHandler) UpdateContent(c *fiber.Ctx) error {
 	var req request.ContentRequest
 	if err  = c.BodyParser(&req); err != nil {
 		code = "[HANDLER] UpdateContent - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Invalid Request Body"
 
@@ -487,7 +943,7 @@ func (ch *contentHandler) UpdateContent(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(&req); err != nil {
 		code = "[HANDLER] UpdateContent - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -498,31 +954,57 @@ func (ch *contentHandler) UpdateContent(c *fiber.Ctx) error {
 	contentID, err := conv.StringToInt64(idParam)
 	if err != nil {
 		code = "[HANDLER] UpdateContent - 4"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
+	version, err := revision.ParseIfMatch(c.Get(fiber.HeaderIfMatch))
+	if err != nil {
+		code = "[HANDLER] UpdateContent - 6"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return ifMatchFailed(c, err)
+	}
+
+	if lock, err := ch.lockService.CheckLock(c.UserContext(), contentID, int64(userID), c.QueryBool("takeOver")); err != nil {
+		code = "[HANDLER] UpdateContent - 7"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return lockFailed(c, lock, err)
+	}
+
 	tags := strings.Split(req.Tags, ",")
 	reqEntity := entity.ContentEntity{
 		ID: 		 contentID,
 		Title:       req.Title,
 		Excerpt:     req.Excerpt,
 		Description: req.Description,
+		BodyFormat:  req.BodyFormat,
+		Blocks:      toContentBlocks(req.Blocks),
 		Image:       req.Image,
 		Tags:        tags,
 		Status:      req.Status,
 		CategoryID:  req.CategoryID,
 		CreatedById: int64(userID),
+		Version:     version,
 	}
 
-	err = ch.contentService.UpdateContent(c.Context(), reqEntity)
+	err = ch.contentService.UpdateContent(c.UserContext(), reqEntity)
 	if err != nil {
 		code = "[HANDLER] UpdateContent - 5"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
+
+		switch {
+		case errors.Is(err, blocks.ErrBlocksInvalid):
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		case errors.Is(err, revision.ErrRevisionConflict):
+			return ch.contentPreconditionFailed(c, contentID)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
 
@@ -539,7 +1021,7 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code = "[HANDLER] UploadImageR2 - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized"
 
@@ -550,7 +1032,7 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 	file, err := c.FormFile("image")
 	if claims.UserID == 0 {
 		code = "[HANDLER] UploadImageR2 - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Invalid request body"
 
@@ -559,12 +1041,13 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 
 	if err = c.SaveFile(file, fmt.Sprintf("./temp/content/%s", file.Filename)); err != nil {
 		code = "[HANDLER] UploadImageR2 - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
 		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
 	}
+	metrics.UploadBytes.WithLabelValues(metrics.UploadKindImage).Add(float64(file.Size))
 
 
 	req.Image = fmt.Sprintf("./temp/content/%s", file.Filename)
@@ -573,10 +1056,10 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 		Path: req.Image,
 	}
 
-	imageUrl, err := ch.contentService.UploadImageR2(c.Context(), reqEntity)
+	imageUrl, err := ch.contentService.UploadImageR2(c.UserContext(), reqEntity)
 	if err != nil {
 		code = "[HANDLER] UploadImageR2 - 4"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -586,7 +1069,7 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 		err = os.Remove(req.Image)
 		if err != nil {
 			code = "[HANDLER] UploadImageR2 - 5"
-			log.Errorw(code, err)
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 			errorResp.Meta.Status = false
 			errorResp.Meta.Message = err.Error()
 
@@ -594,10 +1077,24 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 		}
 	}
 
-	urlImageResp := map[string]interface{}{
-		"urlImage": imageUrl,
-	}
-
+	image, err := ch.imageService.CreateImage(c.UserContext(), entity.ImageEntity{
+		Url:         imageUrl,
+		CreatedById: int64(claims.UserID),
+	})
+	if err != nil {
+		code = "[HANDLER] UploadImageR2 - 6"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	urlImageResp := map[string]interface{}{
+		"urlImage": imageUrl,
+		"imageID":  image.ID,
+	}
+
 	defaultSuccessResponse.Meta.Status = true
 	defaultSuccessResponse.Pagination = nil
 	defaultSuccessResponse.Meta.Message = "Success"
@@ -606,8 +1103,538 @@ func (ch *contentHandler) UploadImageR2(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
 }
 
-func NewContentHandler(contentService service.ContentService) ContentHandler {
+// CreateAttachment implements ContentHandler.
+func (ch *contentHandler) CreateAttachment(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreateAttachment - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] CreateAttachment - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	var req request.ContentAttachmentRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] CreateAttachment - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] CreateAttachment - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := entity.AttachmentUploadEntity{
+		ContentID:   contentID,
+		Type:        req.Type,
+		CreatedById: int64(claims.UserID),
+	}
+
+	if req.UploadID != "" {
+		upload, err := ch.uploadService.GetUpload(c.UserContext(), req.UploadID, int64(claims.UserID))
+		if err != nil || upload.Url == "" {
+			code = "[HANDLER] CreateAttachment - 5"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Upload not found or not completed"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+
+		reqEntity.Url = upload.Url
+		reqEntity.Size = upload.Size
+		reqEntity.FileName = upload.Metadata["filename"]
+		reqEntity.Media = upload.Media
+	} else {
+		file, err := c.FormFile("file")
+		if err != nil {
+			code = "[HANDLER] CreateAttachment - 6"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Field file or upload_id is required"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+
+		reqEntity.Path = fmt.Sprintf("./temp/content/%d-%s", time.Now().UnixNano(), filepath.Base(file.Filename))
+		reqEntity.FileName = file.Filename
+		reqEntity.Size = file.Size
+		if err = c.SaveFile(file, reqEntity.Path); err != nil {
+			code = "[HANDLER] CreateAttachment - 7"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = err.Error()
+
+			return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+		}
+		defer os.Remove(reqEntity.Path)
+		metrics.UploadBytes.WithLabelValues(metrics.UploadKindAttachment).Add(float64(file.Size))
+	}
+
+	if poster, err := c.FormFile("poster"); err == nil {
+		reqEntity.PosterPath = fmt.Sprintf("./temp/content/%d-%s", time.Now().UnixNano(), filepath.Base(poster.Filename))
+		if err = c.SaveFile(poster, reqEntity.PosterPath); err != nil {
+			code = "[HANDLER] CreateAttachment - 8"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = err.Error()
+
+			return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+		}
+		defer os.Remove(reqEntity.PosterPath)
+	}
+
+	result, err := ch.contentService.CreateAttachment(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] CreateAttachment - 9"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, mediaprobe.ErrUnknownFormat) || errors.Is(err, mediaprobe.ErrInvalidMedia) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Attachment created successfuly"
+	defaultSuccessResponse.Data = toAttachmentResponses([]entity.ContentAttachmentEntity{*result})[0]
+
+	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
+}
+
+// DeleteAttachment implements ContentHandler.
+func (ch *contentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] DeleteAttachment - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] DeleteAttachment - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	attachmentID, err := conv.StringToInt64(c.Params("attachmentID"))
+	if err != nil {
+		code = "[HANDLER] DeleteAttachment - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ch.contentService.DeleteAttachment(c.UserContext(), contentID, attachmentID)
+	if err != nil {
+		code = "[HANDLER] DeleteAttachment - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "Attachment deleted successfuly"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// CreatePreview implements ContentHandler.
+func (ch *contentHandler) CreatePreview(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreatePreview - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] CreatePreview - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	var req request.PreviewRequest
+	if len(c.Body()) > 0 {
+		if err = c.BodyParser(&req); err != nil {
+			code = "[HANDLER] CreatePreview - 3"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Invalid request body"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+	}
+
+	if err = validatorLib.ValidateStruct(req); err != nil {
+		code = "[HANDLER] CreatePreview - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ch.contentService.CreatePreview(c.UserContext(), contentID, time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		code = "[HANDLER] CreatePreview - 5"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "preview link created successfully"
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = response.PreviewResponse{
+		ContentID: result.ContentID,
+		Token:     result.Token,
+		Url:       result.Url,
+		ExpiresAt: result.ExpiresAt.Local().String(),
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
+}
+
+// AcquireLock implements ContentHandler.
+func (ch *contentHandler) AcquireLock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] AcquireLock - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] AcquireLock - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	var req request.ContentLockRequest
+	if len(c.Body()) > 0 {
+		if err = c.BodyParser(&req); err != nil {
+			code = "[HANDLER] AcquireLock - 3"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Invalid request body"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+	}
+
+	result, err := ch.lockService.AcquireLock(c.UserContext(), contentID, int64(claims.UserID), req.TakeOver)
+	if err != nil {
+		code = "[HANDLER] AcquireLock - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return lockFailed(c, result, err)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "edit lock acquired"
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = toLockResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// HeartbeatLock implements ContentHandler.
+func (ch *contentHandler) HeartbeatLock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] HeartbeatLock - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] HeartbeatLock - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ch.lockService.HeartbeatLock(c.UserContext(), contentID, int64(claims.UserID))
+	if err != nil {
+		code = "[HANDLER] HeartbeatLock - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		return lockFailed(c, result, err)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "edit lock extended"
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = toLockResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// ReleaseLock implements ContentHandler. Passing force=true lets an admin
+// release the lock whoever holds it, for editors that left without
+// releasing.
+func (ch *contentHandler) ReleaseLock(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] ReleaseLock - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	contentID, err := conv.StringToInt64(c.Params("contentID"))
+	if err != nil {
+		code = "[HANDLER] ReleaseLock - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if c.QueryBool("force") {
+		if claims.Role != entity.RoleAdmin {
+			code = "[HANDLER] ReleaseLock - 5"
+			zerolog.Ctx(c.UserContext()).Error().Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Only admins can force-release a lock"
+
+			return c.Status(fiber.StatusForbidden).JSON(errorResp)
+		}
+
+		err = ch.lockService.ForceReleaseLock(c.UserContext(), contentID)
+	} else {
+		var lock *entity.ContentLockEntity
+		lock, err = ch.lockService.ReleaseLock(c.UserContext(), contentID, int64(claims.UserID))
+		if err != nil {
+			code = "[HANDLER] ReleaseLock - 3"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			return lockFailed(c, lock, err)
+		}
+	}
+	if err != nil {
+		code = "[HANDLER] ReleaseLock - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Meta.Message = "edit lock released"
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// lockFailed answers lock errors. Conflicts carry the current lock so the
+// editor can see who is working on the content and since when.
+func lockFailed(c *fiber.Ctx, lock *entity.ContentLockEntity, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrLockHeld):
+		status = fiber.StatusLocked
+	case errors.Is(err, service.ErrLockNotHeld):
+		status = fiber.StatusConflict
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		status = fiber.StatusNotFound
+	}
+
+	return c.Status(status).JSON(response.DefaultSuccessResponse{
+		Meta: response.Meta{
+			Status:  false,
+			Message: err.Error(),
+		},
+		Data: toLockResponse(lock),
+	})
+}
+
+func toLockResponse(lock *entity.ContentLockEntity) *response.ContentLockResponse {
+	if lock == nil {
+		return nil
+	}
+
+	return &response.ContentLockResponse{
+		ContentID:   lock.ContentID,
+		UserID:      lock.User.ID,
+		UserName:    lock.User.Name,
+		Since:       lock.AcquiredAt.Local().String(),
+		HeartbeatAt: lock.HeartbeatAt.Local().String(),
+		ExpiresAt:   lock.ExpiresAt.Local().String(),
+	}
+}
+
+func formatDeletedAt(deletedAt *time.Time) string {
+	if deletedAt == nil {
+		return ""
+	}
+
+	return deletedAt.Local().String()
+}
+
+func toContentBlocks(reqBlocks []request.ContentBlockRequest) []entity.ContentBlock {
+	contentBlocks := []entity.ContentBlock{}
+	for _, val := range reqBlocks {
+		contentBlocks = append(contentBlocks, entity.ContentBlock{
+			Type:     val.Type,
+			Text:     val.Text,
+			Level:    val.Level,
+			Url:      val.Url,
+			Alt:      val.Alt,
+			Caption:  val.Caption,
+			Ordered:  val.Ordered,
+			Items:    val.Items,
+			Language: val.Language,
+		})
+	}
+
+	return contentBlocks
+}
+
+func toBlockResponses(contentBlocks []entity.ContentBlock) []response.ContentBlockResponse {
+	resps := []response.ContentBlockResponse{}
+	for _, val := range contentBlocks {
+		resps = append(resps, response.ContentBlockResponse{
+			Type:     val.Type,
+			Text:     val.Text,
+			Level:    val.Level,
+			Url:      val.Url,
+			Alt:      val.Alt,
+			Caption:  val.Caption,
+			Ordered:  val.Ordered,
+			Items:    val.Items,
+			Language: val.Language,
+		})
+	}
+
+	return resps
+}
+
+func toTocResponses(toc []entity.TocEntry) []response.TocResponse {
+	resps := []response.TocResponse{}
+	for _, val := range toc {
+		resps = append(resps, response.TocResponse{
+			Level: val.Level,
+			ID:    val.ID,
+			Text:  val.Text,
+		})
+	}
+
+	return resps
+}
+
+func toAttachmentResponses(attachments []entity.ContentAttachmentEntity) []response.ContentAttachmentResponse {
+	resps := []response.ContentAttachmentResponse{}
+	for _, val := range attachments {
+		resps = append(resps, response.ContentAttachmentResponse{
+			ID:         val.ID,
+			Type:       val.Type,
+			Url:        val.Url,
+			FileName:   val.FileName,
+			MimeType:   val.Media.MimeType,
+			Size:       val.Size,
+			DurationMs: val.Media.DurationMs,
+			VideoCodec: val.Media.VideoCodec,
+			AudioCodec: val.Media.AudioCodec,
+			Width:      val.Media.Width,
+			Height:     val.Media.Height,
+			SampleRate: val.Media.SampleRate,
+			Channels:   val.Media.Channels,
+			PosterUrl:  val.PosterUrl,
+		})
+	}
+
+	return resps
+}
+
+func NewContentHandler(contentService service.ContentService, uploadService service.UploadService, imageService service.ImageService, lockService service.ContentLockService) ContentHandler {
 	return &contentHandler{
 		contentService: contentService,
+		uploadService:  uploadService,
+		imageService:   imageService,
+		lockService:    lockService,
+	}
+}
+
+// lastModified is when the newest of contents, their categories and their
+// authors changed, since a response shows all three.
+func lastModified(contents ...entity.ContentEntity) time.Time {
+	var newest time.Time
+	for _, content := range contents {
+		for _, changed := range []time.Time{content.UpdatedAt, content.Category.UpdatedAt, content.User.UpdatedAt} {
+			if changed.After(newest) {
+				newest = changed
+			}
+		}
 	}
-}
\ No newline at end of file
+
+	return newest
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/permalink"
+	"mime"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"github.com/gorilla/feeds"
+	"gorm.io/gorm"
+)
+
+type FeedHandler interface {
+	GetRss(c *fiber.Ctx) error
+	GetAtom(c *fiber.Ctx) error
+}
+
+type feedHandler struct {
+	feedService service.FeedService
+	cfg         *config.Config
+}
+
+// GetRss implements FeedHandler.
+func (fh *feedHandler) GetRss(c *fiber.Ctx) error {
+	feed, err := fh.buildFeed(c)
+	if err != nil {
+		code = "[HANDLER] GetRss - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(feedErrorStatus(err)).JSON(errorResp)
+	}
+
+	body, err := feed.ToRss()
+	if err != nil {
+		code = "[HANDLER] GetRss - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	return sendFeed(c, "application/rss+xml; charset=utf-8", body)
+}
+
+// GetAtom implements FeedHandler.
+func (fh *feedHandler) GetAtom(c *fiber.Ctx) error {
+	feed, err := fh.buildFeed(c)
+	if err != nil {
+		code = "[HANDLER] GetAtom - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(feedErrorStatus(err)).JSON(errorResp)
+	}
+
+	body, err := feed.ToAtom()
+	if err != nil {
+		code = "[HANDLER] GetAtom - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	return sendFeed(c, "application/atom+xml; charset=utf-8", body)
+}
+
+// buildFeed loads the feed for the category or tag in the route, if any.
+func (fh *feedHandler) buildFeed(c *fiber.Ctx) (*feeds.Feed, error) {
+	tag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := fh.feedService.GetFeed(c.UserContext(), entity.FeedQueryEntity{
+		CategorySlug: c.Params("categorySlug"),
+		Tag:          tag,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &feeds.Feed{
+		Title:       result.Title,
+		Link:        &feeds.Link{Href: result.Link},
+		Description: result.Description,
+		Updated:     result.Updated,
+	}
+
+	for _, content := range result.Items {
+		link := permalink.Content(fh.cfg.App.FrontendUrl, content)
+		item := &feeds.Item{
+			Title:       content.Title,
+			Link:        &feeds.Link{Href: link},
+			Id:          link,
+			Description: content.Excerpt,
+			Content:     content.Description,
+			Author:      &feeds.Author{Name: content.User.Name},
+			Created:     content.CreatedAt,
+			Updated:     content.UpdatedAt,
+		}
+
+		if content.Image != "" {
+			item.Enclosure = &feeds.Enclosure{
+				Url:    content.Image,
+				Type:   imageMimeType(content.Image),
+				Length: "0",
+			}
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	return feed, nil
+}
+
+// sendFeed writes the feed with a content hash as ETag and answers
+// conditional requests that already hold it with 304.
+func sendFeed(c *fiber.Ctx, contentType string, body string) error {
+	sum := sha256.Sum256([]byte(body))
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16]))
+
+	c.Set(fiber.HeaderETag, etag)
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.SendString(body)
+}
+
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func imageMimeType(imageUrl string) string {
+	if u, err := url.Parse(imageUrl); err == nil {
+		if mimeType := mime.TypeByExtension(strings.ToLower(path.Ext(u.Path))); strings.HasPrefix(mimeType, "image/") {
+			return mimeType
+		}
+	}
+
+	return "image/jpeg"
+}
+
+func feedErrorStatus(err error) int {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fiber.StatusNotFound
+	}
+
+	return fiber.StatusInternalServerError
+}
+
+func NewFeedHandler(feedService service.FeedService, cfg *config.Config) FeedHandler {
+	return &feedHandler{
+		feedService: feedService,
+		cfg:         cfg,
+	}
+}
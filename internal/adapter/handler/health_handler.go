@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"gonews/internal/adapter/handler/response"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type HealthHandler interface {
+	Healthz(c *fiber.Ctx) error
+	Readyz(c *fiber.Ctx) error
+}
+
+type healthHandler struct {
+	healthService service.HealthService
+}
+
+// Healthz implements HealthHandler. It only tells the process is serving
+// requests; dependencies are left to Readyz so a database outage does not
+// get every pod restarted.
+func (hh *healthHandler) Healthz(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(response.HealthResponse{Status: entity.HealthStatusUp})
+}
+
+// Readyz implements HealthHandler.
+func (hh *healthHandler) Readyz(c *fiber.Ctx) error {
+	report := hh.healthService.Ready(c.UserContext())
+
+	resp := response.HealthResponse{
+		Status: report.Status,
+		Checks: map[string]response.HealthCheckResponse{},
+	}
+	for _, check := range report.Checks {
+		resp.Checks[check.Name] = response.HealthCheckResponse{
+			Status:    check.Status,
+			LatencyMs: float64(check.Latency.Microseconds()) / 1000,
+			Detail:    check.Detail,
+			Error:     check.Error,
+			CheckedAt: check.CheckedAt.Format(time.RFC3339),
+		}
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if report.Status != entity.HealthStatusUp {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(resp)
+	}
+
+	return c.JSON(resp)
+}
+
+func NewHealthHandler(healthService service.HealthService) HealthHandler {
+	return &healthHandler{healthService: healthService}
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"errors"
+	"gonews/internal/adapter/handler/request"
+	"gonews/internal/adapter/handler/response"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/conv"
+	"gonews/lib/imgtransform"
+	validatorLib "gonews/lib/validator"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type ImageHandler interface {
+	CreateImage(c *fiber.Ctx) error
+	GetImageByID(c *fiber.Ctx) error
+	UpdateFocalPoint(c *fiber.Ctx) error
+	GetSignedUrl(c *fiber.Ctx) error
+
+	RenderImage(c *fiber.Ctx) error
+}
+
+type imageHandler struct {
+	imageService service.ImageService
+}
+
+// CreateImage implements ImageHandler.
+func (ih *imageHandler) CreateImage(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreateImage - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	var req request.ImageRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] CreateImage - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] CreateImage - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ih.imageService.CreateImage(c.UserContext(), entity.ImageEntity{
+		Url:         req.Url,
+		CreatedById: int64(claims.UserID),
+	})
+	if err != nil {
+		code = "[HANDLER] CreateImage - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, service.ErrImageUrlInvalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Image created successfuly"
+	defaultSuccessResponse.Data = toImageResponse(result)
+
+	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
+}
+
+// GetImageByID implements ImageHandler.
+func (ih *imageHandler) GetImageByID(c *fiber.Ctx) error {
+	imageID, err := conv.StringToInt64(c.Params("imageID"))
+	if err != nil {
+		code = "[HANDLER] GetImageByID - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ih.imageService.GetImageByID(c.UserContext(), imageID)
+	if err != nil {
+		code = "[HANDLER] GetImageByID - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(imageErrorStatus(err)).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = toImageResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// UpdateFocalPoint implements ImageHandler.
+func (ih *imageHandler) UpdateFocalPoint(c *fiber.Ctx) error {
+	imageID, err := conv.StringToInt64(c.Params("imageID"))
+	if err != nil {
+		code = "[HANDLER] UpdateFocalPoint - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	var req request.FocalPointRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] UpdateFocalPoint - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] UpdateFocalPoint - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = ih.imageService.UpdateFocalPoint(c.UserContext(), entity.ImageEntity{
+		ID:     imageID,
+		FocalX: *req.X,
+		FocalY: *req.Y,
+	})
+	if err != nil {
+		code = "[HANDLER] UpdateFocalPoint - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(imageErrorStatus(err)).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Data = nil
+	defaultSuccessResponse.Meta.Message = "Focal point updated successfuly"
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetSignedUrl implements ImageHandler.
+func (ih *imageHandler) GetSignedUrl(c *fiber.Ctx) error {
+	imageID, err := conv.StringToInt64(c.Params("imageID"))
+	if err != nil {
+		code = "[HANDLER] GetSignedUrl - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	path, err := ih.imageService.SignImagePath(c.UserContext(), imageID, imageTransformQuery(c))
+	if err != nil {
+		code = "[HANDLER] GetSignedUrl - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(imageErrorStatus(err)).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = map[string]interface{}{
+		"url": c.BaseURL() + path,
+	}
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// RenderImage implements ImageHandler.
+func (ih *imageHandler) RenderImage(c *fiber.Ctx) error {
+	imageID, err := conv.StringToInt64(c.Params("imageID"))
+	if err != nil {
+		code = "[HANDLER] RenderImage - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	data, contentType, err := ih.imageService.RenderImage(c.UserContext(), imageID, imageTransformQuery(c), c.Query("sig"))
+	if err != nil {
+		code = "[HANDLER] RenderImage - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(imageErrorStatus(err)).JSON(errorResp)
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+
+	return c.Send(data)
+}
+
+func imageTransformQuery(c *fiber.Ctx) entity.ImageTransformEntity {
+	return entity.ImageTransformEntity{
+		Width:  c.QueryInt("w"),
+		Height: c.QueryInt("h"),
+		Fit:    c.Query("fit"),
+		Format: c.Query("fmt"),
+	}
+}
+
+func imageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, imgtransform.ErrSignatureInvalid):
+		return fiber.StatusForbidden
+	case errors.Is(err, imgtransform.ErrSizeInvalid), errors.Is(err, imgtransform.ErrFitInvalid),
+		errors.Is(err, imgtransform.ErrFormatInvalid), errors.Is(err, service.ErrFocalPointInvalid):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func toImageResponse(image *entity.ImageEntity) response.ImageResponse {
+	return response.ImageResponse{
+		ID:     image.ID,
+		Url:    image.Url,
+		FocalX: image.FocalX,
+		FocalY: image.FocalY,
+	}
+}
+
+func NewImageHandler(imageService service.ImageService) ImageHandler {
+	return &imageHandler{imageService: imageService}
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/handler/request"
+	"gonews/internal/adapter/handler/response"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/conv"
+	"gonews/lib/importer"
+	"gonews/lib/metrics"
+	validatorLib "gonews/lib/validator"
+	"path/filepath"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type ImportHandler interface {
+	CreateImport(c *fiber.Ctx) error
+	GetImports(c *fiber.Ctx) error
+	GetImportByID(c *fiber.Ctx) error
+
+	GetRedirect(c *fiber.Ctx) error
+}
+
+type importHandler struct {
+	importService service.ImportService
+	uploadService service.UploadService
+	cfg           *config.Config
+}
+
+// CreateImport implements ImportHandler. The file is either sent as a
+// multipart field or, for large exports, as a finished resumable upload.
+func (ih *importHandler) CreateImport(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreateImport - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	var req request.ImportRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] CreateImport - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] CreateImport - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := entity.ImportJobEntity{
+		Format:      req.Format,
+		CreatedById: int64(claims.UserID),
+	}
+
+	if req.UploadID != "" {
+		upload, err := ih.uploadService.GetUpload(c.UserContext(), req.UploadID, int64(claims.UserID))
+		if err != nil || upload.Url == "" {
+			code = "[HANDLER] CreateImport - 4"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Upload not found or not completed"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+
+		reqEntity.SourceUrl = upload.Url
+		reqEntity.FileName = upload.Metadata["filename"]
+	} else {
+		file, err := c.FormFile("file")
+		if err != nil {
+			code = "[HANDLER] CreateImport - 5"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = "Field file or upload_id is required"
+
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+
+		importDir := ih.cfg.Import.Dir
+		if importDir == "" {
+			importDir = "./temp/imports"
+		}
+
+		reqEntity.FileName = file.Filename
+		reqEntity.FilePath = filepath.Join(importDir, fmt.Sprintf("%d-%s", time.Now().UnixNano(), filepath.Base(file.Filename)))
+		if err = c.SaveFile(file, reqEntity.FilePath); err != nil {
+			code = "[HANDLER] CreateImport - 6"
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = err.Error()
+
+			return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+		}
+		metrics.UploadBytes.WithLabelValues(metrics.UploadKindImport).Add(float64(file.Size))
+	}
+
+	result, err := ih.importService.CreateJob(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] CreateImport - 7"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, importer.ErrFormatUnsupported) || errors.Is(err, service.ErrImportSourceRequired) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Import queued"
+	defaultSuccessResponse.Data = toImportJobResponse(result)
+
+	return c.Status(fiber.StatusAccepted).JSON(defaultSuccessResponse)
+}
+
+// GetImports implements ImportHandler.
+func (ih *importHandler) GetImports(c *fiber.Ctx) error {
+	results, err := ih.importService.GetJobs(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetImports - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	respJobs := []response.ImportJobResponse{}
+	for i := range results {
+		respJobs = append(respJobs, toImportJobResponse(&results[i]))
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = respJobs
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetImportByID implements ImportHandler.
+func (ih *importHandler) GetImportByID(c *fiber.Ctx) error {
+	jobID, err := conv.StringToInt64(c.Params("jobID"))
+	if err != nil {
+		code = "[HANDLER] GetImportByID - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ih.importService.GetJob(c.UserContext(), jobID)
+	if err != nil {
+		code = "[HANDLER] GetImportByID - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = toImportJobResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetRedirect implements ImportHandler. The frontend asks for it when a
+// path does not resolve, so links to the old site keep working.
+func (ih *importHandler) GetRedirect(c *fiber.Ctx) error {
+	fromPath := c.Query("path")
+	if fromPath == "" {
+		code = "[HANDLER] GetRedirect - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Query path is required"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := ih.importService.ResolveRedirect(c.UserContext(), importer.SourcePath(fromPath))
+	if err != nil {
+		code = "[HANDLER] GetRedirect - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errorResp.Meta.Message = "Redirect not found"
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = response.RedirectResponse{
+		From:   result.FromPath,
+		To:     result.ToPath,
+		Status: fiber.StatusMovedPermanently,
+	}
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+func toImportJobResponse(job *entity.ImportJobEntity) response.ImportJobResponse {
+	resp := response.ImportJobResponse{
+		ID:          job.ID,
+		Format:      job.Format,
+		FileName:    job.FileName,
+		Status:      job.Status,
+		Stats:       job.Stats,
+		Errors:      job.Errors,
+		Error:       job.Error,
+		CreatedById: job.CreatedById,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+	}
+	if resp.Errors == nil {
+		resp.Errors = []string{}
+	}
+	if job.StartedAt != nil {
+		resp.StartedAt = job.StartedAt.Format(time.RFC3339)
+	}
+	if job.FinishedAt != nil {
+		resp.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
+
+func NewImportHandler(importService service.ImportService, uploadService service.UploadService, cfg *config.Config) ImportHandler {
+	return &importHandler{
+		importService: importService,
+		uploadService: uploadService,
+		cfg:           cfg,
+	}
+}
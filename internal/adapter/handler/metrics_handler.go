@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"gonews/config"
+	"gonews/lib/metrics"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsHandler interface {
+	GetMetrics(c *fiber.Ctx) error
+}
+
+type metricsHandler struct {
+	cfg     *config.Config
+	handler fiber.Handler
+}
+
+// GetMetrics implements MetricsHandler. Scrapers have to send the metrics
+// token as a bearer token; without a token metrics are not served at all.
+func (mh *metricsHandler) GetMetrics(c *fiber.Ctx) error {
+	token := mh.cfg.Metrics.Token
+	if token == "" {
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Not Found"
+
+		return c.Status(fiber.StatusNotFound).JSON(errorResp)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+token)) != 1 {
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	return mh.handler(c)
+}
+
+func NewMetricsHandler(cfg *config.Config) MetricsHandler {
+	return &metricsHandler{
+		cfg:     cfg,
+		handler: adaptor.HTTPHandler(promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{})),
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"errors"
+	"gonews/internal/adapter/handler/response"
+	"gonews/lib/revision"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ifMatchFailed answers a missing If-Match with 428 and a malformed one
+// with 400.
+func ifMatchFailed(c *fiber.Ctx, err error) error {
+	errorResp.Meta.Status = false
+	errorResp.Meta.Message = err.Error()
+
+	if errors.Is(err, revision.ErrIfMatchRequired) {
+		return c.Status(fiber.StatusPreconditionRequired).JSON(errorResp)
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+}
+
+// preconditionFailed answers a stale If-Match with 412 and the current
+// version, both in the body and as ETag, so the client can refetch or retry.
+func preconditionFailed(c *fiber.Ctx, version int64) error {
+	c.Set(fiber.HeaderETag, revision.ETag(version))
+
+	return c.Status(fiber.StatusPreconditionFailed).JSON(response.DefaultSuccessResponse{
+		Meta: response.Meta{
+			Status:  false,
+			Message: revision.ErrRevisionConflict.Error(),
+		},
+		Data: response.VersionResponse{
+			Version: version,
+			ETag:    revision.ETag(version),
+		},
+	})
+}
@@ -0,0 +1,6 @@
+package request
+
+type ContentAttachmentRequest struct {
+	Type     string `json:"type" form:"type" validate:"omitempty,oneof=audio video document"`
+	UploadID string `json:"upload_id" form:"upload_id"`
+}
@@ -0,0 +1,17 @@
+package request
+
+type ContentBulkRequest struct {
+	Action     string                    `json:"action" validate:"required,oneof=publish unpublish set_category add_tags remove_tags set_tags delete"`
+	IDs        []int64                   `json:"ids" validate:"omitempty,max=1000,dive,gt=0"`
+	Filter     *ContentBulkFilterRequest `json:"filter"`
+	CategoryID int64                     `json:"category_id"`
+	Tags       []string                  `json:"tags"`
+	DryRun     bool                      `json:"dry_run"`
+}
+
+type ContentBulkFilterRequest struct {
+	Search     string `json:"search"`
+	CategoryID int64  `json:"category_id"`
+	Status     string `json:"status"`
+	Tag        string `json:"tag"`
+}
@@ -0,0 +1,5 @@
+package request
+
+type ContentLockRequest struct {
+	TakeOver bool `json:"take_over"`
+}
@@ -1,11 +1,25 @@
 package request
 
 type ContentRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Excerpt     string `json:"excerpt" validate:"required"`
-	Description string `json:"description" validate:"required"`
-	Image       string `json:"image" validate:"required"`
-	Tags        string `json:"tags"`
-	CategoryID  int64  `json:"category_id" validate:"required"`
-	Status      string `json:"status" validate:"required"`
+	Title       string                `json:"title" validate:"required"`
+	Excerpt     string                `json:"excerpt" validate:"required"`
+	Description string                `json:"description" validate:"required_without=Blocks"`
+	BodyFormat  string                `json:"body_format" validate:"omitempty,oneof=html markdown blocks"`
+	Blocks      []ContentBlockRequest `json:"blocks" validate:"omitempty,dive"`
+	Image       string                `json:"image" validate:"required"`
+	Tags        string                `json:"tags"`
+	CategoryID  int64                 `json:"category_id" validate:"required"`
+	Status      string                `json:"status" validate:"required"`
+}
+
+type ContentBlockRequest struct {
+	Type     string   `json:"type" validate:"required,oneof=paragraph heading image quote embed list code"`
+	Text     string   `json:"text"`
+	Level    int      `json:"level"`
+	Url      string   `json:"url"`
+	Alt      string   `json:"alt"`
+	Caption  string   `json:"caption"`
+	Ordered  bool     `json:"ordered"`
+	Items    []string `json:"items"`
+	Language string   `json:"language"`
 }
@@ -0,0 +1,10 @@
+package request
+
+type ImageRequest struct {
+	Url string `json:"url" validate:"required,url"`
+}
+
+type FocalPointRequest struct {
+	X *float64 `json:"x" validate:"required"`
+	Y *float64 `json:"y" validate:"required"`
+}
@@ -0,0 +1,6 @@
+package request
+
+type ImportRequest struct {
+	Format   string `json:"format" form:"format" validate:"required,oneof=wxr json csv"`
+	UploadID string `json:"upload_id" form:"upload_id"`
+}
@@ -0,0 +1,5 @@
+package request
+
+type PreviewRequest struct {
+	ExpiresInHours int `json:"expires_in_hours" validate:"omitempty,min=1,max=720"`
+}
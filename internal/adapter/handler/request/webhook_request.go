@@ -0,0 +1,8 @@
+package request
+
+type WebhookRequest struct {
+	Url         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" validate:"omitempty,dive,oneof=content.created content.updated content.published content.unpublished content.deleted content.restored content.purged"`
+	Active      *bool    `json:"active"`
+}
@@ -5,4 +5,6 @@ type SuccessCategoryResponse struct {
 	Title string `json:"title"`
 	Slug string `json:"slug"`
 	CreatedByName string `json:"created_by_name"`
+	DeletedAt string `json:"deleted_at,omitempty"`
+	Version int64 `json:"version,omitempty"`
 }
\ No newline at end of file
@@ -0,0 +1,18 @@
+package response
+
+type ContentAttachmentResponse struct {
+	ID         int64  `json:"id"`
+	Type       string `json:"type"`
+	Url        string `json:"url"`
+	FileName   string `json:"file_name"`
+	MimeType   string `json:"mime_type"`
+	Size       int64  `json:"size"`
+	DurationMs int64  `json:"duration_ms,omitempty"`
+	VideoCodec string `json:"video_codec,omitempty"`
+	AudioCodec string `json:"audio_codec,omitempty"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	SampleRate int    `json:"sample_rate,omitempty"`
+	Channels   int    `json:"channels,omitempty"`
+	PosterUrl  string `json:"poster_url,omitempty"`
+}
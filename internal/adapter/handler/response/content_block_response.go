@@ -0,0 +1,19 @@
+package response
+
+type ContentBlockResponse struct {
+	Type     string   `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	Level    int      `json:"level,omitempty"`
+	Url      string   `json:"url,omitempty"`
+	Alt      string   `json:"alt,omitempty"`
+	Caption  string   `json:"caption,omitempty"`
+	Ordered  bool     `json:"ordered,omitempty"`
+	Items    []string `json:"items,omitempty"`
+	Language string   `json:"language,omitempty"`
+}
+
+type TocResponse struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
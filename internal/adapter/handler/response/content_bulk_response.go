@@ -0,0 +1,18 @@
+package response
+
+type ContentBulkResponse struct {
+	Action    string                    `json:"action"`
+	DryRun    bool                      `json:"dry_run"`
+	Applied   bool                      `json:"applied"`
+	Total     int                       `json:"total"`
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Items     []ContentBulkItemResponse `json:"items"`
+}
+
+type ContentBulkItemResponse struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
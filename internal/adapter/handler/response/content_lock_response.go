@@ -0,0 +1,10 @@
+package response
+
+type ContentLockResponse struct {
+	ContentID   int64  `json:"content_id"`
+	UserID      int64  `json:"user_id"`
+	UserName    string `json:"user_name"`
+	Since       string `json:"since"`
+	HeartbeatAt string `json:"heartbeat_at"`
+	ExpiresAt   string `json:"expires_at"`
+}
@@ -1,16 +1,27 @@
 package response
 
 type ContentResponse struct {
-	ID           int64    `json:"id"`
-	Title        string   `json:"title"`
-	Excerpt      string   `json:"excerpt"`
-	Description  string   `json:"description,omitempty"`
-	Image        string   `json:"image"`
-	Tags         []string `json:"tags,omitempty"`
-	Status       string   `json:"status"`
-	CategoryID   int64    `json:"category_id,omitempty"`
-	CreatedById  int64    `json:"created_by_id,omitempty"`
-	CreatedAt    string   `json:"created_at"`
-	CategoryName string   `json:"category_name"`
-	Author       string   `json:"author"`
+	ID                int64                       `json:"id"`
+	Title             string                      `json:"title"`
+	Slug              string                      `json:"slug,omitempty"`
+	Excerpt           string                      `json:"excerpt"`
+	Description       string                      `json:"description,omitempty"`
+	DescriptionHtml   string                      `json:"description_html,omitempty"`
+	DescriptionSource string                      `json:"description_source,omitempty"`
+	BodyFormat        string                      `json:"body_format,omitempty"`
+	Blocks            []ContentBlockResponse      `json:"blocks,omitempty"`
+	TableOfContents   []TocResponse               `json:"table_of_contents,omitempty"`
+	Image             string                      `json:"image"`
+	Tags              []string                    `json:"tags,omitempty"`
+	Status            string                      `json:"status"`
+	CategoryID        int64                       `json:"category_id,omitempty"`
+	CreatedById       int64                       `json:"created_by_id,omitempty"`
+	CreatedAt         string                      `json:"created_at"`
+	UpdatedAt         string                      `json:"updated_at"`
+	DeletedAt         string                      `json:"deleted_at,omitempty"`
+	Version           int64                       `json:"version,omitempty"`
+	CategoryName      string                      `json:"category_name"`
+	Author            string                      `json:"author"`
+	Attachments       []ContentAttachmentResponse `json:"attachments,omitempty"`
+	Lock              *ContentLockResponse        `json:"lock,omitempty"`
 }
@@ -0,0 +1,8 @@
+package response
+
+type ImageResponse struct {
+	ID     int64   `json:"id"`
+	Url    string  `json:"url"`
+	FocalX float64 `json:"focal_x"`
+	FocalY float64 `json:"focal_y"`
+}
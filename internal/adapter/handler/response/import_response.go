@@ -0,0 +1,21 @@
+package response
+
+type ImportJobResponse struct {
+	ID          int64       `json:"id"`
+	Format      string      `json:"format"`
+	FileName    string      `json:"file_name"`
+	Status      string      `json:"status"`
+	Stats       interface{} `json:"stats"`
+	Errors      []string    `json:"errors"`
+	Error       string      `json:"error,omitempty"`
+	CreatedById int64       `json:"created_by_id"`
+	CreatedAt   string      `json:"created_at"`
+	StartedAt   string      `json:"started_at,omitempty"`
+	FinishedAt  string      `json:"finished_at,omitempty"`
+}
+
+type RedirectResponse struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Status int    `json:"status"`
+}
@@ -0,0 +1,8 @@
+package response
+
+type PreviewResponse struct {
+	ContentID int64  `json:"content_id"`
+	Token     string `json:"token"`
+	Url       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
@@ -0,0 +1,6 @@
+package response
+
+type VersionResponse struct {
+	Version int64  `json:"version"`
+	ETag    string `json:"etag"`
+}
@@ -0,0 +1,35 @@
+package response
+
+import "encoding/json"
+
+type WebhookResponse struct {
+	ID             int64    `json:"id"`
+	Url            string   `json:"url"`
+	Secret         string   `json:"secret,omitempty"`
+	Description    string   `json:"description"`
+	Events         []string `json:"events"`
+	Active         bool     `json:"active"`
+	FailureCount   int      `json:"failure_count"`
+	DisabledAt     string   `json:"disabled_at,omitempty"`
+	DisabledReason string   `json:"disabled_reason,omitempty"`
+	CreatedById    int64    `json:"created_by_id"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64           `json:"id"`
+	WebhookID      int64           `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  string          `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  string          `json:"last_attempt_at,omitempty"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DurationMs     int64           `json:"duration_ms"`
+	RedeliveryOfID int64           `json:"redelivery_of_id,omitempty"`
+	CreatedAt      string          `json:"created_at"`
+}
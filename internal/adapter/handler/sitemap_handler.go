@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"errors"
+	"gonews/config"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/conv"
+	"gonews/lib/sitemap"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+type SitemapHandler interface {
+	GetSitemapIndex(c *fiber.Ctx) error
+	GetCategorySitemap(c *fiber.Ctx) error
+	GetContentSitemap(c *fiber.Ctx) error
+	GetNewsSitemap(c *fiber.Ctx) error
+}
+
+type sitemapHandler struct {
+	sitemapService service.SitemapService
+	cfg            *config.Config
+}
+
+// GetSitemapIndex implements SitemapHandler.
+func (sh *sitemapHandler) GetSitemapIndex(c *fiber.Ctx) error {
+	results, err := sh.sitemapService.GetSitemapIndex(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetSitemapIndex - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	body, err := sitemap.Index(toSitemapURLs(results))
+	if err != nil {
+		code = "[HANDLER] GetSitemapIndex - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	c.Set(fiber.HeaderContentType, sitemapContentType)
+	return c.Send(body)
+}
+
+// GetCategorySitemap implements SitemapHandler.
+func (sh *sitemapHandler) GetCategorySitemap(c *fiber.Ctx) error {
+	results, err := sh.sitemapService.GetCategorySitemap(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetCategorySitemap - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	body, err := sitemap.URLSet(toSitemapURLs(results))
+	if err != nil {
+		code = "[HANDLER] GetCategorySitemap - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	c.Set(fiber.HeaderContentType, sitemapContentType)
+	return c.Send(body)
+}
+
+// GetContentSitemap implements SitemapHandler.
+func (sh *sitemapHandler) GetContentSitemap(c *fiber.Ctx) error {
+	page, err := conv.StringToInt(c.Params("page"))
+	if err != nil {
+		code = "[HANDLER] GetContentSitemap - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid page number"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	results, err := sh.sitemapService.GetContentSitemap(c.UserContext(), page)
+	if err != nil {
+		code = "[HANDLER] GetContentSitemap - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, sitemap.ErrSitemapNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	body, err := sitemap.URLSet(toSitemapURLs(results))
+	if err != nil {
+		code = "[HANDLER] GetContentSitemap - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	c.Set(fiber.HeaderContentType, sitemapContentType)
+	return c.Send(body)
+}
+
+// GetNewsSitemap implements SitemapHandler.
+func (sh *sitemapHandler) GetNewsSitemap(c *fiber.Ctx) error {
+	results, err := sh.sitemapService.GetNewsSitemap(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetNewsSitemap - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	urls := []sitemap.NewsURL{}
+	for _, result := range results {
+		urls = append(urls, sitemap.NewsURL{
+			Loc:             result.Loc,
+			Title:           result.Title,
+			PublicationDate: result.PublishedAt,
+		})
+	}
+
+	language := sh.cfg.Sitemap.NewsLanguage
+	if language == "" {
+		language = "en"
+	}
+
+	body, err := sitemap.News(sitemap.Publication{Name: sh.cfg.Sitemap.NewsPublicationName, Language: language}, urls)
+	if err != nil {
+		code = "[HANDLER] GetNewsSitemap - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	c.Set(fiber.HeaderContentType, sitemapContentType)
+	return c.Send(body)
+}
+
+func toSitemapURLs(results []entity.SitemapEntity) []sitemap.URL {
+	urls := []sitemap.URL{}
+	for _, result := range results {
+		urls = append(urls, sitemap.URL{Loc: result.Loc, LastMod: result.LastMod})
+	}
+
+	return urls
+}
+
+func NewSitemapHandler(sitemapService service.SitemapService, cfg *config.Config) SitemapHandler {
+	return &sitemapHandler{
+		sitemapService: sitemapService,
+		cfg:            cfg,
+	}
+}
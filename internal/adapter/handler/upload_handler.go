@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"gonews/config"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/conv"
+	"gonews/lib/tus"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+type UploadHandler interface {
+	CheckTusVersion() fiber.Handler
+
+	OptionsUpload(c *fiber.Ctx) error
+	CreateUpload(c *fiber.Ctx) error
+	HeadUpload(c *fiber.Ctx) error
+	PatchUpload(c *fiber.Ctx) error
+	DeleteUpload(c *fiber.Ctx) error
+	GetUpload(c *fiber.Ctx) error
+}
+
+type uploadHandler struct {
+	uploadService service.UploadService
+	cfg           *config.Config
+}
+
+// CheckTusVersion answers every tus request with the server version and
+// rejects clients speaking another protocol version.
+func (uh *uploadHandler) CheckTusVersion() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Set(tus.HeaderResumable, tus.Version)
+		if c.Method() == fiber.MethodOptions || c.Method() == fiber.MethodGet {
+			return c.Next()
+		}
+
+		if c.Get(tus.HeaderResumable) != tus.Version {
+			code = "[HANDLER] CheckTusVersion - 1"
+			zerolog.Ctx(c.UserContext()).Error().Err(tus.ErrVersionNotSupported).Msg(code)
+			c.Set(tus.HeaderVersion, tus.Version)
+			errorResp.Meta.Status = false
+			errorResp.Meta.Message = tus.ErrVersionNotSupported.Error()
+
+			return c.Status(fiber.StatusPreconditionFailed).JSON(errorResp)
+		}
+
+		return c.Next()
+	}
+}
+
+// OptionsUpload implements UploadHandler.
+func (uh *uploadHandler) OptionsUpload(c *fiber.Ctx) error {
+	c.Set(tus.HeaderVersion, tus.Version)
+	c.Set(tus.HeaderExtension, tus.Extensions)
+	if uh.cfg.Upload.MaxSize > 0 {
+		c.Set(tus.HeaderMaxSize, strconv.FormatInt(uh.cfg.Upload.MaxSize, 10))
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// CreateUpload implements UploadHandler.
+func (uh *uploadHandler) CreateUpload(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreateUpload - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	size, err := conv.StringToInt64(c.Get(tus.HeaderLength))
+	if err != nil {
+		code = "[HANDLER] CreateUpload - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = tus.ErrUploadLengthInvalid.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	metadata, err := tus.ParseMetadata(c.Get(tus.HeaderMetadata))
+	if err != nil {
+		code = "[HANDLER] CreateUpload - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := entity.UploadEntity{
+		Size:        size,
+		Metadata:    metadata,
+		CreatedById: int64(claims.UserID),
+	}
+
+	result, err := uh.uploadService.CreateUpload(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] CreateUpload - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(tusErrorStatus(err)).JSON(errorResp)
+	}
+
+	c.Location(c.BaseURL() + strings.TrimSuffix(c.Path(), "/") + "/" + result.ID)
+	setUploadHeaders(c, result)
+
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// HeadUpload implements UploadHandler.
+func (uh *uploadHandler) HeadUpload(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	result, err := uh.uploadService.GetUpload(c.UserContext(), c.Params("uploadID"), int64(claims.UserID))
+	if err != nil {
+		code = "[HANDLER] HeadUpload - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+
+		return c.SendStatus(tusErrorStatus(err))
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(tus.HeaderLength, strconv.FormatInt(result.Size, 10))
+	if len(result.Metadata) > 0 {
+		c.Set(tus.HeaderMetadata, tus.EncodeMetadata(result.Metadata))
+	}
+	setUploadHeaders(c, result)
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// PatchUpload implements UploadHandler.
+func (uh *uploadHandler) PatchUpload(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if c.Get(fiber.HeaderContentType) != tus.ContentType {
+		code = "[HANDLER] PatchUpload - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(tus.ErrContentTypeInvalid).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = tus.ErrContentTypeInvalid.Error()
+
+		return c.Status(fiber.StatusUnsupportedMediaType).JSON(errorResp)
+	}
+
+	offset, err := conv.StringToInt64(c.Get(tus.HeaderOffset))
+	if err != nil || offset < 0 {
+		code = "[HANDLER] PatchUpload - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = tus.ErrUploadOffsetInvalid.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := uh.uploadService.WriteChunk(c.UserContext(), c.Params("uploadID"), int64(claims.UserID), offset, bytes.NewReader(c.Body()))
+	if err != nil {
+		code = "[HANDLER] PatchUpload - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		if result != nil {
+			setUploadHeaders(c, result)
+		}
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(tusErrorStatus(err)).JSON(errorResp)
+	}
+
+	setUploadHeaders(c, result)
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteUpload implements UploadHandler.
+func (uh *uploadHandler) DeleteUpload(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	err = uh.uploadService.TerminateUpload(c.UserContext(), c.Params("uploadID"), int64(claims.UserID))
+	if err != nil {
+		code = "[HANDLER] DeleteUpload - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(tusErrorStatus(err)).JSON(errorResp)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetUpload implements UploadHandler.
+func (uh *uploadHandler) GetUpload(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	result, err := uh.uploadService.GetUpload(c.UserContext(), c.Params("uploadID"), int64(claims.UserID))
+	if err != nil {
+		code = "[HANDLER] GetUpload - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(tusErrorStatus(err)).JSON(errorResp)
+	}
+
+	uploadResp := map[string]interface{}{
+		"id":       result.ID,
+		"size":     result.Size,
+		"offset":   result.Offset,
+		"complete": result.IsComplete(),
+		"urlImage": result.Url,
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = uploadResp
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+func setUploadHeaders(c *fiber.Ctx, upload *entity.UploadEntity) {
+	c.Set(tus.HeaderOffset, strconv.FormatInt(upload.Offset, 10))
+	c.Set(tus.HeaderExpires, upload.ExpiresAt.UTC().Format(tus.ExpiresTimeFormat))
+}
+
+func tusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, tus.ErrUploadNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, tus.ErrUploadExpired):
+		return fiber.StatusGone
+	case errors.Is(err, tus.ErrOffsetMismatch):
+		return fiber.StatusConflict
+	case errors.Is(err, tus.ErrMaxSizeExceeded):
+		return fiber.StatusRequestEntityTooLarge
+	case errors.Is(err, tus.ErrUploadLengthInvalid):
+		return fiber.StatusBadRequest
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func NewUploadHandler(uploadService service.UploadService, cfg *config.Config) UploadHandler {
+	return &uploadHandler{
+		uploadService: uploadService,
+		cfg:           cfg,
+	}
+}
@@ -9,7 +9,7 @@ import (
 	validatorLib "gonews/lib/validator"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 )
 
 type UserHandler interface {
@@ -26,17 +26,17 @@ func (u *userHandler) GetUserByID(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code := "[HANDLER] GetUserByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized access"
 
 		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
 	}
 
-	user, err := u.userService.GetUserByID(c.Context(), int64(claims.UserID))
+	user, err := u.userService.GetUserByID(c.UserContext(), int64(claims.UserID))
 	if err != nil {
 		code := "[HANDLER] GetUserByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -49,6 +49,7 @@ func (u *userHandler) GetUserByID(c *fiber.Ctx) error {
 		ID:    user.ID,
 		Name:  user.Name,
 		Email: user.Email,
+		Role:  user.Role,
 	}
 	defaultSuccessResponse.Data = resp
 
@@ -60,7 +61,7 @@ func (u *userHandler) UpdatePassword(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*entity.JwtData)
 	if claims.UserID == 0 {
 		code := "[HANDLER] UpdatePassword - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Unauthorized access"
 
@@ -70,7 +71,7 @@ func (u *userHandler) UpdatePassword(c *fiber.Ctx) error {
 	var req request.UpdatePasswordRequest
 	if err = c.BodyParser(&req); err != nil {
 		code := "[HANDLER] UpdatePassword - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = "Invalid request body"
 
@@ -79,7 +80,7 @@ func (u *userHandler) UpdatePassword(c *fiber.Ctx) error {
 
 	if err = validatorLib.ValidateStruct(&req); err != nil {
 		code := "[HANDLER] UpdatePassword - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -89,17 +90,17 @@ func (u *userHandler) UpdatePassword(c *fiber.Ctx) error {
 	if req.ConfirmPassword != req.NewPassword {
 		code := "[HANDLER] UpdatePassword - 4"
 		err = errors.New("passwords do not match")
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
 		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
 	}
 
-	err = u.userService.UpdatePassword(c.Context(), req.NewPassword, int64(claims.UserID))
+	err = u.userService.UpdatePassword(c.UserContext(), req.NewPassword, int64(claims.UserID))
 	if err != nil {
 		code := "[HANDLER] UpdatePassword - 5"
-		log.Errorw(code, err)
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
 		errorResp.Meta.Status = false
 		errorResp.Meta.Message = err.Error()
 
@@ -0,0 +1,436 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"gonews/internal/adapter/handler/request"
+	"gonews/internal/adapter/handler/response"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"gonews/lib/conv"
+	validatorLib "gonews/lib/validator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler interface {
+	GetWebhooks(c *fiber.Ctx) error
+	GetWebhookByID(c *fiber.Ctx) error
+	CreateWebhook(c *fiber.Ctx) error
+	UpdateWebhook(c *fiber.Ctx) error
+	DeleteWebhook(c *fiber.Ctx) error
+
+	GetDeliveries(c *fiber.Ctx) error
+	GetDeliveryByID(c *fiber.Ctx) error
+	RedeliverDelivery(c *fiber.Ctx) error
+}
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// GetWebhooks implements WebhookHandler.
+func (wh *webhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	results, err := wh.webhookService.GetWebhooks(c.UserContext())
+	if err != nil {
+		code = "[HANDLER] GetWebhooks - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	respWebhooks := []response.WebhookResponse{}
+	for i := range results {
+		respWebhooks = append(respWebhooks, toWebhookResponse(&results[i]))
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = respWebhooks
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetWebhookByID implements WebhookHandler.
+func (wh *webhookHandler) GetWebhookByID(c *fiber.Ctx) error {
+	webhookID, err := conv.StringToInt64(c.Params("webhookID"))
+	if err != nil {
+		code = "[HANDLER] GetWebhookByID - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := wh.webhookService.GetWebhookByID(c.UserContext(), webhookID)
+	if err != nil {
+		code = "[HANDLER] GetWebhookByID - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = toWebhookResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// CreateWebhook implements WebhookHandler. The signing secret is only
+// returned here.
+func (wh *webhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*entity.JwtData)
+	if claims.UserID == 0 {
+		code = "[HANDLER] CreateWebhook - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Unauthorized"
+
+		return c.Status(fiber.StatusUnauthorized).JSON(errorResp)
+	}
+
+	var req request.WebhookRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] CreateWebhook - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] CreateWebhook - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := toWebhookEntity(req)
+	reqEntity.CreatedById = int64(claims.UserID)
+
+	result, err := wh.webhookService.CreateWebhook(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] CreateWebhook - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, service.ErrWebhookUrlInvalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	resp := toWebhookResponse(result)
+	resp.Secret = result.Secret
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Webhook created successfully"
+	defaultSuccessResponse.Data = resp
+
+	return c.Status(fiber.StatusCreated).JSON(defaultSuccessResponse)
+}
+
+// UpdateWebhook implements WebhookHandler. Setting an auto-disabled
+// webhook active again also clears its failure count.
+func (wh *webhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	webhookID, err := conv.StringToInt64(c.Params("webhookID"))
+	if err != nil {
+		code = "[HANDLER] UpdateWebhook - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	var req request.WebhookRequest
+	if err = c.BodyParser(&req); err != nil {
+		code = "[HANDLER] UpdateWebhook - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = "Invalid request body"
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	if err = validatorLib.ValidateStruct(&req); err != nil {
+		code = "[HANDLER] UpdateWebhook - 3"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	reqEntity := toWebhookEntity(req)
+	reqEntity.ID = webhookID
+
+	result, err := wh.webhookService.UpdateWebhook(c.UserContext(), reqEntity)
+	if err != nil {
+		code = "[HANDLER] UpdateWebhook - 4"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, service.ErrWebhookUrlInvalid) {
+			return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Webhook updated successfully"
+	defaultSuccessResponse.Data = toWebhookResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// DeleteWebhook implements WebhookHandler.
+func (wh *webhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	webhookID, err := conv.StringToInt64(c.Params("webhookID"))
+	if err != nil {
+		code = "[HANDLER] DeleteWebhook - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	err = wh.webhookService.DeleteWebhook(c.UserContext(), webhookID)
+	if err != nil {
+		code = "[HANDLER] DeleteWebhook - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Webhook deleted successfully"
+	defaultSuccessResponse.Data = nil
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetDeliveries implements WebhookHandler.
+func (wh *webhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	webhookID, err := conv.StringToInt64(c.Params("webhookID"))
+	if err != nil {
+		code = "[HANDLER] GetDeliveries - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	results, err := wh.webhookService.GetDeliveries(c.UserContext(), webhookID)
+	if err != nil {
+		code = "[HANDLER] GetDeliveries - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	respDeliveries := []response.WebhookDeliveryResponse{}
+	for i := range results {
+		respDeliveries = append(respDeliveries, toWebhookDeliveryResponse(&results[i]))
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = respDeliveries
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// GetDeliveryByID implements WebhookHandler.
+func (wh *webhookHandler) GetDeliveryByID(c *fiber.Ctx) error {
+	webhookID, deliveryID, err := deliveryParams(c)
+	if err != nil {
+		code = "[HANDLER] GetDeliveryByID - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := wh.webhookService.GetDelivery(c.UserContext(), webhookID, deliveryID)
+	if err != nil {
+		code = "[HANDLER] GetDeliveryByID - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Success"
+	defaultSuccessResponse.Data = toWebhookDeliveryResponse(result)
+
+	return c.JSON(defaultSuccessResponse)
+}
+
+// RedeliverDelivery implements WebhookHandler. The payload is queued again
+// as a new delivery, which the worker sends on its next run.
+func (wh *webhookHandler) RedeliverDelivery(c *fiber.Ctx) error {
+	webhookID, deliveryID, err := deliveryParams(c)
+	if err != nil {
+		code = "[HANDLER] RedeliverDelivery - 1"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		return c.Status(fiber.StatusBadRequest).JSON(errorResp)
+	}
+
+	result, err := wh.webhookService.Redeliver(c.UserContext(), webhookID, deliveryID)
+	if err != nil {
+		code = "[HANDLER] RedeliverDelivery - 2"
+		zerolog.Ctx(c.UserContext()).Error().Err(err).Msg(code)
+		errorResp.Meta.Status = false
+		errorResp.Meta.Message = err.Error()
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(errorResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(errorResp)
+	}
+
+	defaultSuccessResponse.Meta.Status = true
+	defaultSuccessResponse.Pagination = nil
+	defaultSuccessResponse.Meta.Message = "Redelivery queued"
+	defaultSuccessResponse.Data = toWebhookDeliveryResponse(result)
+
+	return c.Status(fiber.StatusAccepted).JSON(defaultSuccessResponse)
+}
+
+func deliveryParams(c *fiber.Ctx) (int64, int64, error) {
+	webhookID, err := conv.StringToInt64(c.Params("webhookID"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	deliveryID, err := conv.StringToInt64(c.Params("deliveryID"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return webhookID, deliveryID, nil
+}
+
+// toWebhookEntity maps the request; a webhook is active unless asked
+// otherwise.
+func toWebhookEntity(req request.WebhookRequest) entity.WebhookEntity {
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+
+	return entity.WebhookEntity{
+		Url:         req.Url,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      active,
+	}
+}
+
+func toWebhookResponse(webhook *entity.WebhookEntity) response.WebhookResponse {
+	resp := response.WebhookResponse{
+		ID:             webhook.ID,
+		Url:            webhook.Url,
+		Description:    webhook.Description,
+		Events:         webhook.Events,
+		Active:         webhook.Active,
+		FailureCount:   webhook.FailureCount,
+		DisabledReason: webhook.DisabledReason,
+		CreatedById:    webhook.CreatedById,
+		CreatedAt:      webhook.CreatedAt.Format(time.RFC3339),
+	}
+	if resp.Events == nil {
+		resp.Events = []string{}
+	}
+	if webhook.DisabledAt != nil {
+		resp.DisabledAt = webhook.DisabledAt.Format(time.RFC3339)
+	}
+	if webhook.UpdatedAt != nil {
+		resp.UpdatedAt = webhook.UpdatedAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
+
+func toWebhookDeliveryResponse(delivery *entity.WebhookDeliveryEntity) response.WebhookDeliveryResponse {
+	resp := response.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.WebhookID,
+		Event:          delivery.Event,
+		Payload:        json.RawMessage(delivery.Payload),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		DurationMs:     delivery.DurationMs,
+		RedeliveryOfID: delivery.RedeliveryOfID,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if !json.Valid(resp.Payload) {
+		resp.Payload = json.RawMessage("null")
+	}
+	if delivery.Status == entity.WebhookDeliveryPending {
+		resp.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	if delivery.LastAttemptAt != nil {
+		resp.LastAttemptAt = delivery.LastAttemptAt.Format(time.RFC3339)
+	}
+
+	return resp
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandler{
+		webhookService: webhookService,
+	}
+}
@@ -2,19 +2,36 @@ package imagekit
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"gonews/config"
 	"gonews/internal/core/domain/entity"
+	"gonews/lib/metrics"
+	"gonews/lib/netguard"
+	"gonews/lib/tracing"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"time"
 )
 
+// transport traces outgoing calls and passes the trace context on. Ping
+// keeps the default transport, as readiness checks run outside any request.
+var transport = tracing.Transport(http.DefaultTransport)
+
+// remoteTransport is transport for URLs from outside, such as the images of
+// an import, which must not reach the internal network.
+var remoteTransport = tracing.Transport(netguard.Transport())
+
 type ImageKitAdapter interface {
-	UploadImage(req *entity.FileUploadEntity) (string, error)
+	UploadImage(ctx context.Context, req *entity.FileUploadEntity) (string, error)
+	FetchImage(ctx context.Context, url string) ([]byte, error)
+	FetchRemoteImage(ctx context.Context, url string) ([]byte, error)
+	DownloadFile(ctx context.Context, url string, dst string) error
+	Ping(ctx context.Context) error
 }
 
 type imageKitAdapter struct {
@@ -25,7 +42,11 @@ func NewImageKitAdapter(cfg *config.Config) ImageKitAdapter {
 	return &imageKitAdapter{cfg: cfg}
 }
 
-func (ik *imageKitAdapter) UploadImage(req *entity.FileUploadEntity) (string, error) {
+func (ik *imageKitAdapter) UploadImage(ctx context.Context, req *entity.FileUploadEntity) (url string, err error) {
+	ctx, span := tracing.Start(ctx, "imagekit.upload")
+	defer tracing.End(span, &err)
+	defer observe("upload", time.Now(), &err)
+
 	file, err := os.Open(req.Path)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file: %w", err)
@@ -51,7 +72,7 @@ func (ik *imageKitAdapter) UploadImage(req *entity.FileUploadEntity) (string, er
 	writer.Close()
 
 	reqUrl := "https://upload.imagekit.io/api/v1/files/upload"
-	reqHttp, err := http.NewRequest("POST", reqUrl, &b)
+	reqHttp, err := http.NewRequestWithContext(ctx, "POST", reqUrl, &b)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -60,7 +81,7 @@ func (ik *imageKitAdapter) UploadImage(req *entity.FileUploadEntity) (string, er
 	encodedKey := base64.StdEncoding.EncodeToString([]byte(ik.cfg.IK.PrivateKey + ":"))
 	reqHttp.Header.Set("Authorization", "Basic "+encodedKey)
 
-	client := &http.Client{}
+	client := &http.Client{Transport: transport}
 	resp, err := client.Do(reqHttp)
 	if err != nil {
 		return "", fmt.Errorf("upload failed: %w", err)
@@ -82,3 +103,125 @@ func (ik *imageKitAdapter) UploadImage(req *entity.FileUploadEntity) (string, er
 	return result.Url, nil
 }
 
+// FetchImage downloads a stored file, refusing anything over 32MB.
+func (ik *imageKitAdapter) FetchImage(ctx context.Context, url string) (data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "imagekit.fetch")
+	defer tracing.End(span, &err)
+	defer observe("fetch", time.Now(), &err)
+
+	return fetch(ctx, transport, url)
+}
+
+// FetchRemoteImage downloads an image from another site, like FetchImage,
+// but only from public http and https addresses.
+func (ik *imageKitAdapter) FetchRemoteImage(ctx context.Context, url string) (data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "imagekit.fetch_remote")
+	defer tracing.End(span, &err)
+	defer observe("fetch_remote", time.Now(), &err)
+
+	if err = netguard.CheckURL(url); err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+
+	return fetch(ctx, remoteTransport, url)
+}
+
+func fetch(ctx context.Context, rt http.RoundTripper, url string) ([]byte, error) {
+	reqHttp, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Transport: rt, Timeout: 15 * time.Second}
+	resp, err := client.Do(reqHttp)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch failed: status %d", resp.StatusCode)
+	}
+
+	const maxSize = 32 << 20
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, fmt.Errorf("fetch failed: file larger than %d bytes", maxSize)
+	}
+
+	return data, nil
+}
+
+// DownloadFile streams a stored file to dst, for files too large to hold in
+// memory such as import exports.
+func (ik *imageKitAdapter) DownloadFile(ctx context.Context, url string, dst string) (err error) {
+	ctx, span := tracing.Start(ctx, "imagekit.download")
+	defer tracing.End(span, &err)
+	defer observe("download", time.Now(), &err)
+
+	reqHttp, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Minute}
+	resp, err := client.Do(reqHttp)
+	if err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download failed: status %d", resp.StatusCode)
+	}
+
+	file, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("download failed: %w", err)
+	}
+
+	return file.Close()
+}
+
+// Ping lists a single file through the ImageKit API, which checks both that
+// it is reachable and that the private key is accepted.
+func (ik *imageKitAdapter) Ping(ctx context.Context) (err error) {
+	defer observe("ping", time.Now(), &err)
+
+	reqHttp, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.imagekit.io/v1/files?limit=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	encodedKey := base64.StdEncoding.EncodeToString([]byte(ik.cfg.IK.PrivateKey + ":"))
+	reqHttp.Header.Set("Authorization", "Basic "+encodedKey)
+
+	resp, err := http.DefaultClient.Do(reqHttp)
+	if err != nil {
+		return fmt.Errorf("ping failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping failed: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// observe records the latency of an ImageKit call and whether it failed.
+func observe(operation string, start time.Time, err *error) {
+	metrics.ImageKitDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if *err != nil {
+		metrics.ImageKitErrors.WithLabelValues(operation).Inc()
+	}
+}
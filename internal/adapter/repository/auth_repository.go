@@ -5,7 +5,7 @@ import (
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/domain/model"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
@@ -23,18 +23,20 @@ type authRepository struct {
 func (a *authRepository) GetUserByEmail(ctx context.Context, req entity.LoginRequest) (*entity.UserEntity, error) {
 	var modelUser model.User
 
-	err = a.db.Where("email = ?", req.Email).First(&modelUser).Error
+	err = a.db.WithContext(ctx).Where("email = ?", req.Email).First(&modelUser).Error
 	if err != nil {
 		code = "[REPOSITORY] GetUserByEmail - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
 	resp := entity.UserEntity{
-		ID:       modelUser.ID,
-		Name:     modelUser.Name,
-		Email:    modelUser.Email,
-		Password: modelUser.Password,
+		ID:            modelUser.ID,
+		Name:          modelUser.Name,
+		Email:         modelUser.Email,
+		Password:      modelUser.Password,
+		Role:          modelUser.Role,
+		DeactivatedAt: modelUser.DeactivatedAt,
 	}
 
 	return &resp, nil
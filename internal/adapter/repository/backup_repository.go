@@ -0,0 +1,454 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/domain/model"
+	"gonews/lib/backup"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BackupSource yields the records of a backup in the order they have to be
+// restored in.
+type BackupSource interface {
+	Users(fn func(entity.BackupUserEntity) error) error
+	Categories(fn func(entity.BackupCategoryEntity) error) error
+	Contents(fn func(entity.BackupContentEntity) error) error
+	Images(fn func(entity.BackupImageEntity) error) error
+}
+
+type BackupRepository interface {
+	GetBackupUsers(ctx context.Context, afterID int64, limit int) ([]entity.BackupUserEntity, error)
+	GetBackupCategories(ctx context.Context, filter backup.Filter, afterID int64, limit int) ([]entity.BackupCategoryEntity, error)
+	GetBackupContents(ctx context.Context, filter backup.Filter, afterID int64, limit int) ([]entity.BackupContentEntity, error)
+	GetBackupImages(ctx context.Context, afterID int64, limit int) ([]entity.BackupImageEntity, error)
+
+	CountRestoredRows(ctx context.Context) (int64, error)
+	Restore(ctx context.Context, src BackupSource) (*entity.BackupRestoreEntity, error)
+}
+
+type backupRepository struct {
+	db *gorm.DB
+}
+
+// restoredTables are the tables a restore writes with the ids from the
+// archive, in the order their sequences are reset.
+var restoredTables = []string{"users", "categories", "contents", "content_attachments", "images"}
+
+// GetBackupUsers implements BackupRepository.
+func (b *backupRepository) GetBackupUsers(ctx context.Context, afterID int64, limit int) ([]entity.BackupUserEntity, error) {
+	var modelUsers []model.User
+	err = b.db.WithContext(ctx).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&modelUsers).Error
+	if err != nil {
+		code = "[REPOSITORY] GetBackupUsers - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.BackupUserEntity{}
+	for _, val := range modelUsers {
+		resps = append(resps, entity.BackupUserEntity{
+			ID:            val.ID,
+			Name:          val.Name,
+			Email:         val.Email,
+			Role:          val.Role,
+			DeactivatedAt: val.DeactivatedAt,
+			CreatedAt:     val.CreatedAt,
+			UpdatedAt:     val.UpdatedAt,
+		})
+	}
+
+	return resps, nil
+}
+
+// GetBackupCategories implements BackupRepository. Trashed categories are
+// included.
+func (b *backupRepository) GetBackupCategories(ctx context.Context, filter backup.Filter, afterID int64, limit int) ([]entity.BackupCategoryEntity, error) {
+	sqlMain := b.db.WithContext(ctx).Unscoped().Where("id > ?", afterID)
+	if filter.Category != "" {
+		sqlMain = sqlMain.Where("slug = ?", filter.Category)
+	}
+
+	var modelCategories []model.Category
+	err = sqlMain.Order("id ASC").Limit(limit).Find(&modelCategories).Error
+	if err != nil {
+		code = "[REPOSITORY] GetBackupCategories - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.BackupCategoryEntity{}
+	for _, val := range modelCategories {
+		resps = append(resps, entity.BackupCategoryEntity{
+			ID:          val.ID,
+			Title:       val.Title,
+			Slug:        val.Slug,
+			CreatedByID: val.CreatedByID,
+			CreatedAt:   val.CreatedAt,
+			UpdatedAt:   val.UpdatedAt,
+			Version:     val.Version,
+			DeletedAt:   deletedAtPtr(val.DeletedAt),
+		})
+	}
+
+	return resps, nil
+}
+
+// GetBackupContents implements BackupRepository. Trashed contents are
+// included; the date range applies to the creation date.
+func (b *backupRepository) GetBackupContents(ctx context.Context, filter backup.Filter, afterID int64, limit int) ([]entity.BackupContentEntity, error) {
+	sqlMain := b.db.WithContext(ctx).Unscoped().Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id > ?", afterID)
+	if filter.From != nil {
+		sqlMain = sqlMain.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		sqlMain = sqlMain.Where("created_at < ?", *filter.To)
+	}
+	if filter.Category != "" {
+		sqlMain = sqlMain.Where("category_id IN (?)", b.db.WithContext(ctx).Unscoped().Model(&model.Category{}).Select("id").Where("slug = ?", filter.Category))
+	}
+
+	var modelContents []model.Content
+	err = sqlMain.Order("id ASC").Limit(limit).Find(&modelContents).Error
+	if err != nil {
+		code = "[REPOSITORY] GetBackupContents - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.BackupContentEntity{}
+	for _, val := range modelContents {
+		tags := []string{}
+		if val.Tags != "" {
+			tags = strings.Split(val.Tags, ",")
+		}
+
+		attachments := []entity.BackupAttachmentEntity{}
+		for _, attachment := range val.Attachments {
+			attachments = append(attachments, entity.BackupAttachmentEntity{
+				ID:         attachment.ID,
+				Type:       attachment.Type,
+				Url:        attachment.Url,
+				FileName:   attachment.FileName,
+				MimeType:   attachment.MimeType,
+				Size:       attachment.Size,
+				DurationMs: attachment.DurationMs,
+				VideoCodec: attachment.VideoCodec,
+				AudioCodec: attachment.AudioCodec,
+				Width:      attachment.Width,
+				Height:     attachment.Height,
+				SampleRate: attachment.SampleRate,
+				Channels:   attachment.Channels,
+				PosterUrl:  attachment.PosterUrl,
+				CreatedAt:  attachment.CreatedAt,
+				UpdatedAt:  attachment.UpdatedAt,
+			})
+		}
+
+		resps = append(resps, entity.BackupContentEntity{
+			ID:                val.ID,
+			Title:             val.Title,
+			Slug:              val.Slug,
+			Excerpt:           val.Excerpt,
+			Description:       val.Description,
+			BodyFormat:        val.BodyFormat,
+			DescriptionSource: val.DescriptionSource,
+			Body:              val.Body,
+			TableOfContents:   val.TableOfContents,
+			PlainText:         val.PlainText,
+			Image:             val.Image,
+			Tags:              tags,
+			Status:            val.Status,
+			CategoryID:        val.CategoryID,
+			CreatedByID:       val.CreatedByID,
+			CreatedAt:         val.CreatedAt,
+			UpdatedAt:         val.UpdatedAt,
+			Version:           val.Version,
+			DeletedAt:         deletedAtPtr(val.DeletedAt),
+			Attachments:       attachments,
+		})
+	}
+
+	return resps, nil
+}
+
+// GetBackupImages implements BackupRepository.
+func (b *backupRepository) GetBackupImages(ctx context.Context, afterID int64, limit int) ([]entity.BackupImageEntity, error) {
+	var modelImages []model.Image
+	err = b.db.WithContext(ctx).Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&modelImages).Error
+	if err != nil {
+		code = "[REPOSITORY] GetBackupImages - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.BackupImageEntity{}
+	for _, val := range modelImages {
+		resps = append(resps, entity.BackupImageEntity{
+			ID:          val.ID,
+			Url:         val.Url,
+			FocalX:      val.FocalX,
+			FocalY:      val.FocalY,
+			CreatedByID: val.CreatedByID,
+			CreatedAt:   val.CreatedAt,
+			UpdatedAt:   val.UpdatedAt,
+		})
+	}
+
+	return resps, nil
+}
+
+// CountRestoredRows implements BackupRepository. Users are left out: a
+// restore matches them by email.
+func (b *backupRepository) CountRestoredRows(ctx context.Context) (int64, error) {
+	var total int64
+	for _, table := range []string{"categories", "contents", "images"} {
+		var count int64
+		err = b.db.WithContext(ctx).Table(table).Count(&count).Error
+		if err != nil {
+			code = "[REPOSITORY] CountRestoredRows - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return 0, err
+		}
+		total += count
+	}
+
+	return total, nil
+}
+
+// Restore implements BackupRepository. Everything is restored in a single
+// transaction with the ids from the archive, except users that already
+// exist: those are matched by email and the rows pointing at them follow.
+func (b *backupRepository) Restore(ctx context.Context, src BackupSource) (*entity.BackupRestoreEntity, error) {
+	result := entity.BackupRestoreEntity{}
+	userIDs := map[int64]int64{}
+
+	err = b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := src.Users(func(req entity.BackupUserEntity) error {
+			var existing model.User
+			err := tx.Where("lower(email) = lower(?)", req.Email).First(&existing).Error
+			if err == nil {
+				userIDs[req.ID] = existing.ID
+				result.UsersMatched++
+				return nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			modelUser := model.User{
+				Name:          req.Name,
+				Email:         req.Email,
+				Password:      req.Password,
+				Role:          req.Role,
+				DeactivatedAt: req.DeactivatedAt,
+				CreatedAt:     req.CreatedAt,
+				UpdatedAt:     req.UpdatedAt,
+			}
+			// archives from before roles only held admins
+			if modelUser.Role == "" {
+				modelUser.Role = entity.RoleAdmin
+			}
+
+			var taken int64
+			if err = tx.Model(&model.User{}).Where("id = ?", req.ID).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken == 0 {
+				modelUser.ID = req.ID
+			}
+
+			if err = tx.Create(&modelUser).Error; err != nil {
+				return err
+			}
+
+			userIDs[req.ID] = modelUser.ID
+			result.UsersCreated++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// rows without a user, or with id 0 from older archives, stay
+		// without one
+		userID := func(id *int64) (*int64, error) {
+			if id == nil || *id == 0 {
+				return nil, nil
+			}
+			if mapped, ok := userIDs[*id]; ok {
+				return &mapped, nil
+			}
+			return nil, fmt.Errorf("user %d is not in the archive", *id)
+		}
+
+		err = src.Categories(func(req entity.BackupCategoryEntity) error {
+			createdByID, err := userID(req.CreatedByID)
+			if err != nil {
+				return fmt.Errorf("category %d: %w", req.ID, err)
+			}
+
+			modelCategory := model.Category{
+				ID:          req.ID,
+				Title:       req.Title,
+				Slug:        req.Slug,
+				CreatedByID: createdByID,
+				CreatedAt:   req.CreatedAt,
+				UpdatedAt:   req.UpdatedAt,
+				Version:     req.Version,
+				DeletedAt:   toDeletedAt(req.DeletedAt),
+			}
+			if err = tx.Omit(clause.Associations).Create(&modelCategory).Error; err != nil {
+				return fmt.Errorf("category %d: %w", req.ID, err)
+			}
+
+			result.CategoriesCreated++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = src.Contents(func(req entity.BackupContentEntity) error {
+			createdByID, err := userID(req.CreatedByID)
+			if err != nil {
+				return fmt.Errorf("content %d: %w", req.ID, err)
+			}
+
+			modelContent := model.Content{
+				ID:                req.ID,
+				Title:             req.Title,
+				Slug:              req.Slug,
+				Excerpt:           req.Excerpt,
+				Description:       req.Description,
+				BodyFormat:        req.BodyFormat,
+				DescriptionSource: req.DescriptionSource,
+				Body:              req.Body,
+				TableOfContents:   req.TableOfContents,
+				PlainText:         req.PlainText,
+				Image:             req.Image,
+				Tags:              strings.Join(req.Tags, ","),
+				Status:            req.Status,
+				CategoryID:        req.CategoryID,
+				CreatedByID:       createdByID,
+				CreatedAt:         req.CreatedAt,
+				UpdatedAt:         req.UpdatedAt,
+				Version:           req.Version,
+				DeletedAt:         toDeletedAt(req.DeletedAt),
+			}
+			if err = tx.Omit(clause.Associations).Create(&modelContent).Error; err != nil {
+				return fmt.Errorf("content %d: %w", req.ID, err)
+			}
+
+			for _, attachment := range req.Attachments {
+				modelAttachment := model.ContentAttachment{
+					ID:         attachment.ID,
+					ContentID:  req.ID,
+					Type:       attachment.Type,
+					Url:        attachment.Url,
+					FileName:   attachment.FileName,
+					MimeType:   attachment.MimeType,
+					Size:       attachment.Size,
+					DurationMs: attachment.DurationMs,
+					VideoCodec: attachment.VideoCodec,
+					AudioCodec: attachment.AudioCodec,
+					Width:      attachment.Width,
+					Height:     attachment.Height,
+					SampleRate: attachment.SampleRate,
+					Channels:   attachment.Channels,
+					PosterUrl:  attachment.PosterUrl,
+					CreatedAt:  attachment.CreatedAt,
+					UpdatedAt:  attachment.UpdatedAt,
+				}
+				if err = tx.Create(&modelAttachment).Error; err != nil {
+					return fmt.Errorf("content %d attachment %d: %w", req.ID, attachment.ID, err)
+				}
+			}
+
+			result.ContentsCreated++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		err = src.Images(func(req entity.BackupImageEntity) error {
+			modelImage := model.Image{
+				ID:        req.ID,
+				Url:       req.Url,
+				FocalX:    req.FocalX,
+				FocalY:    req.FocalY,
+				CreatedAt: req.CreatedAt,
+				UpdatedAt: req.UpdatedAt,
+			}
+
+			// images outlive the user who created them
+			sqlMain := tx.Omit("created_by_id")
+			if req.CreatedByID != 0 {
+				createdByID, err := userID(&req.CreatedByID)
+				if err != nil {
+					return fmt.Errorf("image %d: %w", req.ID, err)
+				}
+				modelImage.CreatedByID = *createdByID
+				sqlMain = tx
+			}
+
+			if err := sqlMain.Create(&modelImage).Error; err != nil {
+				return fmt.Errorf("image %d: %w", req.ID, err)
+			}
+
+			result.ImagesCreated++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// rows were inserted with explicit ids, so the sequences have to
+		// catch up before the next regular insert
+		for _, table := range restoredTables {
+			err = tx.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', 'id'), COALESCE(MAX(id), 0) + 1, false) FROM %s", table, table)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		code = "[REPOSITORY] Restore - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func deletedAtPtr(deletedAt gorm.DeletedAt) *time.Time {
+	if !deletedAt.Valid {
+		return nil
+	}
+
+	return &deletedAt.Time
+}
+
+func toDeletedAt(deletedAt *time.Time) gorm.DeletedAt {
+	if deletedAt == nil {
+		return gorm.DeletedAt{}
+	}
+
+	return gorm.DeletedAt{Time: *deletedAt, Valid: true}
+}
+
+func NewBackupRepository(db *gorm.DB) BackupRepository {
+	return &backupRepository{db: db}
+}
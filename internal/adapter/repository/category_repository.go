@@ -6,16 +6,28 @@ import (
 	"fmt"
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/domain/model"
-	"github.com/gofiber/fiber/v2/log"
+	"gonews/lib/revision"
+	"time"
+
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrCategoriesNotFound is returned by GetCategories when there are none.
+var ErrCategoriesNotFound = errors.New("categories data is not found")
+
 type CategoryRepository interface {
 	GetCategories(ctx context.Context) ([]entity.CategoryEntity, error)
 	GetCategoryByID(ctx context.Context, id int64) (*entity.CategoryEntity, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*entity.CategoryEntity, error)
 	CreateCategory(ctx context.Context, req entity.CategoryEntity) error
 	EditCategory(ctx context.Context, req entity.CategoryEntity) error
-	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategory(ctx context.Context, id int64, version int64) error
+	GetTrashedCategories(ctx context.Context) ([]entity.CategoryEntity, error)
+	RestoreCategory(ctx context.Context, id int64) error
+	PurgeCategory(ctx context.Context, id int64) error
+	PurgeTrashedCategories(ctx context.Context, before time.Time) (int64, error)
 }
 
 type categoryRepository struct {
@@ -25,11 +37,11 @@ type categoryRepository struct {
 // CreateCategory implements CategoryRepository.
 func (c *categoryRepository) CreateCategory(ctx context.Context, req entity.CategoryEntity) error {
 	var countSlug int64
-	err = c.db.Table("categories").Where("slug = ?", req.Slug).Count(&countSlug).Error
+	err = c.db.WithContext(ctx).Table("categories").Where("slug = ?", req.Slug).Count(&countSlug).Error
 
 	if err != nil {
 		code = "[REPOSITORY] CreateCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
@@ -42,13 +54,14 @@ func (c *categoryRepository) CreateCategory(ctx context.Context, req entity.Cate
 	modelCategory := model.Category{
 		Title: req.Title,
 		Slug: slug,
-		CreatedByID: req.User.ID,
+		CreatedByID: nullableUserID(req.User.ID),
+		Version: 1,
 	}
 
-	err = c.db.Create(&modelCategory).Error
+	err = c.db.WithContext(ctx).Create(&modelCategory).Error
 	if err != nil {
 		code = "[REPOSITORY] CreateCategory - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
@@ -56,12 +69,12 @@ func (c *categoryRepository) CreateCategory(ctx context.Context, req entity.Cate
 }
 
 // DeleteCategory implements CategoryRepository.
-func (c *categoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+func (c *categoryRepository) DeleteCategory(ctx context.Context, id int64, version int64) error {
 	var count int64
-	err = c.db.Table("contents").Where("category_id = ?", id).Count(&count).Error
+	err = c.db.WithContext(ctx).Table("contents").Where("category_id = ? AND deleted_at IS NULL", id).Count(&count).Error
 	if err != nil {
 		code = "[REPOSITORY] DeleteCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
@@ -69,22 +82,45 @@ func (c *categoryRepository) DeleteCategory(ctx context.Context, id int64) error
 		return errors.New("cannot delete a category that has associated contents")
 	}
 
-	err = c.db.Where("id = ?", id).Delete(&model.Category{}).Error
+	err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryVersion(tx, id, version); err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&model.Category{}).Error
+	})
 	if err != nil {
 		code = "[REPOSITORY] DeleteCategory - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+	return nil
+}
+
+// checkCategoryVersion locks the category row for the rest of the
+// transaction and fails with revision.ErrRevisionConflict when it no longer
+// has the expected version.
+func checkCategoryVersion(tx *gorm.DB, id int64, version int64) error {
+	var modelCategory model.Category
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "version").Where("id = ?", id).First(&modelCategory).Error
+	if err != nil {
 		return err
 	}
+
+	if version != revision.Any && modelCategory.Version != version {
+		return revision.ErrRevisionConflict
+	}
+
 	return nil
 }
 
 // EditCategory implements CategoryRepository.
 func (c *categoryRepository) EditCategory(ctx context.Context, req entity.CategoryEntity) error {
 	var countSlug int64
-	err = c.db.Table("categories").Where("slug = ?", req.Slug).Count(&countSlug).Error
+	err = c.db.WithContext(ctx).Table("categories").Where("slug = ?", req.Slug).Count(&countSlug).Error
 	if err != nil {
 		code = "[REPOSITORY] EditCategoryByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 	countSlug = countSlug + 1
@@ -95,13 +131,23 @@ func (c *categoryRepository) EditCategory(ctx context.Context, req entity.Catego
 	modelCategory := model.Category{
 		Title: req.Title,
 		Slug: slug,
-		CreatedByID: req.User.ID,
+		CreatedByID: nullableUserID(req.User.ID),
 	}
 
-	err = c.db.Where("id = ?", req.ID).Updates(&modelCategory).Error
+	err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryVersion(tx, req.ID, req.Version); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", req.ID).Updates(&modelCategory).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.Category{}).Where("id = ?", req.ID).Update("version", gorm.Expr("version + 1")).Error
+	})
 	if err != nil {
 		code = "[REPOSITORY] EditCategoryByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
@@ -112,17 +158,17 @@ func (c *categoryRepository) EditCategory(ctx context.Context, req entity.Catego
 func (c *categoryRepository) GetCategories(ctx context.Context) ([]entity.CategoryEntity, error) {
 	var modelCategories []model.Category
 
-	err = c.db.Order("created_at DESC").Preload("User").Find(&modelCategories).Error
+	err = c.db.WithContext(ctx).Order("created_at DESC").Preload("User").Find(&modelCategories).Error
 	if err != nil {
 		code = "[REPOSITORY] GetCategories - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
 	if len(modelCategories) == 0 {
 		code = "[REPOSITORY] GetCategories - 2"
-		err = errors.New("categories data is not found")
-		log.Errorw(code, err)
+		err = ErrCategoriesNotFound
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
@@ -132,10 +178,13 @@ func (c *categoryRepository) GetCategories(ctx context.Context) ([]entity.Catego
 			ID: val.ID,
 			Title: val.Title,
 			Slug: val.Slug,
+			Version: val.Version,
+			UpdatedAt: updatedAt(val.CreatedAt, val.UpdatedAt),
 			User: entity.UserEntity{
 				ID: val.User.ID,
 				Name: val.User.Name,
 				Email: val.User.Email,
+				UpdatedAt: updatedAt(val.User.CreatedAt, val.User.UpdatedAt),
 			},
 		})
 	}
@@ -146,10 +195,10 @@ func (c *categoryRepository) GetCategories(ctx context.Context) ([]entity.Catego
 // GetCategoryByID implements CategoryRepository.
 func (c *categoryRepository) GetCategoryByID(ctx context.Context, id int64) (*entity.CategoryEntity, error) {
 	var modelCategory model.Category
-	err = c.db.Where("id = ?", id).Preload("User").First(&modelCategory).Error
+	err = c.db.WithContext(ctx).Where("id = ?", id).Preload("User").First(&modelCategory).Error
 	if err != nil {
 		code = "[REPOSITORY] GetByIDCategories - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
@@ -157,6 +206,7 @@ func (c *categoryRepository) GetCategoryByID(ctx context.Context, id int64) (*en
 		ID: modelCategory.ID,
 		Title: modelCategory.Title,
 		Slug: modelCategory.Slug,
+		Version: modelCategory.Version,
 		User: entity.UserEntity{
 			ID: modelCategory.User.ID,
 			Name: modelCategory.User.Name,
@@ -165,6 +215,125 @@ func (c *categoryRepository) GetCategoryByID(ctx context.Context, id int64) (*en
 	}, nil
 }
 
+// GetCategoryBySlug implements CategoryRepository.
+func (c *categoryRepository) GetCategoryBySlug(ctx context.Context, slug string) (*entity.CategoryEntity, error) {
+	var modelCategory model.Category
+	err = c.db.WithContext(ctx).Where("slug = ?", slug).Preload("User").First(&modelCategory).Error
+	if err != nil {
+		code = "[REPOSITORY] GetCategoryBySlug - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return &entity.CategoryEntity{
+		ID:    modelCategory.ID,
+		Title: modelCategory.Title,
+		Slug:  modelCategory.Slug,
+		User: entity.UserEntity{
+			ID:    modelCategory.User.ID,
+			Name:  modelCategory.User.Name,
+			Email: modelCategory.User.Email,
+		},
+	}, nil
+}
+
+// GetTrashedCategories implements CategoryRepository.
+func (c *categoryRepository) GetTrashedCategories(ctx context.Context) ([]entity.CategoryEntity, error) {
+	var modelCategories []model.Category
+	err = c.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Preload("User").Find(&modelCategories).Error
+	if err != nil {
+		code = "[REPOSITORY] GetTrashedCategories - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := []entity.CategoryEntity{}
+	for _, val := range modelCategories {
+		deletedAt := val.DeletedAt.Time
+		resp = append(resp, entity.CategoryEntity{
+			ID:    val.ID,
+			Title: val.Title,
+			Slug:  val.Slug,
+			User: entity.UserEntity{
+				ID:    val.User.ID,
+				Name:  val.User.Name,
+				Email: val.User.Email,
+			},
+			DeletedAt: &deletedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// RestoreCategory implements CategoryRepository.
+func (c *categoryRepository) RestoreCategory(ctx context.Context, id int64) error {
+	result := c.db.WithContext(ctx).Unscoped().Model(&model.Category{}).Where("id = ? AND deleted_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		code = "[REPOSITORY] RestoreCategory - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] RestoreCategory - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeCategory implements CategoryRepository.
+func (c *categoryRepository) PurgeCategory(ctx context.Context, id int64) error {
+	// contents reference categories with ON DELETE CASCADE, so even trashed
+	// contents would be wiped together with the category
+	var count int64
+	err = c.db.WithContext(ctx).Table("contents").Where("category_id = ?", id).Count(&count).Error
+	if err != nil {
+		code = "[REPOSITORY] PurgeCategory - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("cannot purge a category that has associated contents")
+	}
+
+	result := c.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Category{})
+	if result.Error != nil {
+		code = "[REPOSITORY] PurgeCategory - 2"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] PurgeCategory - 3"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeTrashedCategories implements CategoryRepository.
+func (c *categoryRepository) PurgeTrashedCategories(ctx context.Context, before time.Time) (int64, error) {
+	result := c.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM contents WHERE contents.category_id = categories.id)").
+		Delete(&model.Category{})
+	if result.Error != nil {
+		code = "[REPOSITORY] PurgeTrashedCategories - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
 func NewCategoryRepository(db *gorm.DB) CategoryRepository {
 	return &categoryRepository{
 		db: db,
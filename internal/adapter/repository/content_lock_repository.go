@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/domain/model"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ContentLockRepository interface {
+	GetLock(ctx context.Context, contentID int64) (*entity.ContentLockEntity, error)
+	GetLocks(ctx context.Context, contentIDs []int64) (map[int64]entity.ContentLockEntity, error)
+	AcquireLock(ctx context.Context, req entity.ContentLockEntity, takeOver bool) (*entity.ContentLockEntity, error)
+	RefreshLock(ctx context.Context, req entity.ContentLockEntity) (*entity.ContentLockEntity, error)
+	ReleaseLock(ctx context.Context, contentID int64, userID int64) error
+	ForceReleaseLock(ctx context.Context, contentID int64) error
+}
+
+type contentLockRepository struct {
+	db *gorm.DB
+}
+
+// GetLock implements ContentLockRepository. It returns nil when nobody holds
+// an unexpired lock on the content.
+func (c *contentLockRepository) GetLock(ctx context.Context, contentID int64) (*entity.ContentLockEntity, error) {
+	var modelLock model.ContentLock
+	err = c.db.WithContext(ctx).Preload("User").Where("content_id = ? AND expires_at > ?", contentID, time.Now()).First(&modelLock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		code = "[REPOSITORY] GetLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toContentLockEntity(modelLock)
+	return &resp, nil
+}
+
+// GetLocks implements ContentLockRepository.
+func (c *contentLockRepository) GetLocks(ctx context.Context, contentIDs []int64) (map[int64]entity.ContentLockEntity, error) {
+	resps := map[int64]entity.ContentLockEntity{}
+	if len(contentIDs) == 0 {
+		return resps, nil
+	}
+
+	var modelLocks []model.ContentLock
+	err = c.db.WithContext(ctx).Preload("User").Where("content_id IN ? AND expires_at > ?", contentIDs, time.Now()).Find(&modelLocks).Error
+	if err != nil {
+		code = "[REPOSITORY] GetLocks - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	for _, val := range modelLocks {
+		resps[val.ContentID] = toContentLockEntity(val)
+	}
+
+	return resps, nil
+}
+
+// AcquireLock implements ContentLockRepository. The lock is granted when it
+// is free, expired, already held by the same user or takeOver is set;
+// otherwise it is left untouched. Either way the lock as it stands
+// afterwards is returned so the caller can tell who holds it.
+func (c *contentLockRepository) AcquireLock(ctx context.Context, req entity.ContentLockEntity, takeOver bool) (*entity.ContentLockEntity, error) {
+	err = c.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").Where("id = ?", req.ContentID).First(&model.Content{}).Error; err != nil {
+			return err
+		}
+
+		var modelLock model.ContentLock
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("content_id = ?", req.ContentID).First(&modelLock).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// a concurrent acquire may insert first; that holder wins
+			return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.ContentLock{
+				ContentID:   req.ContentID,
+				UserID:      req.User.ID,
+				AcquiredAt:  req.AcquiredAt,
+				HeartbeatAt: req.AcquiredAt,
+				ExpiresAt:   req.ExpiresAt,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		acquiredAt := modelLock.AcquiredAt
+		if modelLock.UserID != req.User.ID {
+			if modelLock.ExpiresAt.After(req.AcquiredAt) && !takeOver {
+				return nil
+			}
+			acquiredAt = req.AcquiredAt
+		}
+
+		return tx.Model(&model.ContentLock{}).Where("content_id = ?", req.ContentID).Updates(map[string]interface{}{
+			"user_id":      req.User.ID,
+			"acquired_at":  acquiredAt,
+			"heartbeat_at": req.AcquiredAt,
+			"expires_at":   req.ExpiresAt,
+		}).Error
+	})
+	if err != nil {
+		code = "[REPOSITORY] AcquireLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return c.loadLock(ctx, req.ContentID)
+}
+
+// RefreshLock implements ContentLockRepository. It fails with
+// gorm.ErrRecordNotFound when the user no longer holds the lock.
+func (c *contentLockRepository) RefreshLock(ctx context.Context, req entity.ContentLockEntity) (*entity.ContentLockEntity, error) {
+	result := c.db.WithContext(ctx).Model(&model.ContentLock{}).
+		Where("content_id = ? AND user_id = ?", req.ContentID, req.User.ID).
+		Updates(map[string]interface{}{
+			"heartbeat_at": req.HeartbeatAt,
+			"expires_at":   req.ExpiresAt,
+		})
+	if result.Error != nil {
+		code = "[REPOSITORY] RefreshLock - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return c.loadLock(ctx, req.ContentID)
+}
+
+// ReleaseLock implements ContentLockRepository.
+func (c *contentLockRepository) ReleaseLock(ctx context.Context, contentID int64, userID int64) error {
+	err = c.db.WithContext(ctx).Where("content_id = ? AND user_id = ?", contentID, userID).Delete(&model.ContentLock{}).Error
+	if err != nil {
+		code = "[REPOSITORY] ReleaseLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// ForceReleaseLock implements ContentLockRepository.
+func (c *contentLockRepository) ForceReleaseLock(ctx context.Context, contentID int64) error {
+	err = c.db.WithContext(ctx).Where("content_id = ?", contentID).Delete(&model.ContentLock{}).Error
+	if err != nil {
+		code = "[REPOSITORY] ForceReleaseLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+func (c *contentLockRepository) loadLock(ctx context.Context, contentID int64) (*entity.ContentLockEntity, error) {
+	var modelLock model.ContentLock
+	err = c.db.WithContext(ctx).Preload("User").Where("content_id = ?", contentID).First(&modelLock).Error
+	if err != nil {
+		code = "[REPOSITORY] loadLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toContentLockEntity(modelLock)
+	return &resp, nil
+}
+
+func toContentLockEntity(modelLock model.ContentLock) entity.ContentLockEntity {
+	return entity.ContentLockEntity{
+		ContentID: modelLock.ContentID,
+		User: entity.UserEntity{
+			ID:   modelLock.User.ID,
+			Name: modelLock.User.Name,
+		},
+		AcquiredAt:  modelLock.AcquiredAt,
+		HeartbeatAt: modelLock.HeartbeatAt,
+		ExpiresAt:   modelLock.ExpiresAt,
+	}
+}
+
+func NewContentLockRepository(db *gorm.DB) ContentLockRepository {
+	return &contentLockRepository{
+		db: db,
+	}
+}
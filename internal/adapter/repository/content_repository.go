@@ -2,13 +2,17 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/domain/model"
+	"gonews/lib/revision"
 	"math"
 	"strings"
+	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -16,9 +20,23 @@ import (
 type ContentRepository interface {
 	GetContents(ctx context.Context, query entity.QueryString) ([]entity.ContentEntity, int64, int64, error)
 	GetContentById(ctx context.Context, id int64) (*entity.ContentEntity, error)
-	CreateContent(ctx context.Context, req entity.ContentEntity) error
+	GetContentsByIDs(ctx context.Context, ids []int64) ([]entity.ContentEntity, error)
+	CreateContent(ctx context.Context, req entity.ContentEntity) (int64, error)
 	UpdateContent(ctx context.Context, req entity.ContentEntity) error
-	DeleteContent(ctx context.Context, id int64) error
+	DeleteContent(ctx context.Context, id int64, version int64) error
+	RestoreContent(ctx context.Context, id int64) error
+	PurgeContent(ctx context.Context, id int64) error
+	PurgeTrashedContents(ctx context.Context, before time.Time) (int64, error)
+	GetContentIDs(ctx context.Context, query entity.QueryString, limit int) ([]int64, error)
+	BulkUpdateContents(ctx context.Context, req entity.ContentBulkEntity) (*entity.ContentBulkResultEntity, error)
+
+	CountPublishedContents(ctx context.Context) (int64, error)
+	GetPublishedContents(ctx context.Context, query entity.SitemapQueryEntity) ([]entity.ContentEntity, error)
+	GetContentsWithoutBody(ctx context.Context, afterID int64, limit int) ([]entity.ContentEntity, error)
+	UpdateContentBody(ctx context.Context, req entity.ContentEntity) error
+
+	CreateAttachment(ctx context.Context, req entity.ContentAttachmentEntity) (*entity.ContentAttachmentEntity, error)
+	DeleteAttachment(ctx context.Context, contentID int64, id int64) error
 }
 
 type contentRepository struct {
@@ -26,76 +44,351 @@ type contentRepository struct {
 }
 
 // CreateContent implements ContentRepository.
-func (c *contentRepository) CreateContent(ctx context.Context, req entity.ContentEntity) error {
+func (c *contentRepository) CreateContent(ctx context.Context, req entity.ContentEntity) (int64, error) {
+	slug, err := uniqueSlug(dbFor(ctx, c.db), req.Slug)
+	if err != nil {
+		code = "[REPOSITORY] CreateContent - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
 	tags := strings.Join(req.Tags, ",")
 	modelContent := model.Content{
-		ID:          req.ID,
-		Title:       req.Title,
-		Excerpt:     req.Excerpt,
-		Description: req.Description,
-		Image:       req.Image,
-		Tags:        tags,
-		Status:      req.Status,
-		CategoryID:  req.CategoryID,
-		CreatedByID: req.CreatedById,
+		ID:                req.ID,
+		Title:             req.Title,
+		Slug:              slug,
+		Excerpt:           req.Excerpt,
+		Description:       req.Description,
+		DescriptionSource: req.DescriptionSource,
+		BodyFormat:        req.BodyFormat,
+		Body:              encodeBlocks(req.Blocks),
+		PlainText:         req.PlainText,
+		TableOfContents:   encodeTableOfContents(req.TableOfContents),
+		Image:             req.Image,
+		Tags:              tags,
+		Status:            req.Status,
+		CategoryID:        req.CategoryID,
+		CreatedByID:       nullableUserID(req.CreatedById),
+		Version:           1,
 	}
 
-	err = c.db.Create(&modelContent).Error
+	err = dbFor(ctx, c.db).Create(&modelContent).Error
 	if err != nil {
 		code = "[REPOSITORY] CreateContent - 1"
-		log.Errorw(code, err)
-		return err
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
 	}
 
-	return nil
+	return modelContent.ID, nil
 }
 
 // DeleteContent implements ContentRepository.
-func (c *contentRepository) DeleteContent(ctx context.Context, id int64) error {
-	err = c.db.Where("id = ?", id).Delete(&model.Content{}).Error
+func (c *contentRepository) DeleteContent(ctx context.Context, id int64, version int64) error {
+	err = dbFor(ctx, c.db).Transaction(func(tx *gorm.DB) error {
+		if err := checkContentVersion(tx, id, version); err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", id).Delete(&model.Content{}).Error
+	})
 	if err != nil {
 		code = "[REPOSITORY] DeleteContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// checkContentVersion locks the content row for the rest of the transaction
+// and fails with revision.ErrRevisionConflict when it no longer has the
+// expected version.
+func checkContentVersion(tx *gorm.DB, id int64, version int64) error {
+	var modelContent model.Content
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "version").Where("id = ?", id).First(&modelContent).Error
+	if err != nil {
 		return err
 	}
 
+	if version != revision.Any && modelContent.Version != version {
+		return revision.ErrRevisionConflict
+	}
+
+	return nil
+}
+
+// RestoreContent implements ContentRepository.
+func (c *contentRepository) RestoreContent(ctx context.Context, id int64) error {
+	result := dbFor(ctx, c.db).Unscoped().Model(&model.Content{}).Where("id = ? AND deleted_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"version":    gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		code = "[REPOSITORY] RestoreContent - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] RestoreContent - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// PurgeContent implements ContentRepository.
+func (c *contentRepository) PurgeContent(ctx context.Context, id int64) error {
+	result := dbFor(ctx, c.db).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&model.Content{})
+	if result.Error != nil {
+		code = "[REPOSITORY] PurgeContent - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] PurgeContent - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
 	return nil
 }
 
+// PurgeTrashedContents implements ContentRepository.
+func (c *contentRepository) PurgeTrashedContents(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFor(ctx, c.db).Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&model.Content{})
+	if result.Error != nil {
+		code = "[REPOSITORY] PurgeTrashedContents - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetContentIDs implements ContentRepository.
+func (c *contentRepository) GetContentIDs(ctx context.Context, query entity.QueryString, limit int) ([]int64, error) {
+	var ids []int64
+	err = filterContents(dbFor(ctx, c.db).Model(&model.Content{}), query).Order("id ASC").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		code = "[REPOSITORY] GetContentIDs - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// errBulkDryRun rolls back the bulk transaction once a dry run has
+// collected its results.
+var errBulkDryRun = errors.New("bulk dry run")
+
+// BulkUpdateContents implements ContentRepository.
+func (c *contentRepository) BulkUpdateContents(ctx context.Context, req entity.ContentBulkEntity) (*entity.ContentBulkResultEntity, error) {
+	result := &entity.ContentBulkResultEntity{
+		Action: req.Action,
+		DryRun: req.DryRun,
+		Items:  []entity.ContentBulkItemEntity{},
+	}
+
+	err = dbFor(ctx, c.db).Transaction(func(tx *gorm.DB) error {
+		if req.Action == entity.BulkActionSetCategory {
+			if err := tx.Where("id = ?", req.CategoryID).First(&model.Category{}).Error; err != nil {
+				return err
+			}
+		}
+
+		for i, id := range req.IDs {
+			item := entity.ContentBulkItemEntity{ID: id, Status: entity.BulkItemSucceeded}
+
+			var modelContent model.Content
+			err := tx.Select("id", "title", "tags").Where("id = ?", id).First(&modelContent).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				item.Status = entity.BulkItemNotFound
+				result.Items = append(result.Items, item)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			item.Title = modelContent.Title
+
+			var locked int64
+			err = tx.Model(&model.ContentLock{}).Where("content_id = ? AND user_id <> ? AND expires_at > ?", id, req.UserID, time.Now()).Count(&locked).Error
+			if err != nil {
+				return err
+			}
+			if locked > 0 {
+				item.Status = entity.BulkItemLocked
+				item.Error = "content is being edited by another user"
+				result.Items = append(result.Items, item)
+				continue
+			}
+
+			// a savepoint per item keeps one failing row from aborting
+			// the whole transaction
+			savepoint := fmt.Sprintf("bulk_item_%d", i)
+			if err := tx.SavePoint(savepoint).Error; err != nil {
+				return err
+			}
+
+			if err := applyBulkAction(tx, req, modelContent); err != nil {
+				if rbErr := tx.RollbackTo(savepoint).Error; rbErr != nil {
+					return rbErr
+				}
+				item.Status = entity.BulkItemFailed
+				item.Error = err.Error()
+			}
+
+			result.Items = append(result.Items, item)
+		}
+
+		if req.DryRun {
+			return errBulkDryRun
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkDryRun) {
+		code = "[REPOSITORY] BulkUpdateContents - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	result.Applied = !req.DryRun
+	return result, nil
+}
+
+func applyBulkAction(tx *gorm.DB, req entity.ContentBulkEntity, modelContent model.Content) error {
+	// every change bumps the version so editors holding the old one get a
+	// conflict instead of overwriting the bulk change
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1")}
+
+	switch req.Action {
+	case entity.BulkActionPublish:
+		updates["status"] = "PUBLISH"
+	case entity.BulkActionUnpublish:
+		updates["status"] = "DRAFT"
+	case entity.BulkActionSetCategory:
+		updates["category_id"] = req.CategoryID
+	case entity.BulkActionAddTags, entity.BulkActionRemoveTags, entity.BulkActionSetTags:
+		updates["tags"] = strings.Join(bulkTags(req.Action, strings.Split(modelContent.Tags, ","), req.Tags), ",")
+	case entity.BulkActionDelete:
+		return tx.Where("id = ?", modelContent.ID).Delete(&model.Content{}).Error
+	default:
+		return fmt.Errorf("unknown bulk action %q", req.Action)
+	}
+
+	return tx.Model(&model.Content{}).Where("id = ?", modelContent.ID).Updates(updates).Error
+}
+
+// bulkTags returns the tag list of a content after a tag action, keeping
+// the existing order and dropping duplicates and blanks.
+func bulkTags(action string, current []string, tags []string) []string {
+	remove := map[string]bool{}
+	if action == entity.BulkActionRemoveTags {
+		for _, tag := range tags {
+			remove[strings.ToLower(strings.TrimSpace(tag))] = true
+		}
+	}
+
+	source := append(current, tags...)
+	switch action {
+	case entity.BulkActionSetTags:
+		source = tags
+	case entity.BulkActionRemoveTags:
+		source = current
+	}
+
+	seen := map[string]bool{}
+	resps := []string{}
+	for _, tag := range source {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] || remove[key] {
+			continue
+		}
+		seen[key] = true
+		resps = append(resps, tag)
+	}
+
+	return resps
+}
+
 // GetContentById implements ContentRepository.
 func (c *contentRepository) GetContentById(ctx context.Context, id int64) (*entity.ContentEntity, error) {
 	var modelContent model.Content
-	err = c.db.Where("id = ?", id).Preload(clause.Associations).First(&modelContent).Error
+	err = dbFor(ctx, c.db).Where("id = ?", id).Preload(clause.Associations).First(&modelContent).Error
 	if err != nil {
 		code = "[REPOSITORY] GetContents - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
+	resp := toContentEntity(ctx, modelContent)
+	return &resp, nil
+}
+
+// GetContentsByIDs implements ContentRepository. IDs that are not found,
+// or trashed, are left out.
+func (c *contentRepository) GetContentsByIDs(ctx context.Context, ids []int64) ([]entity.ContentEntity, error) {
+	if len(ids) == 0 {
+		return []entity.ContentEntity{}, nil
+	}
+
+	var modelContents []model.Content
+	err = dbFor(ctx, c.db).Where("id IN ?", ids).Preload(clause.Associations).Find(&modelContents).Error
+	if err != nil {
+		code = "[REPOSITORY] GetContentsByIDs - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.ContentEntity{}
+	for _, val := range modelContents {
+		resps = append(resps, toContentEntity(ctx, val))
+	}
+
+	return resps, nil
+}
+
+// toContentEntity maps a content loaded with its associations.
+func toContentEntity(ctx context.Context, modelContent model.Content) entity.ContentEntity {
 	tags := strings.Split(modelContent.Tags, ",")
-	resp := entity.ContentEntity{
-		Title:       modelContent.Title,
-		ID:          modelContent.ID,
-		Excerpt:     modelContent.Excerpt,
-		Description: modelContent.Description,
-		Image:       modelContent.Image,
-		Tags:        tags,
-		Status:      modelContent.Description,
-		CategoryID:  modelContent.CategoryID,
-		CreatedById: modelContent.CreatedByID,
-		CreatedAt:   modelContent.CreatedAt,
+	return entity.ContentEntity{
+		Title:             modelContent.Title,
+		Slug:              modelContent.Slug,
+		ID:                modelContent.ID,
+		Excerpt:           modelContent.Excerpt,
+		Description:       modelContent.Description,
+		DescriptionSource: modelContent.DescriptionSource,
+		BodyFormat:        modelContent.BodyFormat,
+		Blocks:            decodeBlocks(ctx, modelContent.Body),
+		PlainText:         modelContent.PlainText,
+		TableOfContents:   decodeTableOfContents(ctx, modelContent.TableOfContents),
+		Image:             modelContent.Image,
+		Tags:              tags,
+		Status:            modelContent.Status,
+		CategoryID:        modelContent.CategoryID,
+		CreatedById:       userIDOf(modelContent.CreatedByID),
+		CreatedAt:         modelContent.CreatedAt,
+		UpdatedAt:         updatedAt(modelContent.CreatedAt, modelContent.UpdatedAt),
+		Version:           modelContent.Version,
 		Category: entity.CategoryEntity{
-			ID:    modelContent.CategoryID,
-			Title: modelContent.Title,
-			Slug:  modelContent.Description,
+			ID:        modelContent.CategoryID,
+			Title:     modelContent.Category.Title,
+			Slug:      modelContent.Category.Slug,
+			UpdatedAt: updatedAt(modelContent.Category.CreatedAt, modelContent.Category.UpdatedAt),
 		},
 		User: entity.UserEntity{
-			ID:   modelContent.User.ID,
-			Name: modelContent.User.Name,
+			ID:        modelContent.User.ID,
+			Name:      modelContent.User.Name,
+			UpdatedAt: updatedAt(modelContent.User.CreatedAt, modelContent.User.UpdatedAt),
 		},
+		Attachments: toAttachmentEntities(modelContent.Attachments),
 	}
-
-	return &resp, nil
 }
 
 // GetContents implements ContentRepository.
@@ -105,23 +398,12 @@ func (c *contentRepository) GetContents(ctx context.Context, query entity.QueryS
 
 	order := fmt.Sprintf("%s %s", query.OrderBy, query.OrderType)
 	offset := (query.Page - 1) * query.Limit
-	status := ""
-	if query.Status != "" {
-		status = query.Status
-	}
-
-	sqlMain := c.db.Preload(clause.Associations).
-		Where("title ilike ? OR excerpt ilike ? OR description ilike ?", "%"+query.Search+"%", "%"+query.Search+"%", "%"+query.Search+"%").
-		Where("status LIKE ?", "%"+status+"%")
-
-	if query.CategoryID > 0 {
-		sqlMain = sqlMain.Where("category_id =?", query.CategoryID)
-	}
+	sqlMain := filterContents(dbFor(ctx, c.db).Preload(clause.Associations), query)
 
 	err = sqlMain.Model(&modelContents).Count(&countData).Error
 	if err != nil {
 		code = "[REPOSITORY] GetContents - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, 0, 0, err
 	}
 
@@ -134,7 +416,7 @@ func (c *contentRepository) GetContents(ctx context.Context, query entity.QueryS
 		Find(&modelContents).Error
 	if err != nil {
 		code = "[REPOSITORY] GetContents - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, 0, 0, err
 	}
 
@@ -142,25 +424,37 @@ func (c *contentRepository) GetContents(ctx context.Context, query entity.QueryS
 	for _, val := range modelContents {
 		tags := strings.Split(val.Tags, ",")
 		resp := entity.ContentEntity{
-			ID:          val.ID,
-			Title:       val.Title,
-			Excerpt:     val.Excerpt,
-			Description: val.Description,
-			Image:       val.Image,
-			Tags:        tags,
-			Status:      val.Status,
-			CategoryID:  val.CategoryID,
-			CreatedById: val.CreatedByID,
-			CreatedAt:   val.CreatedAt,
+			ID:                val.ID,
+			Title:             val.Title,
+			Slug:              val.Slug,
+			Excerpt:           val.Excerpt,
+			Description:       val.Description,
+			DescriptionSource: val.DescriptionSource,
+			BodyFormat:        val.BodyFormat,
+			Blocks:            decodeBlocks(ctx, val.Body),
+			PlainText:         val.PlainText,
+			TableOfContents:   decodeTableOfContents(ctx, val.TableOfContents),
+			Image:             val.Image,
+			Tags:              tags,
+			Status:            val.Status,
+			CategoryID:        val.CategoryID,
+			CreatedById:       userIDOf(val.CreatedByID),
+			CreatedAt:         val.CreatedAt,
+			UpdatedAt:         updatedAt(val.CreatedAt, val.UpdatedAt),
+			Version:           val.Version,
+			DeletedAt:         deletedAt(val.DeletedAt),
 			Category: entity.CategoryEntity{
-				ID:    val.Category.ID,
-				Title: val.Category.Title,
-				Slug:  val.Category.Slug,
+				ID:        val.Category.ID,
+				Title:     val.Category.Title,
+				Slug:      val.Category.Slug,
+				UpdatedAt: updatedAt(val.Category.CreatedAt, val.Category.UpdatedAt),
 			},
 			User: entity.UserEntity{
-				ID:   val.User.ID,
-				Name: val.User.Name,
+				ID:        val.User.ID,
+				Name:      val.User.Name,
+				UpdatedAt: updatedAt(val.User.CreatedAt, val.User.UpdatedAt),
 			},
+			Attachments: toAttachmentEntities(val.Attachments),
 		}
 
 		resps = append(resps, resp)
@@ -172,27 +466,351 @@ func (c *contentRepository) GetContents(ctx context.Context, query entity.QueryS
 func (c *contentRepository) UpdateContent(ctx context.Context, req entity.ContentEntity) error {
 	tags := strings.Join(req.Tags, ",")
 	modelContent := model.Content{
-		ID:          req.ID,
-		Title:       req.Title,
-		Excerpt:     req.Excerpt,
-		Description: req.Description,
-		Image:       req.Image,
-		Tags:        tags,
-		Status:      req.Status,
-		CategoryID:  req.CategoryID,
-		CreatedByID: req.CreatedById,
+		ID:                req.ID,
+		Title:             req.Title,
+		Excerpt:           req.Excerpt,
+		Description:       req.Description,
+		DescriptionSource: req.DescriptionSource,
+		BodyFormat:        req.BodyFormat,
+		Body:              encodeBlocks(req.Blocks),
+		PlainText:         req.PlainText,
+		TableOfContents:   encodeTableOfContents(req.TableOfContents),
+		Image:             req.Image,
+		Tags:              tags,
+		Status:            req.Status,
+		CategoryID:        req.CategoryID,
+		CreatedByID:       nullableUserID(req.CreatedById),
 	}
 
-	err = c.db.Where("id = ?", req.ID).Updates(&modelContent).Error
+	err = dbFor(ctx, c.db).Transaction(func(tx *gorm.DB) error {
+		if err := checkContentVersion(tx, req.ID, req.Version); err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", req.ID).Updates(&modelContent).Error; err != nil {
+			return err
+		}
+
+		// Updates skips zero fields, so switching body formats has to clear
+		// the columns of the previous format explicitly.
+		return tx.Model(&model.Content{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
+			"description_source": req.DescriptionSource,
+			"body":               modelContent.Body,
+			"table_of_contents":  modelContent.TableOfContents,
+			"version":            gorm.Expr("version + 1"),
+		}).Error
+	})
 	if err != nil {
 		code = "[REPOSITORY] UpdateContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// CountPublishedContents implements ContentRepository.
+func (c *contentRepository) CountPublishedContents(ctx context.Context) (int64, error) {
+	var countData int64
+	err = dbFor(ctx, c.db).Model(&model.Content{}).Where("status = ?", "PUBLISH").Count(&countData).Error
+	if err != nil {
+		code = "[REPOSITORY] CountPublishedContents - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	return countData, nil
+}
+
+// GetPublishedContents implements ContentRepository.
+func (c *contentRepository) GetPublishedContents(ctx context.Context, query entity.SitemapQueryEntity) ([]entity.ContentEntity, error) {
+	var modelContents []model.Content
+
+	sqlMain := dbFor(ctx, c.db).Select("id", "title", "slug", "category_id", "created_at", "updated_at").
+		Preload("Category").
+		Where("status = ?", "PUBLISH")
+
+	if !query.Since.IsZero() {
+		sqlMain = sqlMain.Where("created_at >= ?", query.Since)
+	}
+
+	err = sqlMain.Order("id ASC").Offset(query.Offset).Limit(query.Limit).Find(&modelContents).Error
+	if err != nil {
+		code = "[REPOSITORY] GetPublishedContents - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.ContentEntity{}
+	for _, val := range modelContents {
+		resps = append(resps, entity.ContentEntity{
+			ID:         val.ID,
+			Title:      val.Title,
+			Slug:       val.Slug,
+			CategoryID: val.CategoryID,
+			CreatedAt:  val.CreatedAt,
+			UpdatedAt:  updatedAt(val.CreatedAt, val.UpdatedAt),
+			Category: entity.CategoryEntity{
+				ID:    val.Category.ID,
+				Title: val.Category.Title,
+				Slug:  val.Category.Slug,
+			},
+		})
+	}
+
+	return resps, nil
+}
+
+// GetContentsWithoutBody implements ContentRepository.
+func (c *contentRepository) GetContentsWithoutBody(ctx context.Context, afterID int64, limit int) ([]entity.ContentEntity, error) {
+	var modelContents []model.Content
+	err = dbFor(ctx, c.db).Where("body_format = ? AND body IS NULL AND id > ?", entity.BodyFormatHTML, afterID).Order("id ASC").Limit(limit).Find(&modelContents).Error
+	if err != nil {
+		code = "[REPOSITORY] GetContentsWithoutBody - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.ContentEntity{}
+	for _, val := range modelContents {
+		resps = append(resps, entity.ContentEntity{
+			ID:          val.ID,
+			Title:       val.Title,
+			Description: val.Description,
+		})
+	}
+
+	return resps, nil
+}
+
+// UpdateContentBody implements ContentRepository.
+func (c *contentRepository) UpdateContentBody(ctx context.Context, req entity.ContentEntity) error {
+	err = dbFor(ctx, c.db).Model(&model.Content{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
+		"description":       req.Description,
+		"body_format":       entity.BodyFormatBlocks,
+		"body":              encodeBlocks(req.Blocks),
+		"plain_text":        req.PlainText,
+		"table_of_contents": encodeTableOfContents(req.TableOfContents),
+		"version":           gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		code = "[REPOSITORY] UpdateContentBody - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
 	return nil
 }
 
+// CreateAttachment implements ContentRepository.
+func (c *contentRepository) CreateAttachment(ctx context.Context, req entity.ContentAttachmentEntity) (*entity.ContentAttachmentEntity, error) {
+	modelAttachment := model.ContentAttachment{
+		ContentID:  req.ContentID,
+		Type:       req.Type,
+		Url:        req.Url,
+		FileName:   req.FileName,
+		MimeType:   req.Media.MimeType,
+		Size:       req.Size,
+		DurationMs: req.Media.DurationMs,
+		VideoCodec: req.Media.VideoCodec,
+		AudioCodec: req.Media.AudioCodec,
+		Width:      req.Media.Width,
+		Height:     req.Media.Height,
+		SampleRate: req.Media.SampleRate,
+		Channels:   req.Media.Channels,
+		PosterUrl:  req.PosterUrl,
+	}
+
+	err = dbFor(ctx, c.db).Create(&modelAttachment).Error
+	if err != nil {
+		code = "[REPOSITORY] CreateAttachment - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toAttachmentEntities([]model.ContentAttachment{modelAttachment})[0]
+	return &resp, nil
+}
+
+// DeleteAttachment implements ContentRepository.
+func (c *contentRepository) DeleteAttachment(ctx context.Context, contentID int64, id int64) error {
+	result := dbFor(ctx, c.db).Where("id = ? AND content_id = ?", id, contentID).Delete(&model.ContentAttachment{})
+	if result.Error != nil {
+		code = "[REPOSITORY] DeleteAttachment - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] DeleteAttachment - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// uniqueSlug appends a counter to slug until no other content uses it.
+func uniqueSlug(db *gorm.DB, slug string) (string, error) {
+	candidate := slug
+	for i := 2; ; i++ {
+		var countSlug int64
+		// trashed contents keep their slug until they are purged
+		err := db.Unscoped().Model(&model.Content{}).Where("slug = ?", candidate).Count(&countSlug).Error
+		if err != nil {
+			return "", err
+		}
+
+		if countSlug == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+}
+
+// filterContents applies the search, status, category, tag and trash
+// filters of a QueryString. Paging and ordering are left to the caller.
+func filterContents(db *gorm.DB, query entity.QueryString) *gorm.DB {
+	sqlMain := db.
+		Where("title ilike ? OR excerpt ilike ? OR description ilike ?", "%"+query.Search+"%", "%"+query.Search+"%", "%"+query.Search+"%").
+		Where("status LIKE ?", "%"+query.Status+"%")
+
+	if query.Trashed {
+		sqlMain = sqlMain.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if query.CategoryID > 0 {
+		sqlMain = sqlMain.Where("category_id =?", query.CategoryID)
+	}
+
+	if query.Tag != "" {
+		sqlMain = sqlMain.Where("(',' || tags || ',') ILIKE ?", "%,"+query.Tag+",%")
+	}
+
+	return sqlMain
+}
+
+// updatedAt falls back to the creation time for rows that were never
+// updated.
+func updatedAt(createdAt time.Time, updatedAt *time.Time) time.Time {
+	if updatedAt == nil {
+		return createdAt
+	}
+
+	return *updatedAt
+}
+
+func deletedAt(value gorm.DeletedAt) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+
+	return &value.Time
+}
+
+func encodeBlocks(blocks []entity.ContentBlock) *string {
+	if len(blocks) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(blocks)
+	if err != nil {
+		return nil
+	}
+
+	body := string(data)
+	return &body
+}
+
+func decodeBlocks(ctx context.Context, body *string) []entity.ContentBlock {
+	if body == nil {
+		return nil
+	}
+
+	var blocks []entity.ContentBlock
+	if err := json.Unmarshal([]byte(*body), &blocks); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("[REPOSITORY] decodeBlocks - 1")
+		return nil
+	}
+
+	return blocks
+}
+
+func encodeTableOfContents(toc []entity.TocEntry) *string {
+	if len(toc) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(toc)
+	if err != nil {
+		return nil
+	}
+
+	body := string(data)
+	return &body
+}
+
+func decodeTableOfContents(ctx context.Context, body *string) []entity.TocEntry {
+	if body == nil {
+		return nil
+	}
+
+	var toc []entity.TocEntry
+	if err := json.Unmarshal([]byte(*body), &toc); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("[REPOSITORY] decodeTableOfContents - 1")
+		return nil
+	}
+
+	return toc
+}
+
+func toAttachmentEntities(attachments []model.ContentAttachment) []entity.ContentAttachmentEntity {
+	resps := []entity.ContentAttachmentEntity{}
+	for _, val := range attachments {
+		resps = append(resps, entity.ContentAttachmentEntity{
+			ID:        val.ID,
+			ContentID: val.ContentID,
+			Type:      val.Type,
+			Url:       val.Url,
+			FileName:  val.FileName,
+			Size:      val.Size,
+			Media: entity.MediaEntity{
+				MimeType:   val.MimeType,
+				DurationMs: val.DurationMs,
+				VideoCodec: val.VideoCodec,
+				AudioCodec: val.AudioCodec,
+				Width:      val.Width,
+				Height:     val.Height,
+				SampleRate: val.SampleRate,
+				Channels:   val.Channels,
+			},
+			PosterUrl: val.PosterUrl,
+			CreatedAt: val.CreatedAt,
+		})
+	}
+
+	return resps
+}
+
 func NewContentRepository(db *gorm.DB) ContentRepository {
 	return &contentRepository{db: db}
 }
+
+// nullableUserID stores a missing user (id 0) as NULL, so the row does not
+// trip the users foreign key.
+func nullableUserID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+
+	return &id
+}
+
+// userIDOf reads a nullable user id back, with 0 for none.
+func userIDOf(id *int64) int64 {
+	if id == nil {
+		return 0
+	}
+
+	return *id
+}
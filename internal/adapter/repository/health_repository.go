@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+}
+
+type healthRepository struct {
+	db *gorm.DB
+}
+
+// Ping implements HealthRepository.
+func (h *healthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
+
+func NewHealthRepository(db *gorm.DB) HealthRepository {
+	return &healthRepository{db: db}
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/domain/model"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type ImageRepository interface {
+	CreateImage(ctx context.Context, req entity.ImageEntity) (*entity.ImageEntity, error)
+	GetImageByID(ctx context.Context, id int64) (*entity.ImageEntity, error)
+	UpdateFocalPoint(ctx context.Context, req entity.ImageEntity) error
+}
+
+type imageRepository struct {
+	db *gorm.DB
+}
+
+// CreateImage implements ImageRepository.
+func (i *imageRepository) CreateImage(ctx context.Context, req entity.ImageEntity) (*entity.ImageEntity, error) {
+	modelImage := model.Image{
+		Url:         req.Url,
+		FocalX:      req.FocalX,
+		FocalY:      req.FocalY,
+		CreatedByID: req.CreatedById,
+	}
+
+	err = i.db.WithContext(ctx).Create(&modelImage).Error
+	if err != nil {
+		code = "[REPOSITORY] CreateImage - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	req.ID = modelImage.ID
+	req.UpdatedAt = modelImage.CreatedAt
+	return &req, nil
+}
+
+// GetImageByID implements ImageRepository.
+func (i *imageRepository) GetImageByID(ctx context.Context, id int64) (*entity.ImageEntity, error) {
+	var modelImage model.Image
+	err = i.db.WithContext(ctx).Where("id = ?", id).First(&modelImage).Error
+	if err != nil {
+		code = "[REPOSITORY] GetImageByID - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	updatedAt := modelImage.CreatedAt
+	if modelImage.UpdatedAt != nil {
+		updatedAt = *modelImage.UpdatedAt
+	}
+
+	return &entity.ImageEntity{
+		ID:          modelImage.ID,
+		Url:         modelImage.Url,
+		FocalX:      modelImage.FocalX,
+		FocalY:      modelImage.FocalY,
+		CreatedById: modelImage.CreatedByID,
+		UpdatedAt:   updatedAt,
+	}, nil
+}
+
+// UpdateFocalPoint implements ImageRepository.
+func (i *imageRepository) UpdateFocalPoint(ctx context.Context, req entity.ImageEntity) error {
+	result := i.db.WithContext(ctx).Model(&model.Image{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
+		"focal_x": req.FocalX,
+		"focal_y": req.FocalY,
+	})
+	if result.Error != nil {
+		code = "[REPOSITORY] UpdateFocalPoint - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] UpdateFocalPoint - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func NewImageRepository(db *gorm.DB) ImageRepository {
+	return &imageRepository{db: db}
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/domain/model"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImportRepository interface {
+	CreateJob(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error)
+	GetJob(ctx context.Context, id int64) (*entity.ImportJobEntity, error)
+	GetJobs(ctx context.Context, limit int) ([]entity.ImportJobEntity, error)
+	ClaimNextJob(ctx context.Context) (*entity.ImportJobEntity, error)
+	RequeueRunningJobs(ctx context.Context, staleBefore time.Time) (int64, error)
+	UpdateJob(ctx context.Context, req entity.ImportJobEntity) error
+	TouchJob(ctx context.Context, id int64) error
+
+	GetMapping(ctx context.Context, sourceType string, sourceKey string) (*entity.ImportMappingEntity, error)
+	SaveMapping(ctx context.Context, req entity.ImportMappingEntity) error
+	GetUserID(ctx context.Context, email string) (int64, error)
+	FindOrCreateUser(ctx context.Context, req entity.UserEntity) (int64, bool, error)
+	FindOrCreateCategory(ctx context.Context, req entity.CategoryEntity) (int64, bool, error)
+	CreateContent(ctx context.Context, req entity.ContentEntity, mapping entity.ImportMappingEntity, fromPath string) (int64, bool, error)
+	GetRedirect(ctx context.Context, fromPath string) (*entity.RedirectEntity, *entity.ContentEntity, error)
+}
+
+type importRepository struct {
+	db *gorm.DB
+}
+
+// CreateJob implements ImportRepository.
+func (i *importRepository) CreateJob(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error) {
+	modelJob := model.ImportJob{
+		Format:    req.Format,
+		FileName:  req.FileName,
+		FilePath:  req.FilePath,
+		SourceUrl: req.SourceUrl,
+		Status:    req.Status,
+		Stats:     "{}",
+		Errors:    "[]",
+		StartedAt: req.StartedAt,
+	}
+	if modelJob.Status == entity.ImportStatusRunning {
+		modelJob.HeartbeatAt = req.StartedAt
+	}
+	if modelJob.Status == "" {
+		modelJob.Status = entity.ImportStatusQueued
+	}
+	if req.CreatedById != 0 {
+		modelJob.CreatedByID = &req.CreatedById
+	}
+
+	err = i.db.WithContext(ctx).Create(&modelJob).Error
+	if err != nil {
+		code = "[REPOSITORY] CreateJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toImportJobEntity(ctx, modelJob)
+	return &resp, nil
+}
+
+// GetJob implements ImportRepository.
+func (i *importRepository) GetJob(ctx context.Context, id int64) (*entity.ImportJobEntity, error) {
+	var modelJob model.ImportJob
+	err = i.db.WithContext(ctx).Where("id = ?", id).First(&modelJob).Error
+	if err != nil {
+		code = "[REPOSITORY] GetJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toImportJobEntity(ctx, modelJob)
+	return &resp, nil
+}
+
+// GetJobs implements ImportRepository.
+func (i *importRepository) GetJobs(ctx context.Context, limit int) ([]entity.ImportJobEntity, error) {
+	var modelJobs []model.ImportJob
+	err = i.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&modelJobs).Error
+	if err != nil {
+		code = "[REPOSITORY] GetJobs - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.ImportJobEntity{}
+	for _, val := range modelJobs {
+		resps = append(resps, toImportJobEntity(ctx, val))
+	}
+
+	return resps, nil
+}
+
+// ClaimNextJob implements ImportRepository. It marks the oldest queued job
+// as running and returns it, or returns nil when the queue is empty.
+// SKIP LOCKED lets several instances share the queue.
+func (i *importRepository) ClaimNextJob(ctx context.Context) (*entity.ImportJobEntity, error) {
+	var modelJob model.ImportJob
+	err = i.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", entity.ImportStatusQueued).
+			Order("id ASC").
+			First(&modelJob).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		modelJob.Status = entity.ImportStatusRunning
+		modelJob.StartedAt = &now
+		return tx.Model(&model.ImportJob{}).Where("id = ?", modelJob.ID).Updates(map[string]interface{}{
+			"status":       modelJob.Status,
+			"started_at":   now,
+			"heartbeat_at": now,
+		}).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		code = "[REPOSITORY] ClaimNextJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toImportJobEntity(ctx, modelJob)
+	return &resp, nil
+}
+
+// RequeueRunningJobs implements ImportRepository. Only running jobs whose
+// heartbeat is older than staleBefore are queued again.
+func (i *importRepository) RequeueRunningJobs(ctx context.Context, staleBefore time.Time) (int64, error) {
+	result := i.db.WithContext(ctx).Model(&model.ImportJob{}).
+		Where("status = ? AND (heartbeat_at IS NULL OR heartbeat_at < ?)", entity.ImportStatusRunning, staleBefore).
+		Update("status", entity.ImportStatusQueued)
+	if result.Error != nil {
+		code = "[REPOSITORY] RequeueRunningJobs - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// UpdateJob implements ImportRepository.
+func (i *importRepository) UpdateJob(ctx context.Context, req entity.ImportJobEntity) error {
+	stats, _ := json.Marshal(req.Stats)
+	errs, _ := json.Marshal(req.Errors)
+	if req.Errors == nil {
+		errs = []byte("[]")
+	}
+
+	err = i.db.WithContext(ctx).Model(&model.ImportJob{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
+		"status":      req.Status,
+		"stats":       string(stats),
+		"errors":      string(errs),
+		"error":       req.Error,
+		"finished_at": req.FinishedAt,
+	}).Error
+	if err != nil {
+		code = "[REPOSITORY] UpdateJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// TouchJob implements ImportRepository.
+func (i *importRepository) TouchJob(ctx context.Context, id int64) error {
+	err = i.db.WithContext(ctx).Model(&model.ImportJob{}).Where("id = ? AND status = ?", id, entity.ImportStatusRunning).Update("heartbeat_at", time.Now()).Error
+	if err != nil {
+		code = "[REPOSITORY] TouchJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// GetMapping implements ImportRepository. It returns nil when the source
+// has not been imported yet.
+func (i *importRepository) GetMapping(ctx context.Context, sourceType string, sourceKey string) (*entity.ImportMappingEntity, error) {
+	var modelMapping model.ImportMapping
+	err = i.db.WithContext(ctx).Where("source_type = ? AND source_key = ?", sourceType, sourceKey).First(&modelMapping).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		code = "[REPOSITORY] GetMapping - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := entity.ImportMappingEntity{
+		SourceType: modelMapping.SourceType,
+		SourceKey:  modelMapping.SourceKey,
+		TargetUrl:  modelMapping.TargetUrl,
+	}
+	if modelMapping.TargetID != nil {
+		resp.TargetID = *modelMapping.TargetID
+	}
+
+	return &resp, nil
+}
+
+// SaveMapping implements ImportRepository.
+func (i *importRepository) SaveMapping(ctx context.Context, req entity.ImportMappingEntity) error {
+	err = saveMapping(i.db.WithContext(ctx), req)
+	if err != nil {
+		code = "[REPOSITORY] SaveMapping - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// GetUserID implements ImportRepository. It returns 0 when no user has
+// email.
+func (i *importRepository) GetUserID(ctx context.Context, email string) (int64, error) {
+	var modelUser model.User
+	err = i.db.WithContext(ctx).Select("id").Where("LOWER(email) = LOWER(?)", email).First(&modelUser).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		code = "[REPOSITORY] GetUserID - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	return modelUser.ID, nil
+}
+
+// FindOrCreateUser implements ImportRepository. Users are matched on their
+// email; the bool reports whether one was created.
+func (i *importRepository) FindOrCreateUser(ctx context.Context, req entity.UserEntity) (int64, bool, error) {
+	var modelUser model.User
+	err = i.db.WithContext(ctx).Where("LOWER(email) = LOWER(?)", req.Email).First(&modelUser).Error
+	if err == nil {
+		return modelUser.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		code = "[REPOSITORY] FindOrCreateUser - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, false, err
+	}
+
+	modelUser = model.User{
+		Name:     req.Name,
+		Email:    strings.ToLower(req.Email),
+		Password: req.Password,
+		Role:     req.Role,
+	}
+	err = i.db.WithContext(ctx).Create(&modelUser).Error
+	if err != nil {
+		code = "[REPOSITORY] FindOrCreateUser - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, false, err
+	}
+
+	return modelUser.ID, true, nil
+}
+
+// FindOrCreateCategory implements ImportRepository. Categories are matched
+// on their slug, including trashed ones, which are restored.
+func (i *importRepository) FindOrCreateCategory(ctx context.Context, req entity.CategoryEntity) (int64, bool, error) {
+	var modelCategory model.Category
+	err = i.db.WithContext(ctx).Unscoped().Where("slug = ?", req.Slug).First(&modelCategory).Error
+	if err == nil {
+		if modelCategory.DeletedAt.Valid {
+			err = i.db.WithContext(ctx).Unscoped().Model(&model.Category{}).Where("id = ?", modelCategory.ID).Update("deleted_at", nil).Error
+		}
+		if err != nil {
+			code = "[REPOSITORY] FindOrCreateCategory - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return 0, false, err
+		}
+
+		return modelCategory.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		code = "[REPOSITORY] FindOrCreateCategory - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, false, err
+	}
+
+	modelCategory = model.Category{
+		Title:       req.Title,
+		Slug:        req.Slug,
+		CreatedByID: nullableUserID(req.User.ID),
+		Version:     1,
+	}
+	err = i.db.WithContext(ctx).Create(&modelCategory).Error
+	if err != nil {
+		code = "[REPOSITORY] FindOrCreateCategory - 3"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, false, err
+	}
+
+	return modelCategory.ID, true, nil
+}
+
+// CreateContent implements ImportRepository. The content, its import
+// mapping and the redirect from its old path are written together so an
+// interrupted run never leaves a content that a re-run would duplicate.
+// The bool reports whether a redirect was created.
+func (i *importRepository) CreateContent(ctx context.Context, req entity.ContentEntity, mapping entity.ImportMappingEntity, fromPath string) (int64, bool, error) {
+	var modelContent model.Content
+	redirected := false
+	err = dbFor(ctx, i.db).Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, req.Slug)
+		if err != nil {
+			return err
+		}
+
+		modelContent = model.Content{
+			Title:             req.Title,
+			Slug:              slug,
+			Excerpt:           req.Excerpt,
+			Description:       req.Description,
+			DescriptionSource: req.DescriptionSource,
+			BodyFormat:        req.BodyFormat,
+			PlainText:         req.PlainText,
+			TableOfContents:   encodeTableOfContents(req.TableOfContents),
+			Image:             req.Image,
+			Tags:              strings.Join(req.Tags, ","),
+			Status:            req.Status,
+			CategoryID:        req.CategoryID,
+			CreatedByID:       nullableUserID(req.CreatedById),
+			CreatedAt:         req.CreatedAt,
+			Version:           1,
+		}
+		if err := tx.Create(&modelContent).Error; err != nil {
+			return err
+		}
+
+		mapping.TargetID = modelContent.ID
+		if err := saveMapping(tx, mapping); err != nil {
+			return err
+		}
+
+		if fromPath == "" {
+			return nil
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Redirect{
+			FromPath:  fromPath,
+			ContentID: modelContent.ID,
+		})
+		redirected = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		code = "[REPOSITORY] CreateContent (import) - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, false, err
+	}
+
+	return modelContent.ID, redirected, nil
+}
+
+// GetRedirect implements ImportRepository.
+func (i *importRepository) GetRedirect(ctx context.Context, fromPath string) (*entity.RedirectEntity, *entity.ContentEntity, error) {
+	var modelRedirect model.Redirect
+	err = i.db.WithContext(ctx).Where("from_path = ?", fromPath).Preload("Content.Category").First(&modelRedirect).Error
+	if err != nil {
+		code = "[REPOSITORY] GetRedirect - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, nil, err
+	}
+
+	// the preload is scoped, so a trashed content comes back empty
+	if modelRedirect.Content.ID == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	return &entity.RedirectEntity{
+			FromPath:  modelRedirect.FromPath,
+			ContentID: modelRedirect.ContentID,
+		}, &entity.ContentEntity{
+			ID:     modelRedirect.Content.ID,
+			Slug:   modelRedirect.Content.Slug,
+			Status: modelRedirect.Content.Status,
+			Category: entity.CategoryEntity{
+				ID:   modelRedirect.Content.Category.ID,
+				Slug: modelRedirect.Content.Category.Slug,
+			},
+		}, nil
+}
+
+func saveMapping(db *gorm.DB, req entity.ImportMappingEntity) error {
+	modelMapping := model.ImportMapping{
+		SourceType: req.SourceType,
+		SourceKey:  req.SourceKey,
+		TargetUrl:  req.TargetUrl,
+	}
+	if req.TargetID != 0 {
+		modelMapping.TargetID = &req.TargetID
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&modelMapping).Error
+}
+
+func toImportJobEntity(ctx context.Context, modelJob model.ImportJob) entity.ImportJobEntity {
+	resp := entity.ImportJobEntity{
+		ID:         modelJob.ID,
+		Format:     modelJob.Format,
+		FileName:   modelJob.FileName,
+		FilePath:   modelJob.FilePath,
+		SourceUrl:  modelJob.SourceUrl,
+		Status:     modelJob.Status,
+		Errors:     []string{},
+		Error:      modelJob.Error,
+		CreatedAt:  modelJob.CreatedAt,
+		StartedAt:  modelJob.StartedAt,
+		FinishedAt: modelJob.FinishedAt,
+	}
+	if modelJob.CreatedByID != nil {
+		resp.CreatedById = *modelJob.CreatedByID
+	}
+
+	if modelJob.Stats != "" {
+		if err := json.Unmarshal([]byte(modelJob.Stats), &resp.Stats); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("[REPOSITORY] toImportJobEntity - 1")
+		}
+	}
+	if modelJob.Errors != "" {
+		if err := json.Unmarshal([]byte(modelJob.Errors), &resp.Errors); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("[REPOSITORY] toImportJobEntity - 2")
+		}
+	}
+
+	return resp
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &importRepository{
+		db: db,
+	}
+}
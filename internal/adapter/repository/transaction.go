@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// Transactor runs work in a single database transaction. Repository methods
+// called with the context it passes on take part in it.
+type Transactor interface {
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	db *gorm.DB
+}
+
+// Transaction implements Transactor. It commits when fn returns nil and
+// rolls back otherwise.
+func (t *transactor) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbFor(ctx, t.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFor returns the transaction ctx runs in, or db outside of one.
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
@@ -4,14 +4,21 @@ import (
 	"context"
 	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/domain/model"
+	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
 	UpdatePassword(ctx context.Context, newPass string, id int64) error
 	GetUserByID(ctx context.Context, id int64) (*entity.UserEntity, error)
+
+	CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error)
+	GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error)
+	GetUsers(ctx context.Context) ([]entity.UserEntity, error)
+	UpdateRole(ctx context.Context, role string, id int64) error
+	DeactivateUser(ctx context.Context, id int64) error
 }
 
 type userRepository struct {
@@ -21,32 +28,131 @@ type userRepository struct {
 // GetUserByID implements UserRepository.
 func (u *userRepository) GetUserByID(ctx context.Context, id int64) (*entity.UserEntity, error) {
 	var modelUser model.User
-	err := u.db.Where("id = ?", id).First(&modelUser).Error
+	err := u.db.WithContext(ctx).Where("id = ?", id).First(&modelUser).Error
 	if err != nil {
 		code := "[REPOSITORY] GetUserByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
 	return &entity.UserEntity{
-		ID:       id,
-		Name:     modelUser.Name,
-		Email:    modelUser.Email,
+		ID:            id,
+		Name:          modelUser.Name,
+		Email:         modelUser.Email,
+		Role:          modelUser.Role,
+		DeactivatedAt: modelUser.DeactivatedAt,
 	}, nil
 }
 
 // UpdatePassword implements UserRepository.
 func (u *userRepository) UpdatePassword(ctx context.Context, newPass string, id int64) error {
-	err = u.db.Model(&model.User{}).Where("id = ?", id).Update("password", newPass).Error 
+	err = u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("password", newPass).Error 
 	if err != nil {
 		code := "[REPOSITORY] UpdatePassword - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 	
 	return nil
 }
 
+// CreateUser implements UserRepository.
+func (u *userRepository) CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error) {
+	modelUser := model.User{
+		Name:     req.Name,
+		Email:    req.Email,
+		Password: req.Password,
+		Role:     req.Role,
+	}
+
+	err = u.db.WithContext(ctx).Create(&modelUser).Error
+	if err != nil {
+		code := "[REPOSITORY] CreateUser - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return toUserEntity(modelUser), nil
+}
+
+// GetUserByEmail implements UserRepository. Emails are matched without
+// regard to case.
+func (u *userRepository) GetUserByEmail(ctx context.Context, email string) (*entity.UserEntity, error) {
+	var modelUser model.User
+	err = u.db.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&modelUser).Error
+	if err != nil {
+		code := "[REPOSITORY] GetUserByEmail - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return toUserEntity(modelUser), nil
+}
+
+// GetUsers implements UserRepository.
+func (u *userRepository) GetUsers(ctx context.Context) ([]entity.UserEntity, error) {
+	var modelUsers []model.User
+	err = u.db.WithContext(ctx).Order("id ASC").Find(&modelUsers).Error
+	if err != nil {
+		code := "[REPOSITORY] GetUsers - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.UserEntity{}
+	for _, val := range modelUsers {
+		resps = append(resps, *toUserEntity(val))
+	}
+
+	return resps, nil
+}
+
+// UpdateRole implements UserRepository.
+func (u *userRepository) UpdateRole(ctx context.Context, role string, id int64) error {
+	result := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		code := "[REPOSITORY] UpdateRole - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeactivateUser implements UserRepository. Deactivating a user twice
+// keeps the first date.
+func (u *userRepository) DeactivateUser(ctx context.Context, id int64) error {
+	result := u.db.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).
+		Update("deactivated_at", gorm.Expr("COALESCE(deactivated_at, ?)", time.Now()))
+	if result.Error != nil {
+		code := "[REPOSITORY] DeactivateUser - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func toUserEntity(modelUser model.User) *entity.UserEntity {
+	return &entity.UserEntity{
+		ID:            modelUser.ID,
+		Name:          modelUser.Name,
+		Email:         modelUser.Email,
+		Password:      modelUser.Password,
+		Role:          modelUser.Role,
+		DeactivatedAt: modelUser.DeactivatedAt,
+		CreatedAt:     modelUser.CreatedAt,
+	}
+}
+
 func NewUserRepository(db *gorm.DB) UserRepository {
 	return &userRepository{db: db}
 }
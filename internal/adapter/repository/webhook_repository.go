@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/domain/model"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository interface {
+	GetWebhooks(ctx context.Context) ([]entity.WebhookEntity, error)
+	GetWebhookByID(ctx context.Context, id int64) (*entity.WebhookEntity, error)
+	CreateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error)
+	UpdateWebhook(ctx context.Context, req entity.WebhookEntity) error
+	DeleteWebhook(ctx context.Context, id int64) error
+	RecordWebhookFailure(ctx context.Context, id int64) (int, error)
+	ResetWebhookFailures(ctx context.Context, id int64) error
+	DisableWebhook(ctx context.Context, id int64, reason string) error
+
+	EnqueueDeliveries(ctx context.Context, event string, payload string) (int64, error)
+	GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]entity.WebhookDeliveryEntity, error)
+	GetDelivery(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error)
+	CreateRedelivery(ctx context.Context, req entity.WebhookDeliveryEntity) (*entity.WebhookDeliveryEntity, error)
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDeliveryEntity, error)
+	UpdateDelivery(ctx context.Context, req entity.WebhookDeliveryEntity) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// GetWebhooks implements WebhookRepository.
+func (w *webhookRepository) GetWebhooks(ctx context.Context) ([]entity.WebhookEntity, error) {
+	var modelWebhooks []model.Webhook
+	err = dbFor(ctx, w.db).Order("id ASC").Find(&modelWebhooks).Error
+	if err != nil {
+		code = "[REPOSITORY] GetWebhooks - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.WebhookEntity{}
+	for _, val := range modelWebhooks {
+		resps = append(resps, toWebhookEntity(ctx, val))
+	}
+
+	return resps, nil
+}
+
+// GetWebhookByID implements WebhookRepository.
+func (w *webhookRepository) GetWebhookByID(ctx context.Context, id int64) (*entity.WebhookEntity, error) {
+	var modelWebhook model.Webhook
+	err = dbFor(ctx, w.db).Where("id = ?", id).First(&modelWebhook).Error
+	if err != nil {
+		code = "[REPOSITORY] GetWebhookByID - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toWebhookEntity(ctx, modelWebhook)
+	return &resp, nil
+}
+
+// CreateWebhook implements WebhookRepository.
+func (w *webhookRepository) CreateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error) {
+	modelWebhook := model.Webhook{
+		Url:         req.Url,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Events:      encodeWebhookEvents(req.Events),
+		Active:      req.Active,
+	}
+	if req.CreatedById != 0 {
+		modelWebhook.CreatedByID = &req.CreatedById
+	}
+
+	err = dbFor(ctx, w.db).Create(&modelWebhook).Error
+	if err != nil {
+		code = "[REPOSITORY] CreateWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toWebhookEntity(ctx, modelWebhook)
+	return &resp, nil
+}
+
+// UpdateWebhook implements WebhookRepository. Turning a webhook back on
+// clears why it was disabled and its failure count.
+func (w *webhookRepository) UpdateWebhook(ctx context.Context, req entity.WebhookEntity) error {
+	updates := map[string]interface{}{
+		"url":         req.Url,
+		"description": req.Description,
+		"events":      encodeWebhookEvents(req.Events),
+		"active":      req.Active,
+		"updated_at":  time.Now(),
+	}
+	if req.Active {
+		updates["failure_count"] = 0
+		updates["disabled_at"] = nil
+		updates["disabled_reason"] = ""
+	}
+
+	result := dbFor(ctx, w.db).Model(&model.Webhook{}).Where("id = ?", req.ID).Updates(updates)
+	if result.Error != nil {
+		code = "[REPOSITORY] UpdateWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] UpdateWebhook - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteWebhook implements WebhookRepository. Its deliveries go with it.
+func (w *webhookRepository) DeleteWebhook(ctx context.Context, id int64) error {
+	result := dbFor(ctx, w.db).Where("id = ?", id).Delete(&model.Webhook{})
+	if result.Error != nil {
+		code = "[REPOSITORY] DeleteWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		code = "[REPOSITORY] DeleteWebhook - 2"
+		zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RecordWebhookFailure implements WebhookRepository. It counts a delivery
+// that failed every attempt and returns the failures in a row so far.
+func (w *webhookRepository) RecordWebhookFailure(ctx context.Context, id int64) (int, error) {
+	var failures int
+	err = dbFor(ctx, w.db).Raw("UPDATE webhooks SET failure_count = failure_count + 1 WHERE id = ? RETURNING failure_count", id).Scan(&failures).Error
+	if err != nil {
+		code = "[REPOSITORY] RecordWebhookFailure - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+// ResetWebhookFailures implements WebhookRepository.
+func (w *webhookRepository) ResetWebhookFailures(ctx context.Context, id int64) error {
+	err = dbFor(ctx, w.db).Model(&model.Webhook{}).Where("id = ? AND failure_count > 0", id).Update("failure_count", 0).Error
+	if err != nil {
+		code = "[REPOSITORY] ResetWebhookFailures - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// DisableWebhook implements WebhookRepository.
+func (w *webhookRepository) DisableWebhook(ctx context.Context, id int64, reason string) error {
+	err = dbFor(ctx, w.db).Model(&model.Webhook{}).Where("id = ? AND active", id).Updates(map[string]interface{}{
+		"active":          false,
+		"disabled_at":     time.Now(),
+		"disabled_reason": reason,
+	}).Error
+	if err != nil {
+		code = "[REPOSITORY] DisableWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries implements WebhookRepository. It queues payload for
+// every active webhook subscribed to event and returns how many were.
+func (w *webhookRepository) EnqueueDeliveries(ctx context.Context, event string, payload string) (int64, error) {
+	result := dbFor(ctx, w.db).Exec(`INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at)
+		SELECT id, ?, ?, ?, CURRENT_TIMESTAMP FROM webhooks
+		WHERE active AND (events = '[]'::jsonb OR events @> jsonb_build_array(?::text))`,
+		event, payload, entity.WebhookDeliveryPending, event)
+	if result.Error != nil {
+		code = "[REPOSITORY] EnqueueDeliveries - 1"
+		zerolog.Ctx(ctx).Error().Err(result.Error).Msg(code)
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetDeliveries implements WebhookRepository, newest first.
+func (w *webhookRepository) GetDeliveries(ctx context.Context, webhookID int64, limit int) ([]entity.WebhookDeliveryEntity, error) {
+	var modelDeliveries []model.WebhookDelivery
+	err = dbFor(ctx, w.db).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&modelDeliveries).Error
+	if err != nil {
+		code = "[REPOSITORY] GetDeliveries - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.WebhookDeliveryEntity{}
+	for _, val := range modelDeliveries {
+		resps = append(resps, toWebhookDeliveryEntity(ctx, val))
+	}
+
+	return resps, nil
+}
+
+// GetDelivery implements WebhookRepository.
+func (w *webhookRepository) GetDelivery(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error) {
+	var modelDelivery model.WebhookDelivery
+	err = dbFor(ctx, w.db).Where("id = ? AND webhook_id = ?", id, webhookID).First(&modelDelivery).Error
+	if err != nil {
+		code = "[REPOSITORY] GetDelivery - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toWebhookDeliveryEntity(ctx, modelDelivery)
+	return &resp, nil
+}
+
+// CreateRedelivery implements WebhookRepository. The copy is queued now,
+// with the payload of the original.
+func (w *webhookRepository) CreateRedelivery(ctx context.Context, req entity.WebhookDeliveryEntity) (*entity.WebhookDeliveryEntity, error) {
+	modelDelivery := model.WebhookDelivery{
+		WebhookID:      req.WebhookID,
+		Event:          req.Event,
+		Payload:        req.Payload,
+		Status:         entity.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOfID: &req.ID,
+	}
+
+	err = dbFor(ctx, w.db).Omit("Webhook").Create(&modelDelivery).Error
+	if err != nil {
+		code = "[REPOSITORY] CreateRedelivery - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resp := toWebhookDeliveryEntity(ctx, modelDelivery)
+	return &resp, nil
+}
+
+// ClaimDueDeliveries implements WebhookRepository. It takes up to limit
+// pending deliveries of active webhooks that are due, counts the attempt and
+// pushes their next attempt lease into the future, so a worker that dies
+// mid-delivery leaves them to be retried instead of lost. SKIP LOCKED lets
+// several instances share the queue.
+func (w *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDeliveryEntity, error) {
+	var modelDeliveries []model.WebhookDelivery
+	err = dbFor(ctx, w.db).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		err := tx.Raw(`SELECT d.id FROM webhook_deliveries d JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = ? AND d.next_attempt_at <= CURRENT_TIMESTAMP AND w.active
+			ORDER BY d.next_attempt_at ASC LIMIT ? FOR UPDATE OF d SKIP LOCKED`,
+			entity.WebhookDeliveryPending, limit).Scan(&ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": time.Now().Add(lease),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Preload("Webhook").Where("id IN ?", ids).Order("next_attempt_at ASC").Find(&modelDeliveries).Error
+	})
+	if err != nil {
+		code = "[REPOSITORY] ClaimDueDeliveries - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.WebhookDeliveryEntity{}
+	for _, val := range modelDeliveries {
+		resps = append(resps, toWebhookDeliveryEntity(ctx, val))
+	}
+
+	return resps, nil
+}
+
+// UpdateDelivery implements WebhookRepository. It records the outcome of
+// an attempt.
+func (w *webhookRepository) UpdateDelivery(ctx context.Context, req entity.WebhookDeliveryEntity) error {
+	err = dbFor(ctx, w.db).Model(&model.WebhookDelivery{}).Where("id = ?", req.ID).Updates(map[string]interface{}{
+		"status":          req.Status,
+		"next_attempt_at": req.NextAttemptAt,
+		"last_attempt_at": req.LastAttemptAt,
+		"response_status": req.ResponseStatus,
+		"response_body":   req.ResponseBody,
+		"error":           req.Error,
+		"duration_ms":     req.DurationMs,
+	}).Error
+	if err != nil {
+		code = "[REPOSITORY] UpdateDelivery - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+func encodeWebhookEvents(events []string) string {
+	if len(events) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(events)
+	return string(data)
+}
+
+func toWebhookEntity(ctx context.Context, modelWebhook model.Webhook) entity.WebhookEntity {
+	resp := entity.WebhookEntity{
+		ID:             modelWebhook.ID,
+		Url:            modelWebhook.Url,
+		Secret:         modelWebhook.Secret,
+		Description:    modelWebhook.Description,
+		Events:         []string{},
+		Active:         modelWebhook.Active,
+		FailureCount:   modelWebhook.FailureCount,
+		DisabledAt:     modelWebhook.DisabledAt,
+		DisabledReason: modelWebhook.DisabledReason,
+		CreatedAt:      modelWebhook.CreatedAt,
+		UpdatedAt:      modelWebhook.UpdatedAt,
+	}
+	if modelWebhook.CreatedByID != nil {
+		resp.CreatedById = *modelWebhook.CreatedByID
+	}
+
+	if modelWebhook.Events != "" {
+		if err := json.Unmarshal([]byte(modelWebhook.Events), &resp.Events); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("[REPOSITORY] toWebhookEntity - 1")
+		}
+	}
+
+	return resp
+}
+
+func toWebhookDeliveryEntity(ctx context.Context, modelDelivery model.WebhookDelivery) entity.WebhookDeliveryEntity {
+	resp := entity.WebhookDeliveryEntity{
+		ID:             modelDelivery.ID,
+		WebhookID:      modelDelivery.WebhookID,
+		Event:          modelDelivery.Event,
+		Payload:        modelDelivery.Payload,
+		Status:         modelDelivery.Status,
+		Attempts:       modelDelivery.Attempts,
+		NextAttemptAt:  modelDelivery.NextAttemptAt,
+		LastAttemptAt:  modelDelivery.LastAttemptAt,
+		ResponseStatus: modelDelivery.ResponseStatus,
+		ResponseBody:   modelDelivery.ResponseBody,
+		Error:          modelDelivery.Error,
+		DurationMs:     modelDelivery.DurationMs,
+		CreatedAt:      modelDelivery.CreatedAt,
+	}
+	if modelDelivery.RedeliveryOfID != nil {
+		resp.RedeliveryOfID = *modelDelivery.RedeliveryOfID
+	}
+	if modelDelivery.Webhook.ID != 0 {
+		resp.Webhook = toWebhookEntity(ctx, modelDelivery.Webhook)
+	}
+
+	return resp
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
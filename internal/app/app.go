@@ -3,24 +3,35 @@ package app
 import (
 	"context"
 	"gonews/config"
+	"gonews/database"
+	"gonews/internal/adapter/filestore"
 	"gonews/internal/adapter/handler"
 	"gonews/internal/adapter/imagekit"
 	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
 	"gonews/internal/core/service"
 	"gonews/lib/auth"
+	"gonews/lib/httpcache"
+	"gonews/lib/logger"
+	"gonews/lib/metrics"
 	"gonews/lib/middleware"
+	"gonews/lib/migrate"
 	"gonews/lib/pagination"
-	"log"
+	"gonews/lib/ratelimit"
+	"gonews/lib/tracing"
+	"gonews/lib/tus"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
 )
 
 func RunServer() {
@@ -28,49 +39,189 @@ func RunServer() {
 	db, err := cfg.ConnectionPostgres()
 
 	if err != nil {
-		log.Fatalf("Error connecting to database: %v", err)
+		log.Fatal().Err(err).Msg("Error connecting to database")
 		return
 	}
 
 	err = os.MkdirAll("./temp/content", 0755)
 	if err != nil {
-		log.Fatalf("Error connecting to temp dir: %v", err)
+		log.Fatal().Err(err).Msg("Error connecting to temp dir")
 		return
 	}
 
 	// Imagekit
 	ikAdapter := imagekit.NewImageKitAdapter(cfg)
+
+	if cfg.Upload.TusDir == "" {
+		cfg.Upload.TusDir = "./temp/uploads"
+	}
+	tusStore, err := filestore.NewTusStore(cfg.Upload.TusDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating upload dir")
+		return
+	}
+
+	// an empty key would let anyone sign image urls
+	if cfg.Image.SigningKey == "" {
+		log.Fatal().Msg("IMAGE_SIGNING_KEY is not set")
+		return
+	}
+
+	// sharing the key with access tokens would let one pass for the other
+	if cfg.Preview.SigningKey == "" || cfg.Preview.SigningKey == cfg.App.JwtSecretKey {
+		log.Fatal().Msg("PREVIEW_SIGNING_KEY must be set and differ from JWT_SECRET_KEY")
+		return
+	}
+
+	// a proxy header read from anyone would let clients pick their own IP
+	if cfg.App.ProxyHeader != "" && strings.TrimSpace(cfg.App.TrustedProxies) == "" {
+		log.Fatal().Msg("APP_PROXY_HEADER needs APP_TRUSTED_PROXIES")
+		return
+	}
+
+	if cfg.Image.CacheDir == "" {
+		cfg.Image.CacheDir = "./temp/images"
+	}
+	imageCache, err := filestore.NewImageCache(cfg.Image.CacheDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating image cache dir")
+		return
+	}
+
+	if cfg.Import.Dir == "" {
+		cfg.Import.Dir = "./temp/imports"
+	}
+	err = os.MkdirAll(cfg.Import.Dir, 0755)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating import dir")
+		return
+	}
 	
 
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error getting database connection")
+		return
+	}
+	migrations, err := migrate.Load(database.Migrations, database.MigrationsDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error loading migrations")
+		return
+	}
+	migrator := migrate.New(sqlDB, migrations)
+	metrics.RegisterDBStats(sqlDB)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error setting up tracing")
+		return
+	}
+	if err = db.DB.Use(tracing.GormPlugin{}); err != nil {
+		log.Fatal().Err(err).Msg("Error registering tracing plugin")
+		return
+	}
+
+	var redisClient *redis.Client
+	if cfg.Redis.Url != "" {
+		redisClient, err = newRedisClient(cfg.Redis.Url)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Error creating redis client")
+			return
+		}
+		defer redisClient.Close()
+	}
+
+	rateLimitStore, err := newRateLimitStore(cfg, sqlDB, redisClient)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating rate limit store")
+		return
+	}
+	readCache, err := newCache(cfg, redisClient)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cache")
+		return
+	}
+
+	cachePurger, closeCachePurger, err := newCachePurger(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cdn purger")
+		return
+	}
+
+	loginLimit := rateLimitPolicy("login", cfg.RateLimit.Login)
+	publicLimit := rateLimitPolicy("public", cfg.RateLimit.Public)
+	adminLimit := rateLimitPolicy("admin", cfg.RateLimit.Admin)
+
 	jwt := auth.NewJwt(cfg)
-	middlewareAuth := middleware.NewMiddleware(cfg)
 	_ = pagination.NewPagination()
 
 	//repository
 	authRepo := repository.NewAuthRepository(db.DB)
 	categoryRepo := repository.NewCategoryRepository(db.DB)
 	contentRepo := repository.NewContentRepository(db.DB)
+	contentLockRepo := repository.NewContentLockRepository(db.DB)
 	userRepo := repository.NewUserRepository(db.DB)
+	imageRepo := repository.NewImageRepository(db.DB)
+	importRepo := repository.NewImportRepository(db.DB)
+	backupRepo := repository.NewBackupRepository(db.DB)
+	healthRepo := repository.NewHealthRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+
+	middlewareAuth := middleware.NewMiddleware(cfg, userRepo)
+	adminOnly := middlewareAuth.RequireRole(entity.RoleAdmin)
 
 
 	//service
 	authService := service.NewAuthService(authRepo, cfg, jwt)
-	categoryService := service.NewCategoryService(categoryRepo)
-	contentService := service.NewContentService(contentRepo, cfg, ikAdapter)
+	webhookService := service.NewWebhookService(webhookRepo, cfg)
+	categoryService := service.NewCategoryService(categoryRepo, readCache, cachePurger, time.Duration(cfg.Cache.TtlSeconds)*time.Second)
+	contentService := service.NewContentService(contentRepo, repository.NewTransactor(db.DB), cfg, ikAdapter, readCache, cachePurger, webhookService)
+	contentLockService := service.NewContentLockService(contentLockRepo, cfg)
 	userService := service.NewUserService(userRepo)
+	uploadService := service.NewUploadService(tusStore, cfg, ikAdapter)
+	imageService := service.NewImageService(imageRepo, imageCache, cfg, ikAdapter)
+	feedService := service.NewFeedService(contentService, categoryService, cfg)
+	sitemapService := service.NewSitemapService(contentRepo, categoryRepo, cfg)
+	importService := service.NewImportService(importRepo, contentRepo, repository.NewTransactor(db.DB), cfg, ikAdapter, readCache, cachePurger, webhookService)
+	backupService := service.NewBackupService(backupRepo)
+	healthService := service.NewHealthService(healthRepo, ikAdapter, migrator, cfg)
 
 	//handler
 	authHandler := handler.NewAuthHandler(authService)
 	categoryHandler := handler.NewCategoryHandler(categoryService)
-	contentHandler := handler.NewContentHandler(contentService)
+	contentHandler := handler.NewContentHandler(contentService, uploadService, imageService, contentLockService)
 	userHandler := handler.NewUserHandler(userService)
+	uploadHandler := handler.NewUploadHandler(uploadService, cfg)
+	imageHandler := handler.NewImageHandler(imageService)
+	feedHandler := handler.NewFeedHandler(feedService, cfg)
+	sitemapHandler := handler.NewSitemapHandler(sitemapService, cfg)
+	importHandler := handler.NewImportHandler(importService, uploadService, cfg)
+	backupHandler := handler.NewBackupHandler(backupService)
+	healthHandler := handler.NewHealthHandler(healthService)
+	metricsHandler := handler.NewMetricsHandler(cfg)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
 
-	app := fiber.New()
-	app.Use(cors.New())
-	app.Use(recover.New())
-	app.Use(logger.New(logger.Config{
-		Format: "[${time}] %{ip} %{status} - %{latency} %{method} %{path}\n",
+	limit, chunkLimit := bodyLimits(cfg)
+	fiberCfg := fiber.Config{
+		BodyLimit: max(limit, chunkLimit),
+	}
+	proxyConfig(cfg, &fiberCfg)
+	app := fiber.New(fiberCfg)
+	app.Use(bodyLimit(limit, chunkLimit))
+	app.Use(cors.New(cors.Config{
+		ExposeHeaders: strings.Join(append(append([]string{fiber.HeaderETag, tracing.HeaderTraceID}, ratelimit.ExposedHeaders...), tus.ExposedHeaders...), ","),
 	}))
+	app.Use(recover.New())
+
+	// probes and scrapes are registered before tracing, metrics and the
+	// request logger to keep them quiet
+	app.Get("/healthz", healthHandler.Healthz)
+	app.Get("/readyz", healthHandler.Readyz)
+	app.Get("/metrics", metricsHandler.GetMetrics)
+
+	app.Use(tracing.Middleware())
+	app.Use(metrics.Middleware())
+	app.Use(logger.Middleware(log.Logger))
 
 	if os.Getenv("APP_ENV") != "production" {
 		cfg := swagger.Config{
@@ -84,28 +235,95 @@ func RunServer() {
 	}
 
 
+	app.Get("/img/:imageID", imageHandler.RenderImage)
+
+	//feed
+	feedApp := app.Group("/feeds")
+	feedApp.Get("/rss.xml", feedHandler.GetRss)
+	feedApp.Get("/atom.xml", feedHandler.GetAtom)
+	feedApp.Get("/categories/:categorySlug/rss.xml", feedHandler.GetRss)
+	feedApp.Get("/categories/:categorySlug/atom.xml", feedHandler.GetAtom)
+	feedApp.Get("/tags/:tag/rss.xml", feedHandler.GetRss)
+	feedApp.Get("/tags/:tag/atom.xml", feedHandler.GetAtom)
+
+	//sitemap
+	app.Get("/sitemap.xml", sitemapHandler.GetSitemapIndex)
+	app.Get("/news-sitemap.xml", sitemapHandler.GetNewsSitemap)
+	app.Get("/sitemaps/categories.xml", sitemapHandler.GetCategorySitemap)
+	app.Get("/sitemaps/contents-:page.xml", sitemapHandler.GetContentSitemap)
+
 	api := app.Group("/api")
-	api.Post("/login", authHandler.Login)
+	api.Post("/login", ratelimit.New(rateLimitStore, loginLimit, ratelimit.ByIP), authHandler.Login)
 
 	adminApp := api.Group("/admin")
 	adminApp.Use(middlewareAuth.CheckToken())
+	adminApp.Use(ratelimit.New(rateLimitStore, adminLimit, ratelimit.ByUser))
 
 	//category
 	categoryApp := adminApp.Group("/categories")
 	categoryApp.Get("/", categoryHandler.GetCategories)
+	categoryApp.Get("/trash", categoryHandler.GetTrashedCategories)
 	categoryApp.Post("/", categoryHandler.CreateCategory)
 	categoryApp.Get("/:categoryID", categoryHandler.GetCategoryByID)
 	categoryApp.Put("/:categoryID", categoryHandler.EditCategory)
 	categoryApp.Delete("/:categoryID", categoryHandler.DeleteCategory)
+	categoryApp.Post("/:categoryID/restore", categoryHandler.RestoreCategory)
+	categoryApp.Delete("/:categoryID/purge", categoryHandler.PurgeCategory)
 	
 	//content
 	contentApp := adminApp.Group("/contents")
 	contentApp.Get("/", contentHandler.GetContents) 
+	contentApp.Get("/trash", contentHandler.GetTrashedContents)
+	contentApp.Post("/bulk", contentHandler.BulkContents)
 	contentApp.Post("/", contentHandler.CreateContent) 
 	contentApp.Get("/:contentID", contentHandler.GetContentById) 
 	contentApp.Put("/:contentID", contentHandler.UpdateContent) 
 	contentApp.Delete("/:contentID", contentHandler.DeleteContent) 
+	contentApp.Post("/:contentID/restore", contentHandler.RestoreContent)
+	contentApp.Delete("/:contentID/purge", contentHandler.PurgeContent)
 	contentApp.Post("/upload-image", contentHandler.UploadImageR2)
+	contentApp.Post("/:contentID/attachments", contentHandler.CreateAttachment)
+	contentApp.Delete("/:contentID/attachments/:attachmentID", contentHandler.DeleteAttachment)
+	contentApp.Post("/:contentID/preview", contentHandler.CreatePreview)
+	contentApp.Post("/:contentID/lock", contentHandler.AcquireLock)
+	contentApp.Put("/:contentID/lock", contentHandler.HeartbeatLock)
+	contentApp.Delete("/:contentID/lock", contentHandler.ReleaseLock)
+
+	//upload (tus resumable upload)
+	uploadApp := adminApp.Group("/uploads", uploadHandler.CheckTusVersion())
+	uploadApp.Options("/", uploadHandler.OptionsUpload)
+	uploadApp.Post("/", uploadHandler.CreateUpload)
+	uploadApp.Get("/:uploadID", uploadHandler.GetUpload)
+	uploadApp.Head("/:uploadID", uploadHandler.HeadUpload)
+	uploadApp.Patch("/:uploadID", uploadHandler.PatchUpload)
+	uploadApp.Delete("/:uploadID", uploadHandler.DeleteUpload)
+
+	//image
+	imageApp := adminApp.Group("/images")
+	imageApp.Post("/", imageHandler.CreateImage)
+	imageApp.Get("/:imageID", imageHandler.GetImageByID)
+	imageApp.Put("/:imageID/focal-point", imageHandler.UpdateFocalPoint)
+	imageApp.Get("/:imageID/url", imageHandler.GetSignedUrl)
+
+	//import
+	importApp := adminApp.Group("/imports", adminOnly)
+	importApp.Post("/", importHandler.CreateImport)
+	importApp.Get("/", importHandler.GetImports)
+	importApp.Get("/:jobID", importHandler.GetImportByID)
+
+	//webhook
+	webhookApp := adminApp.Group("/webhooks", adminOnly)
+	webhookApp.Get("/", webhookHandler.GetWebhooks)
+	webhookApp.Post("/", webhookHandler.CreateWebhook)
+	webhookApp.Get("/:webhookID", webhookHandler.GetWebhookByID)
+	webhookApp.Put("/:webhookID", webhookHandler.UpdateWebhook)
+	webhookApp.Delete("/:webhookID", webhookHandler.DeleteWebhook)
+	webhookApp.Get("/:webhookID/deliveries", webhookHandler.GetDeliveries)
+	webhookApp.Get("/:webhookID/deliveries/:deliveryID", webhookHandler.GetDeliveryByID)
+	webhookApp.Post("/:webhookID/deliveries/:deliveryID/redeliver", webhookHandler.RedeliverDelivery)
+
+	//export
+	adminApp.Get("/export", adminOnly, backupHandler.Export)
 
 	//user 
 	userApp := adminApp.Group("/users")
@@ -113,10 +331,120 @@ func RunServer() {
 	userApp.Put("/update-password", userHandler.UpdatePassword)
 
 	//fe
-	feApp := api.Group("/fe")
+	feApp := api.Group("/fe", ratelimit.New(rateLimitStore, publicLimit, ratelimit.ByIP), httpcache.New(httpCachePolicy(cfg.HttpCache)))
 	feApp.Get("/categories", categoryHandler.GetCategoryFE)
 	feApp.Get("/contents", contentHandler.GetContentWithQuery)
 	feApp.Get("/contents/:contentID", contentHandler.GetContentDetail)
+	feApp.Get("/preview/:token", contentHandler.GetContentPreview)
+	feApp.Get("/redirects", importHandler.GetRedirect)
+
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			purged, err := uploadService.PurgeExpiredUploads(context.Background())
+			if err != nil {
+				log.Error().Err(err).Msg("error when purging expired uploads")
+				continue
+			}
+			if purged > 0 {
+				log.Info().Int("purged", purged).Msg("purged expired uploads")
+			}
+		}
+	}()
+
+	go func() {
+		retentionDays := cfg.Trash.RetentionDays
+		if retentionDays <= 0 {
+			retentionDays = 30
+		}
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			before := time.Now().AddDate(0, 0, -retentionDays)
+			purgedContents, err := contentService.PurgeTrash(context.Background(), before)
+			if err != nil {
+				log.Error().Err(err).Msg("error when purging trashed contents")
+				continue
+			}
+
+			purgedCategories, err := categoryService.PurgeTrash(context.Background(), before)
+			if err != nil {
+				log.Error().Err(err).Msg("error when purging trashed categories")
+				continue
+			}
+
+			if purgedContents > 0 || purgedCategories > 0 {
+				log.Info().Int64("contents", purgedContents).Int64("categories", purgedCategories).Msg("purged trash")
+			}
+		}
+	}()
+
+	go func() {
+		// a bucket idle for longer than it takes to refill is the same as
+		// no bucket at all
+		idle := time.Hour
+		for _, policy := range []ratelimit.Policy{loginLimit, publicLimit, adminLimit} {
+			if policy.Enabled() && policy.RefillTime() > idle {
+				idle = policy.RefillTime()
+			}
+		}
+
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := rateLimitStore.Sweep(context.Background(), idle); err != nil {
+				log.Error().Err(err).Msg("error when sweeping rate limit buckets")
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			// jobs of an instance that stopped are picked up once their
+			// heartbeat runs out
+			requeued, err := importService.RequeueInterruptedJobs(context.Background())
+			if err != nil {
+				log.Error().Err(err).Msg("error when requeueing interrupted imports")
+			}
+			if requeued > 0 {
+				log.Info().Int64("requeued", requeued).Msg("requeued interrupted imports")
+			}
+
+			for {
+				job, err := importService.RunNextJob(context.Background())
+				if err != nil {
+					log.Error().Err(err).Msg("error when running import")
+				}
+				if job == nil {
+					break
+				}
+				log.Info().Int64("job_id", job.ID).Str("status", job.Status).Msg("import finished")
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			delivered, err := webhookService.DeliverDue(context.Background())
+			if err != nil {
+				log.Error().Err(err).Msg("error when delivering webhooks")
+			}
+			if delivered > 0 {
+				log.Info().Int("delivered", delivered).Msg("delivered webhooks")
+			}
+		}
+	}()
 
 	go func() {
 		if cfg.App.AppPort == "" {
@@ -125,7 +453,7 @@ func RunServer() {
 
 		err := app.Listen(":" + cfg.App.AppPort)
 		if err != nil {
-			log.Fatalf("error when starting server: %v", err)
+			log.Fatal().Err(err).Msg("error when starting server")
 			return
 		}
 	}()
@@ -136,9 +464,27 @@ func RunServer() {
 
 	<-quit
 
-	log.Println("server shutdown on 5 seconds")
+	// report not ready first, so load balancers stop routing here while
+	// requests in flight still complete
+	healthService.SetShuttingDown()
+	shutdownDelay := cfg.Health.ShutdownDelaySeconds
+	if shutdownDelay <= 0 {
+		shutdownDelay = 5
+	}
+	log.Info().Int("delay_seconds", shutdownDelay).Msg("server not ready, shutting down")
+	time.Sleep(time.Duration(shutdownDelay) * time.Second)
+
+	log.Info().Msg("server shutdown on 5 seconds")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	app.ShutdownWithContext(ctx)
+
+	if err := closeCachePurger(ctx); err != nil {
+		log.Error().Err(err).Msg("error sending queued cdn purges")
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("error flushing traces")
+	}
 }
\ No newline at end of file
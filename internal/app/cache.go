@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/cache"
+	"gonews/internal/adapter/cdn"
+	"gonews/lib/httpcache"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	cacheStoreMemory = "memory"
+	cacheStoreRedis  = "redis"
+	cacheStoreNone   = "none"
+
+	defaultCacheSize = 1000
+
+	cdnPurgeAdapterWebhook = "webhook"
+	cdnPurgeAdapterNone    = "none"
+
+	defaultCdnPurgeAttempts = 5
+	defaultCdnPurgeBackoff  = 2 * time.Second
+
+	// commands wait this long for their purges before exiting
+	commandPurgeTimeout = time.Minute
+)
+
+// newRedisClient parses a redis://[user:password@]host[:port][/db] URL;
+// rediss:// uses TLS and unix:///path/to/socket a unix socket. No connection
+// is opened until the first command.
+func newRedisClient(rawURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("redis url invalid: %w", err)
+	}
+
+	return redis.NewClient(opts), nil
+}
+
+func newCache(cfg *config.Config, redisClient *redis.Client) (cache.Cache, error) {
+	switch strings.ToLower(cfg.Cache.Store) {
+	case "", cacheStoreMemory:
+		size := cfg.Cache.Size
+		if size <= 0 {
+			size = defaultCacheSize
+		}
+		return cache.NewLRUCache(size), nil
+	case cacheStoreRedis:
+		if redisClient == nil {
+			return nil, fmt.Errorf("cache store redis needs REDIS_URL")
+		}
+		return cache.NewRedisCache(redisClient), nil
+	case cacheStoreNone:
+		return cache.NewNoopCache(), nil
+	default:
+		return nil, fmt.Errorf("unknown cache store %q", cfg.Cache.Store)
+	}
+}
+
+// newCommandCache is the cache of one-off commands. Only a shared store is
+// worth invalidating from outside the server, so anything else is a no-op.
+func newCommandCache(cfg *config.Config) (cache.Cache, func(), error) {
+	if strings.ToLower(cfg.Cache.Store) != cacheStoreRedis {
+		return cache.NewNoopCache(), func() {}, nil
+	}
+
+	redisClient, err := newRedisClient(cfg.Redis.Url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cache.NewRedisCache(redisClient), func() { redisClient.Close() }, nil
+}
+
+func httpCachePolicy(cfg config.HttpCache) httpcache.Policy {
+	return httpcache.Policy{
+		MaxAge:               time.Duration(cfg.MaxAgeSeconds) * time.Second,
+		SharedMaxAge:         time.Duration(cfg.SharedMaxAgeSeconds) * time.Second,
+		StaleWhileRevalidate: time.Duration(cfg.StaleWhileRevalidateSeconds) * time.Second,
+		StaleIfError:         time.Duration(cfg.StaleIfErrorSeconds) * time.Second,
+		SurrogateKeyHeader:   cfg.SurrogateKeyHeader,
+	}
+}
+
+// newCachePurger returns the CDN purger and a function that waits for queued
+// purges to go out, to call before exiting.
+func newCachePurger(cfg *config.Config) (cdn.CachePurger, func(context.Context) error, error) {
+	switch strings.ToLower(cfg.CdnPurge.Adapter) {
+	case "", cdnPurgeAdapterNone:
+		return cdn.NewNoopPurger(), func(context.Context) error { return nil }, nil
+	case cdnPurgeAdapterWebhook:
+		if cfg.CdnPurge.WebhookUrl == "" {
+			return nil, nil, fmt.Errorf("cdn purge adapter webhook needs CDN_PURGE_WEBHOOK_URL")
+		}
+
+		attempts := cfg.CdnPurge.Attempts
+		if attempts <= 0 {
+			attempts = defaultCdnPurgeAttempts
+		}
+		backoff := time.Duration(cfg.CdnPurge.BackoffSeconds) * time.Second
+		if backoff <= 0 {
+			backoff = defaultCdnPurgeBackoff
+		}
+
+		purger := cdn.NewAsyncPurger(cdn.NewWebhookPurger(cfg), attempts, backoff, 0)
+		return purger, purger.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown cdn purge adapter %q", cfg.CdnPurge.Adapter)
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"gonews/config"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/service"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunConvertDescriptions converts legacy HTML descriptions into structured
+// blocks, sanitizing them on the way through.
+func RunConvertDescriptions(dryRun bool) {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+		return
+	}
+
+	readCache, closeCache, err := newCommandCache(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cache")
+		return
+	}
+	defer closeCache()
+
+	cachePurger, closeCachePurger, err := newCachePurger(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cdn purger")
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), commandPurgeTimeout)
+		defer cancel()
+		if err := closeCachePurger(ctx); err != nil {
+			log.Error().Err(err).Msg("Error sending queued cdn purges")
+		}
+	}()
+
+	ikAdapter := imagekit.NewImageKitAdapter(cfg)
+	contentRepo := repository.NewContentRepository(db.DB)
+	// conversions only rewrite stored bodies and raise no webhook events
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db.DB), cfg)
+	contentService := service.NewContentService(contentRepo, repository.NewTransactor(db.DB), cfg, ikAdapter, readCache, cachePurger, webhookService)
+
+	converted, err := contentService.ConvertLegacyDescriptions(context.Background(), dryRun)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error converting descriptions")
+		return
+	}
+
+	if dryRun {
+		log.Info().Int("contents", converted).Msg("dry run, nothing converted")
+		return
+	}
+
+	log.Info().Int("contents", converted).Msg("contents converted")
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"gonews/config"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/service"
+	"gonews/lib/backup"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunExport writes a site archive to out, narrowed down by the from and to
+// dates and a category slug when given.
+func RunExport(out, from, to, category string) {
+	filter, err := backup.ParseFilter(from, to, category)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error parsing filter")
+		return
+	}
+
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+		return
+	}
+
+	backupRepo := repository.NewBackupRepository(db.DB)
+	backupService := service.NewBackupService(backupRepo)
+
+	file, err := os.Create(out)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", out).Msg("Error creating archive")
+		return
+	}
+
+	manifest, err := backupService.Export(context.Background(), file, filter)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(out)
+		log.Fatal().Err(err).Msg("Error exporting")
+		return
+	}
+
+	log.Info().
+		Str("file", out).
+		Int64("users", manifest.Counts[backup.FileUsers]).
+		Int64("categories", manifest.Counts[backup.FileCategories]).
+		Int64("contents", manifest.Counts[backup.FileContents]).
+		Int64("images", manifest.Counts[backup.FileImages]).
+		Int64("media", manifest.Counts[backup.FileMedia]).
+		Msg("archive exported")
+}
+
+// RunRestore restores a site archive into an empty database.
+func RunRestore(file string) {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+		return
+	}
+
+	backupRepo := repository.NewBackupRepository(db.DB)
+	backupService := service.NewBackupService(backupRepo)
+
+	manifest, result, err := backupService.Restore(context.Background(), file)
+	if err != nil {
+		log.Fatal().Err(err).Str("file", file).Msg("Error restoring")
+		return
+	}
+
+	log.Info().
+		Time("archived_at", manifest.CreatedAt).
+		Int64("users_created", result.UsersCreated).
+		Int64("users_matched", result.UsersMatched).
+		Int64("categories_created", result.CategoriesCreated).
+		Int64("contents_created", result.ContentsCreated).
+		Int64("images_created", result.ImagesCreated).
+		Msg("archive restored")
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"gonews/config"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// uploadChunkPrefix is where tus chunks are PATCHed to.
+const uploadChunkPrefix = "/api/admin/uploads/"
+
+// bodyLimits returns the limit for most request bodies and the one for tus
+// chunks. Both default to fiber's limit.
+func bodyLimits(cfg *config.Config) (int, int) {
+	limit := cfg.App.BodyLimit
+	if limit <= 0 {
+		limit = fiber.DefaultBodyLimit
+	}
+
+	chunkLimit := cfg.Upload.MaxChunkSize
+	if chunkLimit <= 0 {
+		chunkLimit = fiber.DefaultBodyLimit
+	}
+
+	return limit, chunkLimit
+}
+
+// proxyConfig makes c.IP() the client address sent by the trusted proxies
+// instead of the proxy's own, so rate limits and logs see real clients.
+func proxyConfig(cfg *config.Config, fiberCfg *fiber.Config) {
+	if cfg.App.ProxyHeader == "" {
+		return
+	}
+
+	fiberCfg.ProxyHeader = cfg.App.ProxyHeader
+	fiberCfg.EnableIPValidation = true
+	fiberCfg.EnableTrustedProxyCheck = true
+	for _, proxy := range strings.Split(cfg.App.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			fiberCfg.TrustedProxies = append(fiberCfg.TrustedProxies, proxy)
+		}
+	}
+}
+
+// bodyLimit holds request bodies to limit, except tus chunks, which get
+// chunkLimit. The server reads bodies up to the larger of the two, so this
+// runs first to keep a large chunk limit from applying to every route.
+func bodyLimit(limit int, chunkLimit int) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		allowed := limit
+		if c.Method() == fiber.MethodPatch && strings.HasPrefix(c.Path(), uploadChunkPrefix) {
+			allowed = chunkLimit
+		}
+
+		if len(c.Body()) > allowed {
+			return fiber.ErrRequestEntityTooLarge
+		}
+
+		return c.Next()
+	}
+}
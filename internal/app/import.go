@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"gonews/config"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunImport imports a WordPress WXR, JSON or CSV export from a local file.
+// Contents without an author are attributed to the user with userEmail.
+func RunImport(format, file, userEmail string) {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+		return
+	}
+
+	readCache, closeCache, err := newCommandCache(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cache")
+		return
+	}
+	defer closeCache()
+
+	cachePurger, closeCachePurger, err := newCachePurger(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating cdn purger")
+		return
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), commandPurgeTimeout)
+		defer cancel()
+		if err := closeCachePurger(ctx); err != nil {
+			log.Error().Err(err).Msg("Error sending queued cdn purges")
+		}
+	}()
+
+	ikAdapter := imagekit.NewImageKitAdapter(cfg)
+	authRepo := repository.NewAuthRepository(db.DB)
+	importRepo := repository.NewImportRepository(db.DB)
+	webhookService := service.NewWebhookService(repository.NewWebhookRepository(db.DB), cfg)
+	importService := service.NewImportService(importRepo, repository.NewContentRepository(db.DB), repository.NewTransactor(db.DB), cfg, ikAdapter, readCache, cachePurger, webhookService)
+
+	req := entity.ImportJobEntity{
+		Format:   strings.ToLower(format),
+		FileName: filepath.Base(file),
+		FilePath: file,
+	}
+
+	if userEmail != "" {
+		user, err := authRepo.GetUserByEmail(context.Background(), entity.LoginRequest{Email: userEmail})
+		if err != nil {
+			log.Fatal().Err(err).Str("email", userEmail).Msg("Error finding user")
+			return
+		}
+		req.CreatedById = user.ID
+	}
+
+	job, err := importService.RunImport(context.Background(), req)
+	if job != nil {
+		log.Info().
+			Int64("id", job.ID).
+			Str("status", job.Status).
+			Int("users_created", job.Stats.UsersCreated).
+			Int("categories_created", job.Stats.CategoriesCreated).
+			Int("contents_created", job.Stats.ContentsCreated).
+			Int("contents_skipped", job.Stats.ContentsSkipped).
+			Int("contents_failed", job.Stats.ContentsFailed).
+			Int("images_rehomed", job.Stats.ImagesRehomed).
+			Int("images_failed", job.Stats.ImagesFailed).
+			Int("redirects_created", job.Stats.RedirectsCreated).
+			Msg("import finished")
+		for _, jobErr := range job.Errors {
+			log.Warn().Int64("id", job.ID).Msg(jobErr)
+		}
+	}
+	if err != nil {
+		log.Fatal().Err(err).Str("file", file).Msg("Error importing")
+		return
+	}
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"gonews/config"
+	"gonews/database"
+	"gonews/database/seeds"
+	"gonews/lib/migrate"
+
+	"github.com/rs/zerolog/log"
+)
+
+// newMigrator connects to the database and loads the migrations embedded
+// in the binary.
+func newMigrator() *migrate.Migrator {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+	}
+
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error getting database connection")
+	}
+
+	migrations, err := migrate.Load(database.Migrations, database.MigrationsDir)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error loading migrations")
+	}
+
+	return migrate.New(sqlDB, migrations)
+}
+
+// RunMigrateUp applies steps pending migrations, all of them when steps is 0.
+func RunMigrateUp(steps int) {
+	applied, err := newMigrator().Up(context.Background(), steps)
+	for _, migration := range applied {
+		log.Info().Uint64("version", migration.Version).Str("name", migration.Name).Msg("migration applied")
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error migrating up")
+		return
+	}
+
+	if len(applied) == 0 {
+		log.Info().Msg("no pending migrations")
+	}
+}
+
+// RunMigrateDown reverts the last steps migrations.
+func RunMigrateDown(steps int) {
+	reverted, err := newMigrator().Down(context.Background(), steps)
+	for _, migration := range reverted {
+		log.Info().Uint64("version", migration.Version).Str("name", migration.Name).Msg("migration reverted")
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error migrating down")
+		return
+	}
+
+	if len(reverted) == 0 {
+		log.Info().Msg("no applied migrations")
+	}
+}
+
+// RunMigrateStatus lists the migrations and whether they are applied.
+func RunMigrateStatus() {
+	current, dirty, statuses, err := newMigrator().Status(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error reading migration status")
+		return
+	}
+
+	for _, status := range statuses {
+		state := "pending"
+		if status.Applied {
+			state = "applied"
+		}
+		log.Info().Str("state", state).Uint64("version", status.Version).Str("name", status.Name).Msg("migration")
+	}
+
+	if dirty {
+		log.Warn().Uint64("version", current).Msg("database version is dirty")
+		return
+	}
+	log.Info().Uint64("version", current).Msg("database version")
+}
+
+// RunMigrateCreate adds an empty up and down migration to dir.
+func RunMigrateCreate(dir, name string) {
+	upPath, downPath, err := migrate.Create(dir, name)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating migration")
+		return
+	}
+
+	log.Info().Str("path", upPath).Msg("migration created")
+	log.Info().Str("path", downPath).Msg("migration created")
+}
+
+// RunSeed seeds the database with development data. It refuses to run
+// unless dev is set and never runs in production, as the seeded admin
+// has a well-known password.
+func RunSeed(dev bool) {
+	if !dev {
+		log.Fatal().Msg("seed only creates development data with a well-known admin password; pass --dev to seed, or create real accounts with the user command")
+		return
+	}
+
+	cfg := config.NewConfig()
+	if cfg.App.AppEnv == "production" {
+		log.Fatal().Msg("refusing to seed development data with APP_ENV=production")
+		return
+	}
+
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+		return
+	}
+
+	seeds.SeedDevAdmin(db.DB)
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"database/sql"
+	"fmt"
+	"gonews/config"
+	"gonews/lib/ratelimit"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	rateLimitStoreMemory   = "memory"
+	rateLimitStorePostgres = "postgres"
+	rateLimitStoreRedis    = "redis"
+)
+
+func newRateLimitStore(cfg *config.Config, sqlDB *sql.DB, redisClient *redis.Client) (ratelimit.Store, error) {
+	switch strings.ToLower(cfg.RateLimit.Store) {
+	case "", rateLimitStoreMemory:
+		return ratelimit.NewMemoryStore(), nil
+	case rateLimitStorePostgres:
+		return ratelimit.NewPostgresStore(sqlDB), nil
+	case rateLimitStoreRedis:
+		if redisClient == nil {
+			return nil, fmt.Errorf("rate limit store redis needs REDIS_URL")
+		}
+		return ratelimit.NewRedisStore(redisClient), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+}
+
+func rateLimitPolicy(name string, policy config.RateLimitPolicy) ratelimit.Policy {
+	return ratelimit.Policy{
+		Name:   name,
+		Limit:  policy.Requests,
+		Period: time.Duration(policy.PeriodSeconds) * time.Second,
+		Burst:  policy.Burst,
+	}
+}
@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/internal/core/service"
+	"os"
+	"text/tabwriter"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+func newUserService() service.UserService {
+	cfg := config.NewConfig()
+	db, err := cfg.ConnectionPostgres()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error connecting to database")
+	}
+
+	return service.NewUserService(repository.NewUserRepository(db.DB))
+}
+
+// RunUserCreate creates a user that can log in with password.
+func RunUserCreate(email, name, role, password string) {
+	user, err := newUserService().CreateUser(context.Background(), entity.UserEntity{
+		Name:     name,
+		Email:    email,
+		Password: password,
+		Role:     role,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error creating user")
+		return
+	}
+
+	log.Info().Int64("id", user.ID).Str("email", user.Email).Str("role", user.Role).Msg("user created")
+}
+
+// RunUserSetPassword replaces the password of the user with email.
+func RunUserSetPassword(email, password string) {
+	err := newUserService().SetPassword(context.Background(), email, password)
+	if err != nil {
+		log.Fatal().Err(userError(email, err)).Msg("Error setting password")
+		return
+	}
+
+	log.Info().Str("email", email).Msg("password updated")
+}
+
+// RunUserSetRole changes the role of the user with email.
+func RunUserSetRole(email, role string) {
+	err := newUserService().SetRole(context.Background(), email, role)
+	if err != nil {
+		log.Fatal().Err(userError(email, err)).Msg("Error setting role")
+		return
+	}
+
+	log.Info().Str("email", email).Str("role", role).Msg("role updated")
+}
+
+// RunUserList prints every user.
+func RunUserList() {
+	users, err := newUserService().GetUsers(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error listing users")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tSTATUS\tCREATED")
+	for _, user := range users {
+		status := "active"
+		if user.DeactivatedAt != nil {
+			status = "deactivated " + user.DeactivatedAt.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", user.ID, user.Email, user.Name, user.Role, status, user.CreatedAt.Format("2006-01-02"))
+	}
+	w.Flush()
+}
+
+// RunUserDeactivate stops the user with email from logging in.
+func RunUserDeactivate(email string) {
+	err := newUserService().DeactivateUser(context.Background(), email)
+	if err != nil {
+		log.Fatal().Err(userError(email, err)).Msg("Error deactivating user")
+		return
+	}
+
+	log.Info().Str("email", email).Msg("user deactivated")
+}
+
+func userError(email string, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("no user with email %s", email)
+	}
+
+	return err
+}
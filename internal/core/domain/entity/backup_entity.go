@@ -0,0 +1,105 @@
+package entity
+
+import "time"
+
+const (
+	MediaKindContentImage     = "content_image"
+	MediaKindInlineImage      = "inline_image"
+	MediaKindAttachment       = "attachment"
+	MediaKindAttachmentPoster = "attachment_poster"
+	MediaKindImage            = "image"
+)
+
+// Backup entities mirror the table rows they are exported from, so a
+// restore brings back every column, including trashed rows. Password hashes
+// are left out of exports; Password is only read from older archives.
+type BackupUserEntity struct {
+	ID            int64      `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Password      string     `json:"password,omitempty"`
+	Role          string     `json:"role"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at,omitempty"`
+}
+
+type BackupCategoryEntity struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	CreatedByID *int64     `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	Version     int64      `json:"version"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type BackupContentEntity struct {
+	ID                int64                    `json:"id"`
+	Title             string                   `json:"title"`
+	Slug              string                   `json:"slug"`
+	Excerpt           string                   `json:"excerpt"`
+	Description       string                   `json:"description"`
+	BodyFormat        string                   `json:"body_format"`
+	DescriptionSource string                   `json:"description_source,omitempty"`
+	Body              *string                  `json:"body,omitempty"`
+	TableOfContents   *string                  `json:"table_of_contents,omitempty"`
+	PlainText         string                   `json:"plain_text"`
+	Image             string                   `json:"image"`
+	Tags              []string                 `json:"tags"`
+	Status            string                   `json:"status"`
+	CategoryID        int64                    `json:"category_id"`
+	CreatedByID       *int64                   `json:"created_by_id"`
+	CreatedAt         time.Time                `json:"created_at"`
+	UpdatedAt         *time.Time               `json:"updated_at,omitempty"`
+	Version           int64                    `json:"version"`
+	DeletedAt         *time.Time               `json:"deleted_at,omitempty"`
+	Attachments       []BackupAttachmentEntity `json:"attachments"`
+}
+
+type BackupAttachmentEntity struct {
+	ID         int64      `json:"id"`
+	Type       string     `json:"type"`
+	Url        string     `json:"url"`
+	FileName   string     `json:"file_name"`
+	MimeType   string     `json:"mime_type"`
+	Size       int64      `json:"size"`
+	DurationMs int64      `json:"duration_ms"`
+	VideoCodec string     `json:"video_codec,omitempty"`
+	AudioCodec string     `json:"audio_codec,omitempty"`
+	Width      int        `json:"width"`
+	Height     int        `json:"height"`
+	SampleRate int        `json:"sample_rate"`
+	Channels   int        `json:"channels"`
+	PosterUrl  string     `json:"poster_url,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+type BackupImageEntity struct {
+	ID          int64      `json:"id"`
+	Url         string     `json:"url"`
+	FocalX      float64    `json:"focal_x"`
+	FocalY      float64    `json:"focal_y"`
+	CreatedByID int64      `json:"created_by_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// BackupMediaEntity is a line of the media manifest: a file in storage the
+// exported rows point at. Files are not copied into the archive.
+type BackupMediaEntity struct {
+	Url       string `json:"url"`
+	Kind      string `json:"kind"`
+	ContentID int64  `json:"content_id,omitempty"`
+	ImageID   int64  `json:"image_id,omitempty"`
+}
+
+type BackupRestoreEntity struct {
+	UsersCreated      int64
+	UsersMatched      int64
+	CategoriesCreated int64
+	ContentsCreated   int64
+	ImagesCreated     int64
+}
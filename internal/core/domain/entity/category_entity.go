@@ -1,8 +1,13 @@
 package entity
 
+import "time"
+
 type CategoryEntity struct {
-	ID    int64
-	Title string
-	Slug  string
-	User  UserEntity
+	ID        int64
+	Title     string
+	Slug      string
+	User      UserEntity
+	Version   int64
+	UpdatedAt time.Time
+	DeletedAt *time.Time
 }
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+const (
+	AttachmentTypeAudio    = "audio"
+	AttachmentTypeVideo    = "video"
+	AttachmentTypeDocument = "document"
+)
+
+type MediaEntity struct {
+	MimeType   string
+	DurationMs int64
+	VideoCodec string
+	AudioCodec string
+	Width      int
+	Height     int
+	SampleRate int
+	Channels   int
+}
+
+type ContentAttachmentEntity struct {
+	ID        int64
+	ContentID int64
+	Type      string
+	Url       string
+	FileName  string
+	Size      int64
+	Media     MediaEntity
+	PosterUrl string
+	CreatedAt time.Time
+}
+
+// AttachmentUploadEntity describes a new attachment, either as a local file
+// still to be probed and stored, or as an already stored file (Url + Media).
+type AttachmentUploadEntity struct {
+	ContentID   int64
+	Type        string
+	FileName    string
+	Path        string
+	PosterPath  string
+	Url         string
+	Size        int64
+	Media       *MediaEntity
+	CreatedById int64
+}
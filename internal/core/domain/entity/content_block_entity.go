@@ -0,0 +1,39 @@
+package entity
+
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockImage     = "image"
+	BlockQuote     = "quote"
+	BlockEmbed     = "embed"
+	BlockList      = "list"
+	BlockCode      = "code"
+)
+
+// ContentBlock is one element of a structured content body. Which fields
+// are used depends on Type.
+type ContentBlock struct {
+	Type     string   `json:"type"`
+	Text     string   `json:"text,omitempty"`
+	Level    int      `json:"level,omitempty"`
+	Url      string   `json:"url,omitempty"`
+	Alt      string   `json:"alt,omitempty"`
+	Caption  string   `json:"caption,omitempty"`
+	Ordered  bool     `json:"ordered,omitempty"`
+	Items    []string `json:"items,omitempty"`
+	Language string   `json:"language,omitempty"`
+}
+
+const (
+	BodyFormatHTML     = "html"
+	BodyFormatMarkdown = "markdown"
+	BodyFormatBlocks   = "blocks"
+)
+
+// TocEntry is one heading of a rendered content body, linked by the id the
+// heading carries in the rendered HTML.
+type TocEntry struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+const (
+	BulkActionPublish     = "publish"
+	BulkActionUnpublish   = "unpublish"
+	BulkActionSetCategory = "set_category"
+	BulkActionAddTags     = "add_tags"
+	BulkActionRemoveTags  = "remove_tags"
+	BulkActionSetTags     = "set_tags"
+	BulkActionDelete      = "delete"
+)
+
+const (
+	BulkItemSucceeded = "succeeded"
+	BulkItemFailed    = "failed"
+	BulkItemNotFound  = "not_found"
+	BulkItemLocked    = "locked"
+)
+
+// ContentBulkEntity describes one bulk action. Targets are either IDs or,
+// when no IDs are given, every content matching Filter. Contents someone
+// other than UserID holds an edit lock on are left alone.
+type ContentBulkEntity struct {
+	UserID     int64
+	Action     string
+	IDs        []int64
+	Filter     *QueryString
+	CategoryID int64
+	Tags       []string
+	DryRun     bool
+}
+
+type ContentBulkItemEntity struct {
+	ID     int64
+	Title  string
+	Status string
+	Error  string
+}
+
+type ContentBulkResultEntity struct {
+	Action  string
+	DryRun  bool
+	Applied bool
+	Items   []ContentBulkItemEntity
+}
@@ -3,18 +3,28 @@ package entity
 import "time"
 
 type ContentEntity struct {
-	ID          int64
-	Title       string
-	Excerpt     string
-	Description string
-	Image       string
-	Tags        []string
-	Status      string
-	CategoryID  int64
-	CreatedById int64
-	CreatedAt   time.Time
-	Category CategoryEntity
-	User UserEntity
+	ID                int64
+	Title             string
+	Slug              string
+	Excerpt           string
+	Description       string
+	DescriptionSource string
+	BodyFormat        string
+	Blocks            []ContentBlock
+	PlainText         string
+	TableOfContents   []TocEntry
+	Image             string
+	Tags              []string
+	Status            string
+	CategoryID        int64
+	CreatedById       int64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Version           int64
+	DeletedAt         *time.Time
+	Category          CategoryEntity
+	User              UserEntity
+	Attachments       []ContentAttachmentEntity
 }
 
 type QueryString struct {
@@ -25,4 +35,12 @@ type QueryString struct {
 	Search     string
 	CategoryID int64
 	Status     string
+	Tag        string
+	Trashed    bool
+}
+
+type SitemapQueryEntity struct {
+	Since  time.Time
+	Offset int
+	Limit  int
 }
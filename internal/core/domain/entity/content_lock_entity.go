@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+type ContentLockEntity struct {
+	ContentID   int64
+	User        UserEntity
+	AcquiredAt  time.Time
+	HeartbeatAt time.Time
+	ExpiresAt   time.Time
+}
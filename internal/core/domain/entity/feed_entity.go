@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type FeedQueryEntity struct {
+	CategorySlug string
+	Tag          string
+}
+
+type FeedEntity struct {
+	Title       string
+	Description string
+	Link        string
+	Updated     time.Time
+	Items       []ContentEntity
+}
@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type HealthCheckEntity struct {
+	Name      string
+	Status    string
+	Latency   time.Duration
+	Error     string
+	Detail    string
+	CheckedAt time.Time
+}
+
+type HealthReportEntity struct {
+	Status string
+	Checks []HealthCheckEntity
+}
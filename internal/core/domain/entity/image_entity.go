@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type ImageEntity struct {
+	ID          int64
+	Url         string
+	FocalX      float64
+	FocalY      float64
+	CreatedById int64
+	UpdatedAt   time.Time
+}
+
+type ImageTransformEntity struct {
+	Width  int
+	Height int
+	Fit    string
+	Format string
+}
@@ -0,0 +1,88 @@
+package entity
+
+import "time"
+
+const (
+	ImportFormatWXR  = "wxr"
+	ImportFormatJSON = "json"
+	ImportFormatCSV  = "csv"
+
+	ImportStatusQueued    = "queued"
+	ImportStatusRunning   = "running"
+	ImportStatusSucceeded = "succeeded"
+	ImportStatusFailed    = "failed"
+
+	ImportSourceContent = "content"
+	ImportSourceImage   = "image"
+)
+
+// ImportAuthorEntity is an author as found in an import file.
+type ImportAuthorEntity struct {
+	Login string
+	Email string
+	Name  string
+}
+
+// ImportCategoryEntity is a category as found in an import file.
+type ImportCategoryEntity struct {
+	Slug  string
+	Title string
+}
+
+// ImportContentEntity is an article as found in an import file. SourceKey
+// identifies it across re-runs; SourceUrl is the address it had on the old
+// site.
+type ImportContentEntity struct {
+	SourceKey   string
+	SourceUrl   string
+	Title       string
+	Slug        string
+	Excerpt     string
+	Description string
+	Status      string
+	Author      ImportAuthorEntity
+	Category    ImportCategoryEntity
+	Tags        []string
+	Image       string
+	PublishedAt time.Time
+}
+
+type ImportStatsEntity struct {
+	UsersCreated      int `json:"users_created"`
+	CategoriesCreated int `json:"categories_created"`
+	ContentsCreated   int `json:"contents_created"`
+	ContentsSkipped   int `json:"contents_skipped"`
+	ContentsFailed    int `json:"contents_failed"`
+	ImagesRehomed     int `json:"images_rehomed"`
+	ImagesFailed      int `json:"images_failed"`
+	RedirectsCreated  int `json:"redirects_created"`
+}
+
+type ImportJobEntity struct {
+	ID          int64
+	Format      string
+	FileName    string
+	FilePath    string
+	SourceUrl   string
+	Status      string
+	Stats       ImportStatsEntity
+	Errors      []string
+	Error       string
+	CreatedById int64
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
+
+type ImportMappingEntity struct {
+	SourceType string
+	SourceKey  string
+	TargetID   int64
+	TargetUrl  string
+}
+
+type RedirectEntity struct {
+	FromPath  string
+	ContentID int64
+	ToPath    string
+}
@@ -4,5 +4,6 @@ import "github.com/golang-jwt/jwt/v5"
 
 type JwtData struct {
 	UserID float64 `json:"user_id"`
+	Role   string  `json:"role"`
 	jwt.RegisteredClaims
 }
\ No newline at end of file
@@ -0,0 +1,10 @@
+package entity
+
+import "time"
+
+type PreviewEntity struct {
+	ContentID int64
+	Token     string
+	Url       string
+	ExpiresAt time.Time
+}
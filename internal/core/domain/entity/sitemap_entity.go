@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type SitemapEntity struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type NewsSitemapEntity struct {
+	Loc         string
+	Title       string
+	PublishedAt time.Time
+}
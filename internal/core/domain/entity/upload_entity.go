@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// States of an upload whose data is all there. An upload still receiving
+// chunks has no state.
+const (
+	UploadStateCompleting = "completing"
+	UploadStateCompleted  = "completed"
+)
+
+type UploadEntity struct {
+	ID          string
+	Size        int64
+	Offset      int64
+	Metadata    map[string]string
+	Url         string
+	State       string
+	Media       *MediaEntity
+	CreatedById int64
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (u UploadEntity) IsComplete() bool {
+	return u.Offset == u.Size
+}
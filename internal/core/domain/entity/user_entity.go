@@ -1,8 +1,23 @@
 package entity
 
+import "time"
+
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleAuthor = "author"
+)
+
+// Roles lists the valid user roles, most privileged first.
+var Roles = []string{RoleAdmin, RoleEditor, RoleAuthor}
+
 type UserEntity struct {
-	ID       int64
-	Name     string
-	Email    string
-	Password string
+	ID            int64
+	Name          string
+	Email         string
+	Password      string
+	Role          string
+	DeactivatedAt *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
 }
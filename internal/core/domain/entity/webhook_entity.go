@@ -0,0 +1,65 @@
+package entity
+
+import "time"
+
+const (
+	WebhookEventContentCreated     = "content.created"
+	WebhookEventContentUpdated     = "content.updated"
+	WebhookEventContentPublished   = "content.published"
+	WebhookEventContentUnpublished = "content.unpublished"
+	WebhookEventContentDeleted     = "content.deleted"
+	WebhookEventContentRestored    = "content.restored"
+	WebhookEventContentPurged      = "content.purged"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookEvents lists the event types a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventContentCreated,
+	WebhookEventContentUpdated,
+	WebhookEventContentPublished,
+	WebhookEventContentUnpublished,
+	WebhookEventContentDeleted,
+	WebhookEventContentRestored,
+	WebhookEventContentPurged,
+}
+
+// WebhookEntity is a subscription of an outside URL to content events. An
+// empty Events list subscribes to all of them.
+type WebhookEntity struct {
+	ID             int64
+	Url            string
+	Secret         string
+	Description    string
+	Events         []string
+	Active         bool
+	FailureCount   int
+	DisabledAt     *time.Time
+	DisabledReason string
+	CreatedById    int64
+	CreatedAt      time.Time
+	UpdatedAt      *time.Time
+}
+
+// WebhookDeliveryEntity is one event queued for one webhook, with the
+// outcome of its latest attempt.
+type WebhookDeliveryEntity struct {
+	ID             int64
+	WebhookID      int64
+	Webhook        WebhookEntity
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastAttemptAt  *time.Time
+	ResponseStatus int
+	ResponseBody   string
+	Error          string
+	DurationMs     int64
+	RedeliveryOfID int64
+	CreatedAt      time.Time
+}
@@ -1,13 +1,19 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Category struct {
-	ID          int64      `gorm:"id"`
-	Title       string     `gorm:"title"`
-	Slug        string     `gorm:"slug"`
-	CreatedByID int64      `gorm:"created_by_id"`
-	User        User       `gorm:"foreignKey:CreatedByID"`
-	CreatedAt   time.Time  `gorm:"create_at"`
-	UpdatedAt   *time.Time `gorm:"updated_at"`
+	ID          int64          `gorm:"id"`
+	Title       string         `gorm:"title"`
+	Slug        string         `gorm:"slug"`
+	CreatedByID *int64         `gorm:"created_by_id"`
+	User        User           `gorm:"foreignKey:CreatedByID"`
+	CreatedAt   time.Time      `gorm:"create_at"`
+	UpdatedAt   *time.Time     `gorm:"updated_at"`
+	Version     int64          `gorm:"version"`
+	DeletedAt   gorm.DeletedAt `gorm:"deleted_at"`
 }
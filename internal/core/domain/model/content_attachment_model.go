@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type ContentAttachment struct {
+	ID         int64      `gorm:"id"`
+	ContentID  int64      `gorm:"content_id"`
+	Type       string     `gorm:"type"`
+	Url        string     `gorm:"url"`
+	FileName   string     `gorm:"file_name"`
+	MimeType   string     `gorm:"mime_type"`
+	Size       int64      `gorm:"size"`
+	DurationMs int64      `gorm:"duration_ms"`
+	VideoCodec string     `gorm:"video_codec"`
+	AudioCodec string     `gorm:"audio_codec"`
+	Width      int        `gorm:"width"`
+	Height     int        `gorm:"height"`
+	SampleRate int        `gorm:"sample_rate"`
+	Channels   int        `gorm:"channels"`
+	PosterUrl  string     `gorm:"poster_url"`
+	CreatedAt  time.Time  `gorm:"created_at"`
+	UpdatedAt  *time.Time `gorm:"updated_at"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+type ContentLock struct {
+	ContentID   int64     `gorm:"content_id;primaryKey"`
+	UserID      int64     `gorm:"user_id"`
+	User        User      `gorm:"foreignKey:UserID"`
+	AcquiredAt  time.Time `gorm:"acquired_at"`
+	HeartbeatAt time.Time `gorm:"heartbeat_at"`
+	ExpiresAt   time.Time `gorm:"expires_at"`
+}
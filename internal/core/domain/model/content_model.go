@@ -1,19 +1,32 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Content struct {
-	ID          int64      `gorm:"id"`
-	Title       string     `gorm:"title"`
-	Excerpt     string     `gorm:"excerpt"`
-	Description string     `gorm:"description"`
-	Image       string     `gorm:"image"`
-	Tags        string     `gorm:"tags"`
-	Status      string     `gorm:"status"`
-	CategoryID  int64      `gorm:"category_id"`
-	CreatedByID int64      `gorm:"created_by_id"`
-	User        User       `gorm:"foreignKey:CreatedByID"`
-	Category    Category   `gorm:"foreignKey:CategoryID"`
-	CreatedAt   time.Time  `gorm:"created_at"`
-	UpdatedAt   *time.Time `gorm:"updated_at"`
+	ID                int64               `gorm:"id"`
+	Title             string              `gorm:"title"`
+	Slug              string              `gorm:"slug"`
+	Excerpt           string              `gorm:"excerpt"`
+	Description       string              `gorm:"description"`
+	BodyFormat        string              `gorm:"body_format"`
+	DescriptionSource string              `gorm:"description_source"`
+	Body              *string             `gorm:"body"`
+	TableOfContents   *string             `gorm:"table_of_contents"`
+	PlainText         string              `gorm:"plain_text"`
+	Image             string              `gorm:"image"`
+	Tags              string              `gorm:"tags"`
+	Status            string              `gorm:"status"`
+	CategoryID        int64               `gorm:"category_id"`
+	CreatedByID       *int64              `gorm:"created_by_id"`
+	User              User                `gorm:"foreignKey:CreatedByID"`
+	Category          Category            `gorm:"foreignKey:CategoryID"`
+	Attachments       []ContentAttachment `gorm:"foreignKey:ContentID"`
+	CreatedAt         time.Time           `gorm:"created_at"`
+	UpdatedAt         *time.Time          `gorm:"updated_at"`
+	Version           int64               `gorm:"version"`
+	DeletedAt         gorm.DeletedAt      `gorm:"deleted_at"`
 }
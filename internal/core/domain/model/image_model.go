@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type Image struct {
+	ID          int64      `gorm:"id"`
+	Url         string     `gorm:"url"`
+	FocalX      float64    `gorm:"focal_x"`
+	FocalY      float64    `gorm:"focal_y"`
+	CreatedByID int64      `gorm:"created_by_id"`
+	CreatedAt   time.Time  `gorm:"created_at"`
+	UpdatedAt   *time.Time `gorm:"updated_at"`
+}
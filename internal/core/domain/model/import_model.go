@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+type ImportJob struct {
+	ID          int64      `gorm:"id"`
+	Format      string     `gorm:"format"`
+	FileName    string     `gorm:"file_name"`
+	FilePath    string     `gorm:"file_path"`
+	SourceUrl   string     `gorm:"source_url"`
+	Status      string     `gorm:"status"`
+	Stats       string     `gorm:"stats"`
+	Errors      string     `gorm:"errors"`
+	Error       string     `gorm:"error"`
+	CreatedByID *int64     `gorm:"created_by_id"`
+	CreatedAt   time.Time  `gorm:"created_at"`
+	StartedAt   *time.Time `gorm:"started_at"`
+	FinishedAt  *time.Time `gorm:"finished_at"`
+	HeartbeatAt *time.Time `gorm:"heartbeat_at"`
+}
+
+type ImportMapping struct {
+	ID         int64     `gorm:"id"`
+	SourceType string    `gorm:"source_type"`
+	SourceKey  string    `gorm:"source_key"`
+	TargetID   *int64    `gorm:"target_id"`
+	TargetUrl  string    `gorm:"target_url"`
+	CreatedAt  time.Time `gorm:"created_at"`
+}
+
+type Redirect struct {
+	ID        int64     `gorm:"id"`
+	FromPath  string    `gorm:"from_path"`
+	ContentID int64     `gorm:"content_id"`
+	Content   Content   `gorm:"foreignKey:ContentID"`
+	CreatedAt time.Time `gorm:"created_at"`
+}
@@ -3,10 +3,12 @@ package model
 import "time"
 
 type User struct {
-	ID        int64      `gorm:"id"`
-	Name      string     `gorm:"name"`
-	Email     string     `gorm:"email"`
-	Password  string     `gorm:"password"`
-	CreatedAt time.Time  `gorm:"create_at"`
-	UpdatedAt *time.Time `gorm:"updated_at"`
+	ID            int64      `gorm:"id"`
+	Name          string     `gorm:"name"`
+	Email         string     `gorm:"email"`
+	Password      string     `gorm:"password"`
+	Role          string     `gorm:"role"`
+	DeactivatedAt *time.Time `gorm:"deactivated_at"`
+	CreatedAt     time.Time  `gorm:"create_at"`
+	UpdatedAt     *time.Time `gorm:"updated_at"`
 }
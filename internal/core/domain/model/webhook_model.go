@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+type Webhook struct {
+	ID             int64      `gorm:"id"`
+	Url            string     `gorm:"url"`
+	Secret         string     `gorm:"secret"`
+	Description    string     `gorm:"description"`
+	Events         string     `gorm:"events"`
+	Active         bool       `gorm:"active"`
+	FailureCount   int        `gorm:"failure_count"`
+	DisabledAt     *time.Time `gorm:"disabled_at"`
+	DisabledReason string     `gorm:"disabled_reason"`
+	CreatedByID    *int64     `gorm:"created_by_id"`
+	CreatedAt      time.Time  `gorm:"created_at"`
+	UpdatedAt      *time.Time `gorm:"updated_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64      `gorm:"id"`
+	WebhookID      int64      `gorm:"webhook_id"`
+	Webhook        Webhook    `gorm:"foreignKey:WebhookID"`
+	Event          string     `gorm:"event"`
+	Payload        string     `gorm:"payload"`
+	Status         string     `gorm:"status"`
+	Attempts       int        `gorm:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"next_attempt_at"`
+	LastAttemptAt  *time.Time `gorm:"last_attempt_at"`
+	ResponseStatus int        `gorm:"response_status"`
+	ResponseBody   string     `gorm:"response_body"`
+	Error          string     `gorm:"error"`
+	DurationMs     int64      `gorm:"duration_ms"`
+	RedeliveryOfID *int64     `gorm:"redelivery_of_id"`
+	CreatedAt      time.Time  `gorm:"created_at"`
+}
@@ -8,10 +8,11 @@ import (
 	"gonews/internal/core/domain/entity"
 	"gonews/lib/auth"
 	"gonews/lib/conv"
+	"gonews/lib/metrics"
 	"strconv"
 	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -31,20 +32,24 @@ type authService struct {
 func (a *authService) GetUserByEmail(ctx context.Context, req entity.LoginRequest) (*entity.AccessToken, error) {
 	result, err := a.authRepository.GetUserByEmail(ctx, req)
 	if err != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
 		code = "[SERVICE] GetUserByEmail - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
-	if checkPass := conv.CheckPasswordHash(req.Password, result.Password); !checkPass {
+	// deactivated users get the same answer as a wrong password
+	if checkPass := conv.CheckPasswordHash(req.Password, result.Password); !checkPass || result.DeactivatedAt != nil {
+		metrics.Logins.WithLabelValues(metrics.LoginFailure).Inc()
 		code = "[SERVICE] GetUserByEmail - 2"
 		err = errors.New("invalid email or password password")
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
 	jwtData := entity.JwtData{
 		UserID: float64(result.ID),
+		Role:   result.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			NotBefore: jwt.NewNumericDate(time.Now().Add(time.Hour * 2)),
 			ID:        strconv.FormatInt(result.ID, 10),
@@ -54,10 +59,11 @@ func (a *authService) GetUserByEmail(ctx context.Context, req entity.LoginReques
 	accessToken, expiresAt, err := a.jwtToken.GenerateToken(&jwtData)
 	if err != nil {
 		code = "[SERVICE] GetUserByEmail - 3"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
+	metrics.Logins.WithLabelValues(metrics.LoginSuccess).Inc()
 	resp := entity.AccessToken{
 		AccessToken: accessToken,
 		ExpiresAt:   expiresAt,
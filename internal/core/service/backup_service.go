@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/backup"
+	"gonews/lib/importer"
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+const backupBatchSize = 500
+
+var ErrRestoreNotEmpty = errors.New("restore needs an empty database: categories, contents and images must be empty")
+
+type BackupService interface {
+	Export(ctx context.Context, w io.Writer, filter backup.Filter) (*backup.Manifest, error)
+	Restore(ctx context.Context, path string) (*backup.Manifest, *entity.BackupRestoreEntity, error)
+}
+
+type backupService struct {
+	backupRepo repository.BackupRepository
+}
+
+// Export implements BackupService. Images are only exported with the whole
+// site, as they are not tied to a content.
+func (b *backupService) Export(ctx context.Context, w io.Writer, filter backup.Filter) (*backup.Manifest, error) {
+	archive := backup.NewWriter(w)
+	media := newMediaManifest()
+
+	err = archive.Create(backup.FileUsers)
+	if err == nil {
+		err = eachBatch(ctx, func(afterID int64) (int64, error) {
+			results, err := b.backupRepo.GetBackupUsers(ctx, afterID, backupBatchSize)
+			return writeBatch(archive, results, func(user entity.BackupUserEntity) int64 { return user.ID }, err)
+		})
+	}
+	if err != nil {
+		code = "[SERVICE] Export - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	err = archive.Create(backup.FileCategories)
+	if err == nil {
+		err = eachBatch(ctx, func(afterID int64) (int64, error) {
+			results, err := b.backupRepo.GetBackupCategories(ctx, filter, afterID, backupBatchSize)
+			return writeBatch(archive, results, func(category entity.BackupCategoryEntity) int64 { return category.ID }, err)
+		})
+	}
+	if err != nil {
+		code = "[SERVICE] Export - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	err = archive.Create(backup.FileContents)
+	if err == nil {
+		err = eachBatch(ctx, func(afterID int64) (int64, error) {
+			results, err := b.backupRepo.GetBackupContents(ctx, filter, afterID, backupBatchSize)
+			for _, content := range results {
+				media.addContent(content)
+			}
+			return writeBatch(archive, results, func(content entity.BackupContentEntity) int64 { return content.ID }, err)
+		})
+	}
+	if err != nil {
+		code = "[SERVICE] Export - 3"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if filter.IsZero() {
+		err = archive.Create(backup.FileImages)
+		if err == nil {
+			err = eachBatch(ctx, func(afterID int64) (int64, error) {
+				results, err := b.backupRepo.GetBackupImages(ctx, afterID, backupBatchSize)
+				for _, image := range results {
+					media.add(entity.BackupMediaEntity{Url: image.Url, Kind: entity.MediaKindImage, ImageID: image.ID})
+				}
+				return writeBatch(archive, results, func(image entity.BackupImageEntity) int64 { return image.ID }, err)
+			})
+		}
+		if err != nil {
+			code = "[SERVICE] Export - 4"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+	}
+
+	err = archive.Create(backup.FileMedia)
+	if err == nil {
+		for _, item := range media.items {
+			if err = archive.Write(item); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		code = "[SERVICE] Export - 5"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	manifest, err := archive.Close(filter)
+	if err != nil {
+		code = "[SERVICE] Export - 6"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Restore implements BackupService.
+func (b *backupService) Restore(ctx context.Context, path string) (*backup.Manifest, *entity.BackupRestoreEntity, error) {
+	archive, err := backup.Open(path)
+	if err != nil {
+		code = "[SERVICE] Restore - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, nil, err
+	}
+	defer archive.Close()
+
+	count, err := b.backupRepo.CountRestoredRows(ctx)
+	if err != nil {
+		code = "[SERVICE] Restore - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, nil, err
+	}
+	if count > 0 {
+		return nil, nil, ErrRestoreNotEmpty
+	}
+
+	result, err := b.backupRepo.Restore(ctx, archiveSource{archive})
+	if err != nil {
+		code = "[SERVICE] Restore - 3"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, nil, err
+	}
+
+	return &archive.Manifest, result, nil
+}
+
+// archiveSource implements repository.BackupSource over an open archive.
+type archiveSource struct {
+	r *backup.Reader
+}
+
+func (a archiveSource) Users(fn func(entity.BackupUserEntity) error) error {
+	return backup.Each(a.r, backup.FileUsers, fn)
+}
+
+func (a archiveSource) Categories(fn func(entity.BackupCategoryEntity) error) error {
+	return backup.Each(a.r, backup.FileCategories, fn)
+}
+
+func (a archiveSource) Contents(fn func(entity.BackupContentEntity) error) error {
+	return backup.Each(a.r, backup.FileContents, fn)
+}
+
+func (a archiveSource) Images(fn func(entity.BackupImageEntity) error) error {
+	return backup.Each(a.r, backup.FileImages, fn)
+}
+
+// mediaManifest collects the storage files an export points at, once per
+// url and kind.
+type mediaManifest struct {
+	seen  map[string]bool
+	items []entity.BackupMediaEntity
+}
+
+func newMediaManifest() *mediaManifest {
+	return &mediaManifest{seen: map[string]bool{}}
+}
+
+func (m *mediaManifest) add(item entity.BackupMediaEntity) {
+	key := item.Kind + " " + item.Url
+	if item.Url == "" || m.seen[key] {
+		return
+	}
+
+	m.seen[key] = true
+	m.items = append(m.items, item)
+}
+
+func (m *mediaManifest) addContent(content entity.BackupContentEntity) {
+	m.add(entity.BackupMediaEntity{Url: content.Image, Kind: entity.MediaKindContentImage, ContentID: content.ID})
+	for _, src := range importer.ImageSources(content.Description) {
+		m.add(entity.BackupMediaEntity{Url: src, Kind: entity.MediaKindInlineImage, ContentID: content.ID})
+	}
+	for _, attachment := range content.Attachments {
+		m.add(entity.BackupMediaEntity{Url: attachment.Url, Kind: entity.MediaKindAttachment, ContentID: content.ID})
+		m.add(entity.BackupMediaEntity{Url: attachment.PosterUrl, Kind: entity.MediaKindAttachmentPoster, ContentID: content.ID})
+	}
+}
+
+// eachBatch pages through a table by id until a batch comes back empty.
+func eachBatch(ctx context.Context, fn func(afterID int64) (int64, error)) error {
+	var afterID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lastID, err := fn(afterID)
+		if err != nil {
+			return err
+		}
+		if lastID == 0 {
+			return nil
+		}
+		afterID = lastID
+	}
+}
+
+// writeBatch writes a batch to the archive and returns the id of its last
+// record, or 0 when the batch was empty.
+func writeBatch[T any](archive *backup.Writer, records []T, id func(T) int64, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+
+	var lastID int64
+	for _, record := range records {
+		if err = archive.Write(record); err != nil {
+			return 0, err
+		}
+		lastID = id(record)
+	}
+
+	return lastID, nil
+}
+
+func NewBackupService(backupRepo repository.BackupRepository) BackupService {
+	return &backupService{backupRepo: backupRepo}
+}
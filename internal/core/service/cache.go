@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"gonews/internal/adapter/cache"
+	"gonews/internal/adapter/cdn"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultCacheTtl = time.Minute
+
+// Cache tags. Lists carry the tag of what they list, single values the tag
+// of their own row and of the rows they embed. The same names are sent to
+// CDNs as surrogate keys.
+const (
+	CacheTagContents   = "contents"
+	CacheTagCategories = "categories"
+)
+
+// ContentCacheTag tags values that show the content with id.
+func ContentCacheTag(id int64) string {
+	return "content:" + strconv.FormatInt(id, 10)
+}
+
+// CategoryCacheTag tags values that show the category with id.
+func CategoryCacheTag(id int64) string {
+	return "category:" + strconv.FormatInt(id, 10)
+}
+
+// purgeRequest lists the front-end routes that show what tags name, for CDNs
+// that purge by URL. Lists are purged without their query strings; the
+// surrogate keys reach the rest. Routes named by slugs or tags the tags do
+// not carry, such as the feeds of a category, are purged by prefix.
+func purgeRequest(tags []string) cdn.PurgeRequest {
+	req := cdn.PurgeRequest{Keys: tags}
+	seen := map[string]bool{}
+	add := func(list *[]string, values ...string) {
+		for _, value := range values {
+			if !seen[value] {
+				seen[value] = true
+				*list = append(*list, value)
+			}
+		}
+	}
+
+	for _, tag := range tags {
+		switch {
+		case tag == CacheTagContents:
+			add(&req.Paths, "/api/fe/contents", "/feeds/rss.xml", "/feeds/atom.xml", "/sitemap.xml", "/news-sitemap.xml")
+			add(&req.Prefixes, "/feeds/categories/", "/feeds/tags/", "/sitemaps/contents-")
+		case tag == CacheTagCategories:
+			add(&req.Paths, "/api/fe/categories", "/sitemap.xml", "/sitemaps/categories.xml")
+		case strings.HasPrefix(tag, "content:"):
+			add(&req.Paths, "/api/fe/contents/"+strings.TrimPrefix(tag, "content:"))
+		case strings.HasPrefix(tag, "category:"):
+			// every content of the category shows it, and its feeds may
+			// live under a slug it no longer has
+			add(&req.Prefixes, "/api/fe/contents/", "/feeds/categories/")
+		}
+	}
+	return req
+}
+
+// readCache caches the results of front-end reads. Concurrent misses for the
+// same key share a single load, so a burst of traffic on a cold key reaches
+// the database once.
+//
+// A load that overlaps an invalidation of one of its tags may have read the
+// rows from before the write, so its value is not stored. Invalidations from
+// other instances are not seen here; such values expire with the TTL.
+type readCache struct {
+	cache  cache.Cache
+	purger cdn.CachePurger
+	ttl    time.Duration
+	group  singleflight.Group
+	marks  *tagMarks
+}
+
+// tagMarks remembers which tags were invalidated while loads were running.
+// Every service has its own readCache over the same store, so they share
+// the marks of the process.
+type tagMarks struct {
+	// mu is held for writing while tags are marked, and for reading from the
+	// check of a loaded value to its store, so an invalidation lands either
+	// before the check or after the store
+	mu          sync.RWMutex
+	clock       uint64
+	invalidated map[string]uint64
+	loading     atomic.Int64
+}
+
+var processTagMarks = &tagMarks{invalidated: map[string]uint64{}}
+
+func newReadCache(c cache.Cache, purger cdn.CachePurger, ttl time.Duration) *readCache {
+	if c == nil {
+		c = cache.NewNoopCache()
+	}
+	if purger == nil {
+		purger = cdn.NewNoopPurger()
+	}
+	if ttl <= 0 {
+		ttl = defaultCacheTtl
+	}
+	return &readCache{cache: c, purger: purger, ttl: ttl, marks: processTagMarks}
+}
+
+// start returns the clock a load starts at. The load is counted until its
+// caller calls done.
+func (m *tagMarks) start() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.loading.Add(1)
+	return m.clock
+}
+
+func (m *tagMarks) done() {
+	m.loading.Add(-1)
+}
+
+// mark records that tags were invalidated.
+func (m *tagMarks) mark(tags []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock++
+	if m.loading.Load() == 0 {
+		// no load started before now, so older marks can go
+		clear(m.invalidated)
+		return
+	}
+	for _, tag := range tags {
+		m.invalidated[tag] = m.clock
+	}
+}
+
+// store caches a value loaded since start, unless one of its tags was
+// invalidated in the meantime.
+func (rc *readCache) store(ctx context.Context, start uint64, key string, data []byte, tags []string) error {
+	rc.marks.mu.RLock()
+	defer rc.marks.mu.RUnlock()
+
+	for _, tag := range tags {
+		if rc.marks.invalidated[tag] > start {
+			return nil
+		}
+	}
+
+	return rc.cache.Set(ctx, key, data, rc.ttl, tags)
+}
+
+// cached returns the value under key, loading and storing it on a miss. A
+// failing cache is logged and bypassed, never failing the read.
+func cached[T any](ctx context.Context, rc *readCache, key string, tags func(T) []string, load func(context.Context) (T, error)) (T, error) {
+	var value T
+
+	data, ok, err := rc.cache.Get(ctx, key)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Str("key", key).Msg("[SERVICE] cached - 1")
+	}
+	if ok {
+		if err = json.Unmarshal(data, &value); err == nil {
+			return value, nil
+		}
+		zerolog.Ctx(ctx).Error().Err(err).Str("key", key).Msg("[SERVICE] cached - 2")
+	}
+
+	// the load is shared with other requests, so one of them going away
+	// must not cancel it for the rest
+	shared, err, _ := rc.group.Do(key, func() (interface{}, error) {
+		loadCtx := context.WithoutCancel(ctx)
+		start := rc.marks.start()
+		defer rc.marks.done()
+
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(loaded)
+		if err == nil {
+			err = rc.store(loadCtx, start, key, data, tags(loaded))
+		}
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str("key", key).Msg("[SERVICE] cached - 3")
+		}
+
+		return loaded, nil
+	})
+	if err != nil {
+		return value, err
+	}
+
+	return shared.(T), nil
+}
+
+// invalidate drops cached reads after a write, here and at the CDN.
+// Failures are logged only: the write went through and the values expire on
+// their own.
+func (rc *readCache) invalidate(ctx context.Context, tags ...string) {
+	rc.marks.mark(tags)
+
+	if err := rc.cache.InvalidateTags(ctx, tags...); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Strs("tags", tags).Msg("[SERVICE] invalidate - 1")
+	}
+
+	if err := rc.purger.Purge(ctx, purgeRequest(tags)); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Strs("tags", tags).Msg("[SERVICE] invalidate - 2")
+	}
+}
@@ -2,23 +2,33 @@ package service
 
 import (
 	"context"
+	"gonews/internal/adapter/cache"
+	"gonews/internal/adapter/cdn"
 	"gonews/internal/adapter/repository"
 	"gonews/internal/core/domain/entity"
 	"gonews/lib/conv"
+	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
 )
 
 type CategoryService interface {
 	GetCategories(ctx context.Context) ([]entity.CategoryEntity, error)
+	GetPublicCategories(ctx context.Context) ([]entity.CategoryEntity, error)
 	GetCategoryByID(ctx context.Context, id int64) (*entity.CategoryEntity, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*entity.CategoryEntity, error)
 	CreateCategory(ctx context.Context, req entity.CategoryEntity) error
 	EditCategory(ctx context.Context, req entity.CategoryEntity) error
-	DeleteCategory(ctx context.Context, id int64) error
+	DeleteCategory(ctx context.Context, id int64, version int64) error
+	GetTrashedCategories(ctx context.Context) ([]entity.CategoryEntity, error)
+	RestoreCategory(ctx context.Context, id int64) error
+	PurgeCategory(ctx context.Context, id int64) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
 
 type categoryService struct {
 	categoryRepository repository.CategoryRepository
+	cache              *readCache
 }
 
 // CreateCategory implements CategoryService.
@@ -29,22 +39,26 @@ func (c *categoryService) CreateCategory(ctx context.Context, req entity.Categor
 	err =  c.categoryRepository.CreateCategory(ctx, req)
 	if err != nil {
 		code = "[Service] GetCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
+	c.cache.invalidate(ctx, CacheTagCategories)
+
 	return nil
 }
 
 // DeleteCategory implements CategoryService.
-func (c *categoryService) DeleteCategory(ctx context.Context, id int64) error {
-	err := c.categoryRepository.DeleteCategory(ctx, id)
+func (c *categoryService) DeleteCategory(ctx context.Context, id int64, version int64) error {
+	err := c.categoryRepository.DeleteCategory(ctx, id, version)
 	if err != nil {
 		code = "[SERVICE] DeleteCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
+	c.cache.invalidate(ctx, CacheTagCategories, CacheTagContents, CategoryCacheTag(id))
+
 	return nil
 }
 
@@ -53,7 +67,7 @@ func (c *categoryService) EditCategory(ctx context.Context, req entity.CategoryE
 	categoryData, err := c.categoryRepository.GetCategoryByID(ctx, req.ID)
 	if err != nil {
 		code = "[SERVICE] EditCategoryByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 	slug := conv.GenerateSlug(req.Title)
@@ -65,9 +79,11 @@ func (c *categoryService) EditCategory(ctx context.Context, req entity.CategoryE
 	err = c.categoryRepository.EditCategory(ctx, req)
 	if err != nil {
 		code = "[SERVICE] EditCategoryByID - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
+
+	c.cache.invalidate(ctx, CacheTagCategories, CacheTagContents, CategoryCacheTag(req.ID))
 	return nil
 }
 
@@ -76,26 +92,112 @@ func (c *categoryService) GetCategories(ctx context.Context) ([]entity.CategoryE
 	result, err := c.categoryRepository.GetCategories(ctx)
 	if err != nil {
 		code = "[Service] GetCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
 	return result, err
 }
 
+// GetPublicCategories implements CategoryService.
+func (c *categoryService) GetPublicCategories(ctx context.Context) ([]entity.CategoryEntity, error) {
+	tags := func([]entity.CategoryEntity) []string {
+		return []string{CacheTagCategories}
+	}
+
+	return cached(ctx, c.cache, "fe:categories", tags, func(ctx context.Context) ([]entity.CategoryEntity, error) {
+		result, err := c.categoryRepository.GetCategories(ctx)
+		if err != nil {
+			code = "[SERVICE] GetPublicCategories - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+
+		return result, nil
+	})
+}
+
 // GetCategoryByID implements CategoryService.
 func (c *categoryService) GetCategoryByID(ctx context.Context, id int64) (*entity.CategoryEntity, error) {
 	result, err := c.categoryRepository.GetCategoryByID(ctx, id)
 	if err != nil {
 		code = "[Service] GetCategory - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCategoryBySlug implements CategoryService.
+func (c *categoryService) GetCategoryBySlug(ctx context.Context, slug string) (*entity.CategoryEntity, error) {
+	result, err := c.categoryRepository.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		code = "[Service] GetCategoryBySlug - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 	return result, nil
 }
 
-func NewCategoryService(categoryRepo repository.CategoryRepository) CategoryService {
+// GetTrashedCategories implements CategoryService.
+func (c *categoryService) GetTrashedCategories(ctx context.Context) ([]entity.CategoryEntity, error) {
+	results, err := c.categoryRepository.GetTrashedCategories(ctx)
+	if err != nil {
+		code = "[SERVICE] GetTrashedCategories - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// RestoreCategory implements CategoryService.
+func (c *categoryService) RestoreCategory(ctx context.Context, id int64) error {
+	err := c.categoryRepository.RestoreCategory(ctx, id)
+	if err != nil {
+		code = "[SERVICE] RestoreCategory - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	c.cache.invalidate(ctx, CacheTagCategories, CacheTagContents, CategoryCacheTag(id))
+
+	return nil
+}
+
+// PurgeCategory implements CategoryService.
+func (c *categoryService) PurgeCategory(ctx context.Context, id int64) error {
+	err := c.categoryRepository.PurgeCategory(ctx, id)
+	if err != nil {
+		code = "[SERVICE] PurgeCategory - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	c.cache.invalidate(ctx, CacheTagCategories, CacheTagContents, CategoryCacheTag(id))
+
+	return nil
+}
+
+// PurgeTrash implements CategoryService.
+func (c *categoryService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := c.categoryRepository.PurgeTrashedCategories(ctx, before)
+	if err != nil {
+		code = "[SERVICE] PurgeTrash - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	if purged > 0 {
+		c.cache.invalidate(ctx, CacheTagCategories, CacheTagContents)
+	}
+
+	return purged, nil
+}
+
+func NewCategoryService(categoryRepo repository.CategoryRepository, cacheStore cache.Cache, purger cdn.CachePurger, cacheTtl time.Duration) CategoryService {
 	return &categoryService{
 		categoryRepository: categoryRepo,
+		cache:              newReadCache(cacheStore, purger, cacheTtl),
 	}
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gonews/config"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const defaultLockTtl = 2 * time.Minute
+
+var (
+	ErrLockHeld    = errors.New("content is being edited by another user")
+	ErrLockNotHeld = errors.New("edit lock is no longer held by you")
+)
+
+type ContentLockService interface {
+	GetLock(ctx context.Context, contentID int64) (*entity.ContentLockEntity, error)
+	GetLocks(ctx context.Context, contentIDs []int64) (map[int64]entity.ContentLockEntity, error)
+	AcquireLock(ctx context.Context, contentID int64, userID int64, takeOver bool) (*entity.ContentLockEntity, error)
+	HeartbeatLock(ctx context.Context, contentID int64, userID int64) (*entity.ContentLockEntity, error)
+	ReleaseLock(ctx context.Context, contentID int64, userID int64) (*entity.ContentLockEntity, error)
+	ForceReleaseLock(ctx context.Context, contentID int64) error
+	CheckLock(ctx context.Context, contentID int64, userID int64, takeOver bool) (*entity.ContentLockEntity, error)
+}
+
+type contentLockService struct {
+	lockRepo repository.ContentLockRepository
+	cfg      *config.Config
+}
+
+// GetLock implements ContentLockService.
+func (c *contentLockService) GetLock(ctx context.Context, contentID int64) (*entity.ContentLockEntity, error) {
+	result, err := c.lockRepo.GetLock(ctx, contentID)
+	if err != nil {
+		code = "[SERVICE] GetLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetLocks implements ContentLockService.
+func (c *contentLockService) GetLocks(ctx context.Context, contentIDs []int64) (map[int64]entity.ContentLockEntity, error) {
+	results, err := c.lockRepo.GetLocks(ctx, contentIDs)
+	if err != nil {
+		code = "[SERVICE] GetLocks - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AcquireLock implements ContentLockService. When someone else holds the
+// lock it returns their lock together with ErrLockHeld.
+func (c *contentLockService) AcquireLock(ctx context.Context, contentID int64, userID int64, takeOver bool) (*entity.ContentLockEntity, error) {
+	now := time.Now()
+	result, err := c.lockRepo.AcquireLock(ctx, entity.ContentLockEntity{
+		ContentID:  contentID,
+		User:       entity.UserEntity{ID: userID},
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(c.ttl()),
+	}, takeOver)
+	if err != nil {
+		code = "[SERVICE] AcquireLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if result.User.ID != userID {
+		return result, ErrLockHeld
+	}
+
+	return result, nil
+}
+
+// HeartbeatLock implements ContentLockService. A lock that was taken over
+// or force-released fails with ErrLockNotHeld and the current lock, if any.
+func (c *contentLockService) HeartbeatLock(ctx context.Context, contentID int64, userID int64) (*entity.ContentLockEntity, error) {
+	now := time.Now()
+	result, err := c.lockRepo.RefreshLock(ctx, entity.ContentLockEntity{
+		ContentID:   contentID,
+		User:        entity.UserEntity{ID: userID},
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(c.ttl()),
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		current, err := c.lockRepo.GetLock(ctx, contentID)
+		if err != nil {
+			code = "[SERVICE] HeartbeatLock - 2"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+
+		return current, ErrLockNotHeld
+	}
+	if err != nil {
+		code = "[SERVICE] HeartbeatLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReleaseLock implements ContentLockService. Releasing a lock nobody holds
+// is a no-op; releasing someone else's fails with ErrLockHeld.
+func (c *contentLockService) ReleaseLock(ctx context.Context, contentID int64, userID int64) (*entity.ContentLockEntity, error) {
+	current, err := c.lockRepo.GetLock(ctx, contentID)
+	if err != nil {
+		code = "[SERVICE] ReleaseLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if current != nil && current.User.ID != userID {
+		return current, ErrLockHeld
+	}
+
+	err = c.lockRepo.ReleaseLock(ctx, contentID, userID)
+	if err != nil {
+		code = "[SERVICE] ReleaseLock - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+// ForceReleaseLock implements ContentLockService.
+func (c *contentLockService) ForceReleaseLock(ctx context.Context, contentID int64) error {
+	err = c.lockRepo.ForceReleaseLock(ctx, contentID)
+	if err != nil {
+		code = "[SERVICE] ForceReleaseLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// CheckLock implements ContentLockService. It guards writes: a content
+// nobody is editing, or that the user holds, passes; one held by someone
+// else fails with ErrLockHeld unless takeOver moves the lock to the user.
+func (c *contentLockService) CheckLock(ctx context.Context, contentID int64, userID int64, takeOver bool) (*entity.ContentLockEntity, error) {
+	if takeOver {
+		return c.AcquireLock(ctx, contentID, userID, true)
+	}
+
+	current, err := c.lockRepo.GetLock(ctx, contentID)
+	if err != nil {
+		code = "[SERVICE] CheckLock - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if current != nil && current.User.ID != userID {
+		return current, ErrLockHeld
+	}
+
+	return current, nil
+}
+
+func (c *contentLockService) ttl() time.Duration {
+	if c.cfg.EditLock.TtlSeconds > 0 {
+		return time.Duration(c.cfg.EditLock.TtlSeconds) * time.Second
+	}
+
+	return defaultLockTtl
+}
+
+func NewContentLockService(lockRepo repository.ContentLockRepository, cfg *config.Config) ContentLockService {
+	return &contentLockService{
+		lockRepo: lockRepo,
+		cfg:      cfg,
+	}
+}
@@ -2,59 +2,381 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"gonews/config"
+	"gonews/internal/adapter/cache"
+	"gonews/internal/adapter/cdn"
 	"gonews/internal/adapter/imagekit"
 	"gonews/internal/adapter/repository"
 	"gonews/internal/core/domain/entity"
+	"gonews/lib/blocks"
+	"gonews/lib/conv"
+	"gonews/lib/markdown"
+	"gonews/lib/mediaprobe"
+	"gonews/lib/preview"
+	"gonews/lib/tracing"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPreviewExpiry = 24 * time.Hour
+	maxBulkItems         = 1000
+)
+
+var (
+	ErrBulkTargetRequired   = errors.New("bulk action needs either ids or a filter")
+	ErrBulkTooManyItems     = fmt.Errorf("bulk action is limited to %d contents", maxBulkItems)
+	ErrBulkCategoryRequired = errors.New("bulk action needs a category_id")
+	ErrBulkTagsRequired     = errors.New("bulk action needs tags")
 )
 
 type ContentService interface {
 	GetContents(ctx context.Context, query entity.QueryString) ([]entity.ContentEntity, int64, int64, error)
 	GetContentById(ctx context.Context, id int64) (*entity.ContentEntity, error)
+	GetPublishedContentById(ctx context.Context, id int64) (*entity.ContentEntity, error)
+	GetPublishedContents(ctx context.Context, query entity.QueryString) ([]entity.ContentEntity, int64, int64, error)
+	CreatePreview(ctx context.Context, id int64, expiresIn time.Duration) (*entity.PreviewEntity, error)
+	GetContentByPreviewToken(ctx context.Context, token string) (*entity.ContentEntity, error)
 	CreateContent(ctx context.Context, req entity.ContentEntity) error
 	UpdateContent(ctx context.Context, req entity.ContentEntity) error
-	DeleteContent(ctx context.Context, id int64) error
+	DeleteContent(ctx context.Context, id int64, version int64) error
+	RestoreContent(ctx context.Context, id int64) error
+	PurgeContent(ctx context.Context, id int64) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	BulkContents(ctx context.Context, req entity.ContentBulkEntity) (*entity.ContentBulkResultEntity, error)
 	UploadImageR2(ctx context.Context, req entity.FileUploadEntity) (string, error)
+	ConvertLegacyDescriptions(ctx context.Context, dryRun bool) (int, error)
+
+	CreateAttachment(ctx context.Context, req entity.AttachmentUploadEntity) (*entity.ContentAttachmentEntity, error)
+	DeleteAttachment(ctx context.Context, contentID int64, id int64) error
 }
 
 type contentService struct {
 	contentRepo repository.ContentRepository
+	tx          repository.Transactor
 	cfg         *config.Config
 	ik          imagekit.ImageKitAdapter
+	cache       *readCache
+	webhooks    WebhookService
 }
 
 // CreateContent implements ContentService.
 func (c *contentService) CreateContent(ctx context.Context, req entity.ContentEntity) error {
-	err = c.contentRepo.CreateContent(ctx, req)
+	ctx, span := tracing.Start(ctx, "ContentService.CreateContent")
+	defer span.End()
+
+	req.Slug = conv.GenerateSlug(req.Title)
+	if req.Slug == "" {
+		req.Slug = "content"
+	}
+
+	if err = prepareContentBody(&req); err != nil {
+		code = "[SERVICE] CreateContent - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		id, err := c.contentRepo.CreateContent(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		created := contentForEvent(ctx, c.contentRepo, id)
+		return publishContentEvents(ctx, c.webhooks, created, append([]string{entity.WebhookEventContentCreated}, statusEvents("", created.Status)...)...)
+	})
 	if err != nil {
 		code = "[SERVICE] CreateContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
+	c.cache.invalidate(ctx, CacheTagContents)
+
 	return nil
 }
 
 // DeleteContent implements ContentService.
-func (c *contentService) DeleteContent(ctx context.Context, id int64) error {
-	err = c.contentRepo.DeleteContent(ctx, id)
+func (c *contentService) DeleteContent(ctx context.Context, id int64, version int64) error {
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		// loaded first, as trashed contents are out of reach afterwards
+		deleted := contentForEvent(ctx, c.contentRepo, id)
+
+		if err := c.contentRepo.DeleteContent(ctx, id, version); err != nil {
+			return err
+		}
+
+		return publishContentEvents(ctx, c.webhooks, deleted, entity.WebhookEventContentDeleted)
+	})
 	if err != nil {
 		code = "[SERVICE] DeleteContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
+	c.cache.invalidate(ctx, CacheTagContents, ContentCacheTag(id))
+
 	return nil
 }
 
+// RestoreContent implements ContentService.
+func (c *contentService) RestoreContent(ctx context.Context, id int64) error {
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := c.contentRepo.RestoreContent(ctx, id); err != nil {
+			return err
+		}
+
+		return publishContentEvents(ctx, c.webhooks, contentForEvent(ctx, c.contentRepo, id), entity.WebhookEventContentRestored)
+	})
+	if err != nil {
+		code = "[SERVICE] RestoreContent - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	c.cache.invalidate(ctx, CacheTagContents, ContentCacheTag(id))
+
+	return nil
+}
+
+// PurgeContent implements ContentService.
+func (c *contentService) PurgeContent(ctx context.Context, id int64) error {
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := c.contentRepo.PurgeContent(ctx, id); err != nil {
+			return err
+		}
+
+		return publishContentEvents(ctx, c.webhooks, entity.ContentEntity{ID: id}, entity.WebhookEventContentPurged)
+	})
+	if err != nil {
+		code = "[SERVICE] PurgeContent - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	c.cache.invalidate(ctx, CacheTagContents, ContentCacheTag(id))
+
+	return nil
+}
+
+// PurgeTrash implements ContentService.
+func (c *contentService) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := c.contentRepo.PurgeTrashedContents(ctx, before)
+	if err != nil {
+		code = "[SERVICE] PurgeTrash - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	if purged > 0 {
+		c.cache.invalidate(ctx, CacheTagContents)
+	}
+
+	return purged, nil
+}
+
+// BulkContents implements ContentService.
+func (c *contentService) BulkContents(ctx context.Context, req entity.ContentBulkEntity) (*entity.ContentBulkResultEntity, error) {
+	switch req.Action {
+	case entity.BulkActionSetCategory:
+		if req.CategoryID <= 0 {
+			return nil, ErrBulkCategoryRequired
+		}
+	case entity.BulkActionAddTags, entity.BulkActionRemoveTags:
+		if len(req.Tags) == 0 {
+			return nil, ErrBulkTagsRequired
+		}
+	}
+
+	if len(req.IDs) == 0 {
+		if req.Filter == nil {
+			return nil, ErrBulkTargetRequired
+		}
+
+		req.IDs, err = c.contentRepo.GetContentIDs(ctx, *req.Filter, maxBulkItems+1)
+		if err != nil {
+			code = "[SERVICE] BulkContents - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+	}
+
+	ids := []int64{}
+	seen := map[int64]bool{}
+	for _, id := range req.IDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.IDs = ids
+
+	if len(req.IDs) > maxBulkItems {
+		return nil, ErrBulkTooManyItems
+	}
+
+	var result *entity.ContentBulkResultEntity
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = c.contentRepo.BulkUpdateContents(ctx, req)
+		if err != nil || !result.Applied {
+			return err
+		}
+
+		return c.publishBulkEvents(ctx, req.Action, result.Items)
+	})
+	if err != nil {
+		code = "[SERVICE] BulkContents - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if result.Applied {
+		tags := []string{CacheTagContents}
+		for _, item := range result.Items {
+			if item.Error == "" {
+				tags = append(tags, ContentCacheTag(item.ID))
+			}
+		}
+		c.cache.invalidate(ctx, tags...)
+	}
+
+	return result, nil
+}
+
 // GetContentById implements ContentService.
 func (c *contentService) GetContentById(ctx context.Context, id int64) (*entity.ContentEntity, error) {
 	result, err := c.contentRepo.GetContentById(ctx, id)
 	if err != nil {
 		code = "[SERVICE] GetContentByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetPublishedContentById implements ContentService.
+func (c *contentService) GetPublishedContentById(ctx context.Context, id int64) (*entity.ContentEntity, error) {
+	tags := func(result *entity.ContentEntity) []string {
+		return []string{ContentCacheTag(result.ID), CategoryCacheTag(result.CategoryID)}
+	}
+
+	return cached(ctx, c.cache, "fe:content:"+strconv.FormatInt(id, 10), tags, func(ctx context.Context) (*entity.ContentEntity, error) {
+		result, err := c.contentRepo.GetContentById(ctx, id)
+		if err != nil {
+			code = "[SERVICE] GetPublishedContentById - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+
+		// drafts are only reachable through a preview token
+		if result.Status != "PUBLISH" {
+			code = "[SERVICE] GetPublishedContentById - 2"
+			zerolog.Ctx(ctx).Error().Err(gorm.ErrRecordNotFound).Msg(code)
+			return nil, gorm.ErrRecordNotFound
+		}
+
+		return result, nil
+	})
+}
+
+// publishedContentsPage is what GetPublishedContents keeps in the cache.
+type publishedContentsPage struct {
+	Contents   []entity.ContentEntity
+	TotalData  int64
+	TotalPages int64
+}
+
+// GetPublishedContents implements ContentService.
+func (c *contentService) GetPublishedContents(ctx context.Context, query entity.QueryString) ([]entity.ContentEntity, int64, int64, error) {
+	query.Status = "PUBLISH"
+	query.Trashed = false
+
+	key, err := json.Marshal(query)
+	if err != nil {
+		code = "[SERVICE] GetPublishedContents - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, 0, 0, err
+	}
+	sum := sha256.Sum256(key)
+
+	tags := func(page publishedContentsPage) []string {
+		return []string{CacheTagContents}
+	}
+
+	page, err := cached(ctx, c.cache, "fe:contents:"+hex.EncodeToString(sum[:]), tags, func(ctx context.Context) (publishedContentsPage, error) {
+		results, totalData, totalPages, err := c.contentRepo.GetContents(ctx, query)
+		if err != nil {
+			code = "[SERVICE] GetPublishedContents - 2"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return publishedContentsPage{}, err
+		}
+
+		return publishedContentsPage{Contents: results, TotalData: totalData, TotalPages: totalPages}, nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return page.Contents, page.TotalData, page.TotalPages, nil
+}
+
+// CreatePreview implements ContentService.
+func (c *contentService) CreatePreview(ctx context.Context, id int64, expiresIn time.Duration) (*entity.PreviewEntity, error) {
+	if _, err = c.contentRepo.GetContentById(ctx, id); err != nil {
+		code = "[SERVICE] CreatePreview - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if expiresIn <= 0 {
+		expiresIn = defaultPreviewExpiry
+		if c.cfg.Preview.ExpireInHours > 0 {
+			expiresIn = time.Duration(c.cfg.Preview.ExpireInHours) * time.Hour
+		}
+	}
+
+	expiresAt := time.Now().Add(expiresIn)
+	token, err := preview.Sign(c.cfg.Preview.SigningKey, id, expiresAt)
+	if err != nil {
+		code = "[SERVICE] CreatePreview - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return &entity.PreviewEntity{
+		ContentID: id,
+		Token:     token,
+		Url:       fmt.Sprintf("%s/preview/%s", strings.TrimRight(c.cfg.App.FrontendUrl, "/"), token),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// GetContentByPreviewToken implements ContentService.
+func (c *contentService) GetContentByPreviewToken(ctx context.Context, token string) (*entity.ContentEntity, error) {
+	id, err := preview.Parse(c.cfg.Preview.SigningKey, token)
+	if err != nil {
+		code = "[SERVICE] GetContentByPreviewToken - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	result, err := c.contentRepo.GetContentById(ctx, id)
+	if err != nil {
+		code = "[SERVICE] GetContentByPreviewToken - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 
@@ -66,7 +388,7 @@ func (c *contentService) GetContents(ctx context.Context, query entity.QueryStri
 	results, totalData, totalPages, err := c.contentRepo.GetContents(ctx, query)
 	if err != nil {
 		code = "[SERVICE] GetContents - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, 0, 0, err
 	}
 
@@ -75,31 +397,384 @@ func (c *contentService) GetContents(ctx context.Context, query entity.QueryStri
 
 // UpdateContent implements ContentService.
 func (c *contentService) UpdateContent(ctx context.Context, req entity.ContentEntity) error {
-	err = c.contentRepo.UpdateContent(ctx, req)
+	ctx, span := tracing.Start(ctx, "ContentService.UpdateContent")
+	defer span.End()
+
+	if err = prepareContentBody(&req); err != nil {
+		code = "[SERVICE] UpdateContent - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	err = c.tx.Transaction(ctx, func(ctx context.Context) error {
+		previousStatus := contentForEvent(ctx, c.contentRepo, req.ID).Status
+
+		if err := c.contentRepo.UpdateContent(ctx, req); err != nil {
+			return err
+		}
+
+		updated := contentForEvent(ctx, c.contentRepo, req.ID)
+		return publishContentEvents(ctx, c.webhooks, updated, append([]string{entity.WebhookEventContentUpdated}, statusEvents(previousStatus, updated.Status)...)...)
+	})
 	if err != nil {
 		code = "[SERVICE] UpdateContent - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
+	c.cache.invalidate(ctx, CacheTagContents, ContentCacheTag(req.ID))
+
 	return nil
 }
 
 // UploadImageR2 implements ContentService.
 func (c *contentService) UploadImageR2(ctx context.Context, req entity.FileUploadEntity) (string, error) {
-	urlImage, err := c.ik.UploadImage(&req)
+	urlImage, err := c.ik.UploadImage(ctx, &req)
 	if err != nil {
-		log.Errorw("[SERVICE] UploadImageImageKit - 1", err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg("[SERVICE] UploadImageImageKit - 1")
 		return "", err 
 	}
 	return urlImage, nil
 }
 
+// ConvertLegacyDescriptions implements ContentService.
+func (c *contentService) ConvertLegacyDescriptions(ctx context.Context, dryRun bool) (int, error) {
+	converted := 0
+	var afterID int64
+	for {
+		results, err := c.contentRepo.GetContentsWithoutBody(ctx, afterID, 100)
+		if err != nil {
+			code = "[SERVICE] ConvertLegacyDescriptions - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return converted, err
+		}
+
+		if len(results) == 0 {
+			return converted, nil
+		}
+
+		for _, result := range results {
+			afterID = result.ID
+
+			result.Blocks, err = blocks.FromHTML(result.Description)
+			if err != nil {
+				code = "[SERVICE] ConvertLegacyDescriptions - 2"
+				zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+				continue
+			}
+			result.BodyFormat = entity.BodyFormatBlocks
+			if err = prepareContentBody(&result); err != nil {
+				code = "[SERVICE] ConvertLegacyDescriptions - 4"
+				zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+				continue
+			}
+
+			if !dryRun {
+				if err = c.contentRepo.UpdateContentBody(ctx, result); err != nil {
+					code = "[SERVICE] ConvertLegacyDescriptions - 3"
+					zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+					return converted, err
+				}
+				c.cache.invalidate(ctx, ContentCacheTag(result.ID))
+			}
+			converted++
+		}
+	}
+}
+
+// contentForEvent loads the content an event is about, falling back to just
+// its ID when it is gone.
+func contentForEvent(ctx context.Context, contentRepo repository.ContentRepository, id int64) entity.ContentEntity {
+	result, err := contentRepo.GetContentById(ctx, id)
+	if err != nil {
+		return entity.ContentEntity{ID: id}
+	}
+	return *result
+}
+
+// publishContentEvents queues the events of a change. It runs in the
+// transaction of the change, so its events are queued if and only if the
+// change is committed.
+func publishContentEvents(ctx context.Context, webhooks WebhookService, content entity.ContentEntity, events ...string) error {
+	for _, event := range events {
+		if err := webhooks.PublishContentEvent(ctx, event, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publishBulkEvents queues the events of the items a bulk action changed,
+// loading their contents in one query. Deleted contents are out of reach
+// and only carry their ID and title.
+func (c *contentService) publishBulkEvents(ctx context.Context, action string, items []entity.ContentBulkItemEntity) error {
+	contents := map[int64]entity.ContentEntity{}
+	if action != entity.BulkActionDelete {
+		ids := []int64{}
+		for _, item := range items {
+			if item.Status == entity.BulkItemSucceeded {
+				ids = append(ids, item.ID)
+			}
+		}
+
+		results, err := c.contentRepo.GetContentsByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			contents[result.ID] = result
+		}
+	}
+
+	events := []string{entity.WebhookEventContentUpdated}
+	switch action {
+	case entity.BulkActionDelete:
+		events = []string{entity.WebhookEventContentDeleted}
+	case entity.BulkActionPublish:
+		events = append(events, entity.WebhookEventContentPublished)
+	case entity.BulkActionUnpublish:
+		events = append(events, entity.WebhookEventContentUnpublished)
+	}
+
+	for _, item := range items {
+		if item.Status != entity.BulkItemSucceeded {
+			continue
+		}
+
+		content, ok := contents[item.ID]
+		if !ok {
+			content = entity.ContentEntity{ID: item.ID, Title: item.Title}
+		}
+		if err := publishContentEvents(ctx, c.webhooks, content, events...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statusEvents names the events of a status change: going to PUBLISH
+// publishes a content, leaving it unpublishes it.
+func statusEvents(before string, after string) []string {
+	switch {
+	case after == "PUBLISH" && before != "PUBLISH":
+		return []string{entity.WebhookEventContentPublished}
+	case before == "PUBLISH" && after != "PUBLISH":
+		return []string{entity.WebhookEventContentUnpublished}
+	default:
+		return nil
+	}
+}
+
+// prepareContentBody renders the submitted body to sanitized HTML according
+// to its format: blocks are validated and rendered, Markdown is rendered and
+// kept as source, and plain HTML goes through the sanitizer. A plain text
+// copy and the table of contents are derived from the result.
+func prepareContentBody(req *entity.ContentEntity) error {
+	if req.BodyFormat == "" {
+		req.BodyFormat = entity.BodyFormatHTML
+		if len(req.Blocks) > 0 {
+			req.BodyFormat = entity.BodyFormatBlocks
+		}
+	}
+
+	switch req.BodyFormat {
+	case entity.BodyFormatBlocks:
+		if err := blocks.Validate(req.Blocks); err != nil {
+			return err
+		}
+		req.DescriptionSource = ""
+		req.Description = blocks.RenderHTML(req.Blocks)
+		req.PlainText = blocks.RenderText(req.Blocks)
+	case entity.BodyFormatMarkdown:
+		rendered, err := markdown.Render(req.Description)
+		if err != nil {
+			return err
+		}
+		req.DescriptionSource = req.Description
+		req.Blocks = nil
+		req.Description = blocks.SanitizeHTML(rendered)
+	default:
+		req.BodyFormat = entity.BodyFormatHTML
+		req.Blocks = nil
+		req.Description = blocks.SanitizeHTML(req.Description)
+		req.DescriptionSource = req.Description
+	}
+
+	description, toc, err := blocks.TableOfContents(req.Description)
+	if err != nil {
+		return err
+	}
+	req.Description = description
+	req.TableOfContents = toc
+
+	if req.BodyFormat != entity.BodyFormatBlocks {
+		converted, err := blocks.FromHTML(req.Description)
+		if err != nil {
+			return err
+		}
+		req.PlainText = blocks.RenderText(converted)
+	}
+
+	return nil
+}
+
+// CreateAttachment implements ContentService.
+func (c *contentService) CreateAttachment(ctx context.Context, req entity.AttachmentUploadEntity) (*entity.ContentAttachmentEntity, error) {
+	ctx, span := tracing.Start(ctx, "ContentService.CreateAttachment")
+	defer span.End()
+
+	if _, err = c.contentRepo.GetContentById(ctx, req.ContentID); err != nil {
+		code = "[SERVICE] CreateAttachment - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	var info *mediaprobe.Info
+	media := req.Media
+	if req.Path != "" {
+		// only files sent as audio or video have to probe; anything else
+		// that does not is kept as a document
+		info, err = mediaprobe.ProbeFile(req.Path)
+		if err != nil && (req.Type == entity.AttachmentTypeAudio || req.Type == entity.AttachmentTypeVideo) {
+			code = "[SERVICE] CreateAttachment - 2"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+		media = ToMediaEntity(info, req.Path)
+	}
+
+	if media == nil {
+		media = &entity.MediaEntity{MimeType: "application/octet-stream"}
+	}
+
+	attachmentType := req.Type
+	if attachmentType == "" {
+		attachmentType = entity.AttachmentTypeDocument
+		if media.VideoCodec != "" {
+			attachmentType = entity.AttachmentTypeVideo
+		} else if media.AudioCodec != "" {
+			attachmentType = entity.AttachmentTypeAudio
+		}
+	}
+
+	if (attachmentType == entity.AttachmentTypeVideo && media.VideoCodec == "") ||
+		(attachmentType == entity.AttachmentTypeAudio && media.AudioCodec == "") {
+		code = "[SERVICE] CreateAttachment - 3"
+		err = fmt.Errorf("%w: file is not %s", mediaprobe.ErrUnknownFormat, attachmentType)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	url := req.Url
+	if req.Path != "" {
+		url, err = c.ik.UploadImage(ctx, &entity.FileUploadEntity{
+			Name: fmt.Sprintf("%d-%d%s", req.CreatedById, time.Now().UnixNano(), filepath.Ext(req.FileName)),
+			Path: req.Path,
+		})
+		if err != nil {
+			code = "[SERVICE] CreateAttachment - 4"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+	}
+
+	posterUrl, err := c.uploadPoster(ctx, req, info)
+	if err != nil {
+		code = "[SERVICE] CreateAttachment - 5"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	reqEntity := entity.ContentAttachmentEntity{
+		ContentID: req.ContentID,
+		Type:      attachmentType,
+		Url:       url,
+		FileName:  req.FileName,
+		Size:      req.Size,
+		Media:     *media,
+		PosterUrl: posterUrl,
+	}
+
+	result, err := c.contentRepo.CreateAttachment(ctx, reqEntity)
+	if err != nil {
+		code = "[SERVICE] CreateAttachment - 6"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	c.cache.invalidate(ctx, ContentCacheTag(req.ContentID))
+
+	return result, nil
+}
+
+// uploadPoster stores the poster sent along with the attachment, or falls
+// back to the artwork embedded in the media file.
+func (c *contentService) uploadPoster(ctx context.Context, req entity.AttachmentUploadEntity, info *mediaprobe.Info) (string, error) {
+	posterPath := req.PosterPath
+	if posterPath == "" {
+		if info == nil || len(info.Artwork) == 0 {
+			return "", nil
+		}
+
+		ext := ".jpg"
+		if info.ArtworkMime == "image/png" {
+			ext = ".png"
+		}
+
+		posterPath = req.Path + "-poster" + ext
+		if err := os.WriteFile(posterPath, info.Artwork, 0644); err != nil {
+			return "", err
+		}
+		defer os.Remove(posterPath)
+	}
+
+	return c.ik.UploadImage(ctx, &entity.FileUploadEntity{
+		Name: fmt.Sprintf("%d-%d-poster%s", req.CreatedById, time.Now().UnixNano(), filepath.Ext(posterPath)),
+		Path: posterPath,
+	})
+}
+
+// DeleteAttachment implements ContentService.
+func (c *contentService) DeleteAttachment(ctx context.Context, contentID int64, id int64) error {
+	err = c.contentRepo.DeleteAttachment(ctx, contentID, id)
+	if err != nil {
+		code = "[SERVICE] DeleteAttachment - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	c.cache.invalidate(ctx, ContentCacheTag(contentID))
+
+	return nil
+}
+
+// ToMediaEntity converts probe results, falling back to a sniffed mime type
+// for files that are not audio or video.
+func ToMediaEntity(info *mediaprobe.Info, path string) *entity.MediaEntity {
+	if info == nil {
+		return &entity.MediaEntity{MimeType: mediaprobe.DetectMimeType(path)}
+	}
+
+	return &entity.MediaEntity{
+		MimeType:   info.MimeType,
+		DurationMs: info.DurationMs,
+		VideoCodec: info.VideoCodec,
+		AudioCodec: info.AudioCodec,
+		Width:      info.Width,
+		Height:     info.Height,
+		SampleRate: info.SampleRate,
+		Channels:   info.Channels,
+	}
+}
 
-func NewContentService(repo repository.ContentRepository, cfg *config.Config, ik imagekit.ImageKitAdapter) ContentService {
+func NewContentService(repo repository.ContentRepository, tx repository.Transactor, cfg *config.Config, ik imagekit.ImageKitAdapter, cacheStore cache.Cache, purger cdn.CachePurger, webhooks WebhookService) ContentService {
 	return &contentService{
 		contentRepo: repo,
+		tx:          tx,
 		cfg:         cfg,
 		ik:          ik,
+		cache:       newReadCache(cacheStore, purger, time.Duration(cfg.Cache.TtlSeconds)*time.Second),
+		webhooks:    webhooks,
 	}
 }
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/permalink"
+
+	"github.com/rs/zerolog"
+)
+
+const defaultFeedItemCount = 20
+
+type FeedService interface {
+	GetFeed(ctx context.Context, query entity.FeedQueryEntity) (*entity.FeedEntity, error)
+}
+
+type feedService struct {
+	contentService  ContentService
+	categoryService CategoryService
+	cfg             *config.Config
+}
+
+// GetFeed implements FeedService.
+func (f *feedService) GetFeed(ctx context.Context, query entity.FeedQueryEntity) (*entity.FeedEntity, error) {
+	itemCount := f.cfg.Feed.ItemCount
+	if itemCount <= 0 {
+		itemCount = defaultFeedItemCount
+	}
+
+	feed := entity.FeedEntity{
+		Title:       f.cfg.Feed.Title,
+		Description: f.cfg.Feed.Description,
+		Link:        f.cfg.App.FrontendUrl,
+	}
+
+	queryString := entity.QueryString{
+		Limit:     itemCount,
+		Page:      1,
+		OrderBy:   "created_at",
+		OrderType: "desc",
+		Status:    "PUBLISH",
+		Tag:       query.Tag,
+	}
+
+	if query.CategorySlug != "" {
+		category, err := f.categoryService.GetCategoryBySlug(ctx, query.CategorySlug)
+		if err != nil {
+			code = "[SERVICE] GetFeed - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return nil, err
+		}
+
+		queryString.CategoryID = category.ID
+		feed.Title = fmt.Sprintf("%s - %s", feed.Title, category.Title)
+		feed.Link = permalink.Category(f.cfg.App.FrontendUrl, category.Slug)
+	}
+
+	if query.Tag != "" {
+		feed.Title = fmt.Sprintf("%s - %s", feed.Title, query.Tag)
+		feed.Link = permalink.Tag(f.cfg.App.FrontendUrl, query.Tag)
+	}
+
+	results, _, _, err := f.contentService.GetContents(ctx, queryString)
+	if err != nil {
+		code = "[SERVICE] GetFeed - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	feed.Items = results
+	for _, result := range results {
+		if result.UpdatedAt.After(feed.Updated) {
+			feed.Updated = result.UpdatedAt
+		}
+	}
+
+	return &feed, nil
+}
+
+func NewFeedService(contentService ContentService, categoryService CategoryService, cfg *config.Config) FeedService {
+	return &feedService{
+		contentService:  contentService,
+		categoryService: categoryService,
+		cfg:             cfg,
+	}
+}
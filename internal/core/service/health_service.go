@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/migrate"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultHealthCheckTimeout    = 2 * time.Second
+	defaultHealthStorageInterval = 30 * time.Second
+)
+
+type HealthService interface {
+	Ready(ctx context.Context) entity.HealthReportEntity
+	SetShuttingDown()
+}
+
+type healthService struct {
+	healthRepo   repository.HealthRepository
+	ik           imagekit.ImageKitAdapter
+	migrator     *migrate.Migrator
+	cfg          *config.Config
+	shuttingDown atomic.Bool
+
+	storageMu    sync.Mutex
+	storageCheck *entity.HealthCheckEntity
+}
+
+// Ready implements HealthService. Checks run concurrently, each bounded by
+// the check timeout, and the report is down as soon as one check is.
+func (h *healthService) Ready(ctx context.Context) entity.HealthReportEntity {
+	checks := []func(context.Context) entity.HealthCheckEntity{
+		h.checkPostgres,
+		h.checkMigrations,
+		h.checkStorage,
+	}
+
+	report := entity.HealthReportEntity{
+		Status: entity.HealthStatusUp,
+		Checks: make([]entity.HealthCheckEntity, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = check(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if h.shuttingDown.Load() {
+		report.Checks = append(report.Checks, entity.HealthCheckEntity{
+			Name:      "shutdown",
+			Status:    entity.HealthStatusDown,
+			Detail:    "server is shutting down",
+			CheckedAt: time.Now(),
+		})
+	}
+
+	for _, check := range report.Checks {
+		if check.Status != entity.HealthStatusUp {
+			report.Status = entity.HealthStatusDown
+		}
+	}
+
+	return report
+}
+
+// SetShuttingDown implements HealthService. From then on the server
+// reports not ready, so load balancers stop sending it traffic before it
+// stops accepting connections.
+func (h *healthService) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *healthService) checkPostgres(ctx context.Context) entity.HealthCheckEntity {
+	return h.run(ctx, "postgres", func(ctx context.Context) (string, error) {
+		return "", h.healthRepo.Ping(ctx)
+	})
+}
+
+// checkMigrations is down while the database is dirty or behind the
+// migrations of this binary. A database ahead of the binary is fine: it
+// happens during a rolling deploy.
+func (h *healthService) checkMigrations(ctx context.Context) entity.HealthCheckEntity {
+	return h.run(ctx, "migrations", func(ctx context.Context) (string, error) {
+		current, dirty, err := h.migrator.Version(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		detail := fmt.Sprintf("version %d, latest %d", current, h.migrator.Latest())
+		if dirty {
+			return detail, migrate.ErrDirty
+		}
+		if current < h.migrator.Latest() {
+			return detail, fmt.Errorf("%d pending migrations", len(h.migrator.Pending(current)))
+		}
+
+		return detail, nil
+	})
+}
+
+// checkStorage pings the storage API at most once per storage interval;
+// probes come every few seconds and it is a third-party API.
+func (h *healthService) checkStorage(ctx context.Context) entity.HealthCheckEntity {
+	interval := defaultHealthStorageInterval
+	if h.cfg.Health.StorageIntervalSeconds > 0 {
+		interval = time.Duration(h.cfg.Health.StorageIntervalSeconds) * time.Second
+	}
+
+	h.storageMu.Lock()
+	defer h.storageMu.Unlock()
+
+	if h.storageCheck != nil && time.Since(h.storageCheck.CheckedAt) < interval {
+		return *h.storageCheck
+	}
+
+	check := h.run(ctx, "storage", func(ctx context.Context) (string, error) {
+		return "", h.ik.Ping(ctx)
+	})
+	h.storageCheck = &check
+
+	return check
+}
+
+func (h *healthService) run(ctx context.Context, name string, fn func(ctx context.Context) (string, error)) entity.HealthCheckEntity {
+	timeout := defaultHealthCheckTimeout
+	if h.cfg.Health.CheckTimeoutMs > 0 {
+		timeout = time.Duration(h.cfg.Health.CheckTimeoutMs) * time.Millisecond
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := fn(ctx)
+	check := entity.HealthCheckEntity{
+		Name:      name,
+		Status:    entity.HealthStatusUp,
+		Latency:   time.Since(start),
+		Detail:    detail,
+		CheckedAt: start,
+	}
+	if err != nil {
+		check.Status = entity.HealthStatusDown
+		check.Error = err.Error()
+	}
+
+	return check
+}
+
+func NewHealthService(healthRepo repository.HealthRepository, ik imagekit.ImageKitAdapter, migrator *migrate.Migrator, cfg *config.Config) HealthService {
+	return &healthService{
+		healthRepo: healthRepo,
+		ik:         ik,
+		migrator:   migrator,
+		cfg:        cfg,
+	}
+}
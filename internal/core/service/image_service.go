@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/filestore"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/imgtransform"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrFocalPointInvalid = errors.New("focal point invalid: x and y must be between 0 and 1")
+	ErrImageUrlInvalid   = errors.New("image url must point to the configured storage endpoint")
+)
+
+type ImageService interface {
+	CreateImage(ctx context.Context, req entity.ImageEntity) (*entity.ImageEntity, error)
+	GetImageByID(ctx context.Context, id int64) (*entity.ImageEntity, error)
+	UpdateFocalPoint(ctx context.Context, req entity.ImageEntity) error
+	SignImagePath(ctx context.Context, id int64, opts entity.ImageTransformEntity) (string, error)
+	RenderImage(ctx context.Context, id int64, opts entity.ImageTransformEntity, signature string) ([]byte, string, error)
+}
+
+type imageService struct {
+	imageRepo repository.ImageRepository
+	cache     filestore.ImageCache
+	cfg       *config.Config
+	ik        imagekit.ImageKitAdapter
+}
+
+// CreateImage implements ImageService.
+func (i *imageService) CreateImage(ctx context.Context, req entity.ImageEntity) (*entity.ImageEntity, error) {
+	if i.cfg.IK.UrlEndpoint != "" && !strings.HasPrefix(req.Url, i.cfg.IK.UrlEndpoint) {
+		return nil, ErrImageUrlInvalid
+	}
+
+	req.FocalX, req.FocalY = 0.5, 0.5
+	result, err := i.imageRepo.CreateImage(ctx, req)
+	if err != nil {
+		code = "[SERVICE] CreateImage - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetImageByID implements ImageService.
+func (i *imageService) GetImageByID(ctx context.Context, id int64) (*entity.ImageEntity, error) {
+	result, err := i.imageRepo.GetImageByID(ctx, id)
+	if err != nil {
+		code = "[SERVICE] GetImageByID - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateFocalPoint implements ImageService.
+func (i *imageService) UpdateFocalPoint(ctx context.Context, req entity.ImageEntity) error {
+	if req.FocalX < 0 || req.FocalX > 1 || req.FocalY < 0 || req.FocalY > 1 {
+		return ErrFocalPointInvalid
+	}
+
+	err = i.imageRepo.UpdateFocalPoint(ctx, req)
+	if err != nil {
+		code = "[SERVICE] UpdateFocalPoint - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// SignImagePath implements ImageService.
+func (i *imageService) SignImagePath(ctx context.Context, id int64, opts entity.ImageTransformEntity) (string, error) {
+	opts, err := imgtransform.Normalize(opts, i.maxDimension())
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.imageRepo.GetImageByID(ctx, id); err != nil {
+		code = "[SERVICE] SignImagePath - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return "", err
+	}
+
+	return imgtransform.SignedPath(i.cfg.Image.SigningKey, id, opts), nil
+}
+
+// RenderImage implements ImageService.
+func (i *imageService) RenderImage(ctx context.Context, id int64, opts entity.ImageTransformEntity, signature string) ([]byte, string, error) {
+	opts, err := imgtransform.Normalize(opts, i.maxDimension())
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Only parameter combinations handed out by SignImagePath are rendered,
+	// otherwise anyone could make us resize to arbitrary sizes.
+	if err = imgtransform.Verify(i.cfg.Image.SigningKey, id, opts, signature); err != nil {
+		return nil, "", err
+	}
+
+	image, err := i.imageRepo.GetImageByID(ctx, id)
+	if err != nil {
+		code = "[SERVICE] RenderImage - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, "", err
+	}
+
+	contentType := imgtransform.ContentType(opts.Format)
+	cacheKey := imageCacheKey(image, opts)
+	if data, ok := i.cache.GetImage(cacheKey); ok {
+		return data, contentType, nil
+	}
+
+	source, err := i.ik.FetchImage(ctx, image.Url)
+	if err != nil {
+		code = "[SERVICE] RenderImage - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, "", err
+	}
+
+	src, err := imgtransform.Decode(source)
+	if err != nil {
+		code = "[SERVICE] RenderImage - 3"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	dst := imgtransform.Transform(src, opts, image.FocalX, image.FocalY, i.maxDimension())
+	if err = imgtransform.Encode(&buf, dst, opts.Format); err != nil {
+		code = "[SERVICE] RenderImage - 4"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, "", err
+	}
+
+	if err = i.cache.PutImage(cacheKey, buf.Bytes()); err != nil {
+		code = "[SERVICE] RenderImage - 5"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+func (i *imageService) maxDimension() int {
+	if i.cfg.Image.MaxDimension > 0 {
+		return i.cfg.Image.MaxDimension
+	}
+
+	return 2048
+}
+
+// imageCacheKey includes the source and focal point, so moving the focal
+// point or replacing the file never serves a stale crop.
+func imageCacheKey(image *entity.ImageEntity, opts entity.ImageTransformEntity) string {
+	raw := fmt.Sprintf("%d|%s|%.3f|%.3f|%d|%d|%s|%s", image.ID, image.Url, image.FocalX, image.FocalY,
+		opts.Width, opts.Height, opts.Fit, opts.Format)
+	sum := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(sum[:]) + "." + opts.Format
+}
+
+func NewImageService(imageRepo repository.ImageRepository, cache filestore.ImageCache, cfg *config.Config, ik imagekit.ImageKitAdapter) ImageService {
+	return &imageService{
+		imageRepo: imageRepo,
+		cache:     cache,
+		cfg:       cfg,
+		ik:        ik,
+	}
+}
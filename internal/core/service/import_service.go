@@ -0,0 +1,726 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/cache"
+	"gonews/internal/adapter/cdn"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/blocks"
+	"gonews/lib/conv"
+	"gonews/lib/importer"
+	"gonews/lib/permalink"
+	"html"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportErrors       = 100
+	importProgressStep    = 100
+	defaultImportDir      = "./temp/imports"
+	defaultImportImageMax = 20 << 20
+
+	// a running job stamps its heartbeat every importHeartbeat; one that
+	// has not for importLease belongs to an instance that is gone
+	importHeartbeat = 30 * time.Second
+	importLease     = 2 * time.Minute
+)
+
+var (
+	ErrImportSourceRequired = errors.New("import needs a file or an upload_id")
+	ErrImportAuthorRequired = errors.New("content has no author email and the import has no fallback user")
+)
+
+type ImportService interface {
+	CreateJob(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error)
+	GetJob(ctx context.Context, id int64) (*entity.ImportJobEntity, error)
+	GetJobs(ctx context.Context) ([]entity.ImportJobEntity, error)
+	RunImport(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error)
+	RunNextJob(ctx context.Context) (*entity.ImportJobEntity, error)
+	RequeueInterruptedJobs(ctx context.Context) (int64, error)
+	ResolveRedirect(ctx context.Context, fromPath string) (*entity.RedirectEntity, error)
+}
+
+type importService struct {
+	importRepo  repository.ImportRepository
+	contentRepo repository.ContentRepository
+	tx          repository.Transactor
+	cfg         *config.Config
+	ik          imagekit.ImageKitAdapter
+	cache       *readCache
+	webhooks    WebhookService
+}
+
+// CreateJob implements ImportService. The job is queued for the background
+// worker.
+func (i *importService) CreateJob(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error) {
+	if req.FilePath == "" && req.SourceUrl == "" {
+		return nil, ErrImportSourceRequired
+	}
+
+	switch req.Format {
+	case entity.ImportFormatWXR, entity.ImportFormatJSON, entity.ImportFormatCSV:
+	default:
+		return nil, importer.ErrFormatUnsupported
+	}
+
+	req.Status = entity.ImportStatusQueued
+	result, err := i.importRepo.CreateJob(ctx, req)
+	if err != nil {
+		code = "[SERVICE] CreateJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetJob implements ImportService.
+func (i *importService) GetJob(ctx context.Context, id int64) (*entity.ImportJobEntity, error) {
+	result, err := i.importRepo.GetJob(ctx, id)
+	if err != nil {
+		code = "[SERVICE] GetJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetJobs implements ImportService.
+func (i *importService) GetJobs(ctx context.Context) ([]entity.ImportJobEntity, error) {
+	results, err := i.importRepo.GetJobs(ctx, 50)
+	if err != nil {
+		code = "[SERVICE] GetJobs - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// RunImport implements ImportService. It records the job as running and
+// imports it right away, for the CLI.
+func (i *importService) RunImport(ctx context.Context, req entity.ImportJobEntity) (*entity.ImportJobEntity, error) {
+	switch req.Format {
+	case entity.ImportFormatWXR, entity.ImportFormatJSON, entity.ImportFormatCSV:
+	default:
+		return nil, importer.ErrFormatUnsupported
+	}
+
+	now := time.Now()
+	req.Status = entity.ImportStatusRunning
+	req.StartedAt = &now
+	job, err := i.importRepo.CreateJob(ctx, req)
+	if err != nil {
+		code = "[SERVICE] RunImport - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return i.runJob(ctx, *job)
+}
+
+// RunNextJob implements ImportService. It returns nil when no job was
+// queued.
+func (i *importService) RunNextJob(ctx context.Context) (*entity.ImportJobEntity, error) {
+	job, err := i.importRepo.ClaimNextJob(ctx)
+	if err != nil {
+		code = "[SERVICE] RunNextJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, nil
+	}
+
+	return i.runJob(ctx, *job)
+}
+
+// RequeueInterruptedJobs implements ImportService. Imports are idempotent,
+// so jobs cut off by a restart are simply run again. Jobs another instance
+// is still running keep their heartbeat fresh and are left alone.
+func (i *importService) RequeueInterruptedJobs(ctx context.Context) (int64, error) {
+	requeued, err := i.importRepo.RequeueRunningJobs(ctx, time.Now().Add(-importLease))
+	if err != nil {
+		code = "[SERVICE] RequeueInterruptedJobs - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	return requeued, nil
+}
+
+// ResolveRedirect implements ImportService. Only published contents are
+// redirected to.
+func (i *importService) ResolveRedirect(ctx context.Context, fromPath string) (*entity.RedirectEntity, error) {
+	redirect, content, err := i.importRepo.GetRedirect(ctx, fromPath)
+	if err != nil {
+		code = "[SERVICE] ResolveRedirect - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if content.Status != "PUBLISH" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	redirect.ToPath = permalink.Content("", *content)
+	return redirect, nil
+}
+
+func (i *importService) runJob(ctx context.Context, job entity.ImportJobEntity) (*entity.ImportJobEntity, error) {
+	run := &importRun{
+		ctx:        ctx,
+		service:    i,
+		job:        &job,
+		users:      map[string]int64{},
+		categories: map[string]int64{},
+	}
+
+	stop := i.heartbeat(ctx, job.ID)
+	err := run.parse()
+	stop()
+	// every write invalidated its tags already; this covers CDN purges
+	// dropped on a full queue while a large import ran
+	if job.Stats.CategoriesCreated > 0 || job.Stats.ContentsCreated > 0 {
+		i.cache.invalidate(context.WithoutCancel(ctx), CacheTagCategories, CacheTagContents)
+	}
+
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Status = entity.ImportStatusSucceeded
+	if err != nil {
+		code = "[SERVICE] runJob - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		job.Status = entity.ImportStatusFailed
+		job.Error = err.Error()
+	}
+
+	// jobs with a context that was cancelled are still recorded
+	if updateErr := i.importRepo.UpdateJob(context.WithoutCancel(ctx), job); updateErr != nil {
+		code = "[SERVICE] runJob - 2"
+		zerolog.Ctx(ctx).Error().Err(updateErr).Msg(code)
+		return nil, updateErr
+	}
+
+	return &job, err
+}
+
+// heartbeat stamps the job until the returned func is called.
+func (i *importService) heartbeat(ctx context.Context, id int64) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(importHeartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := i.importRepo.TouchJob(ctx, id); err != nil {
+					code = "[SERVICE] heartbeat - 1"
+					zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+// importRun is the importer.Handler of a single job. It caches the users
+// and categories it resolved and keeps the job statistics up to date.
+type importRun struct {
+	ctx        context.Context
+	service    *importService
+	job        *entity.ImportJobEntity
+	users      map[string]int64
+	categories map[string]int64
+	processed  int
+}
+
+func (r *importRun) parse() error {
+	filePath := r.job.FilePath
+	if filePath == "" {
+		downloaded, err := r.service.download(r.ctx, r.job.SourceUrl)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(downloaded)
+		filePath = downloaded
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = importer.Parse(r.job.Format, file, r); err != nil {
+		return err
+	}
+
+	// uploaded files are ours to clean up; files given to the CLI are not
+	if r.service.inImportDir(r.job.FilePath) {
+		os.Remove(r.job.FilePath)
+	}
+
+	return nil
+}
+
+// Author implements importer.Handler.
+func (r *importRun) Author(author entity.ImportAuthorEntity) error {
+	if author.Email == "" {
+		return nil
+	}
+
+	userID, err := r.user(author)
+	if err != nil {
+		r.fail("author "+author.Email, err)
+		return nil
+	}
+
+	if author.Login != "" {
+		r.users["login:"+author.Login] = userID
+	}
+
+	return r.ctx.Err()
+}
+
+// Category implements importer.Handler.
+func (r *importRun) Category(category entity.ImportCategoryEntity) error {
+	if _, err := r.category(category); err != nil {
+		r.fail("category "+category.Slug, err)
+	}
+
+	return r.ctx.Err()
+}
+
+// Content implements importer.Handler. Failing contents are counted and
+// logged on the job without stopping the import.
+func (r *importRun) Content(content entity.ImportContentEntity) error {
+	if err := r.content(content); err != nil {
+		r.job.Stats.ContentsFailed++
+		r.fail(content.SourceKey, err)
+	}
+
+	r.processed++
+	if r.processed%importProgressStep == 0 {
+		if err := r.service.importRepo.UpdateJob(r.ctx, *r.job); err != nil {
+			return err
+		}
+	}
+
+	return r.ctx.Err()
+}
+
+func (r *importRun) content(content entity.ImportContentEntity) error {
+	if content.SourceKey == "" {
+		return errors.New("content has no source id")
+	}
+	if strings.TrimSpace(content.Title) == "" {
+		return errors.New("content has no title")
+	}
+
+	mapping, err := r.service.importRepo.GetMapping(r.ctx, entity.ImportSourceContent, content.SourceKey)
+	if err != nil {
+		return err
+	}
+	if mapping != nil {
+		r.job.Stats.ContentsSkipped++
+		return nil
+	}
+
+	userID, err := r.author(content.Author)
+	if err != nil {
+		return err
+	}
+
+	categoryID, err := r.category(content.Category)
+	if err != nil {
+		return err
+	}
+
+	image := content.Image
+	if image != "" {
+		if image, err = r.image(content.Image); err != nil {
+			image = content.Image
+		}
+	}
+
+	slug := conv.GenerateSlug(content.Slug)
+	if slug == "" {
+		slug = conv.GenerateSlug(content.Title)
+	}
+	if slug == "" {
+		slug = "content"
+	}
+
+	req := entity.ContentEntity{
+		Title:       truncateRunes(strings.TrimSpace(content.Title), 200),
+		Slug:        truncateRunes(slug, 200),
+		Description: r.images(content.Description),
+		BodyFormat:  entity.BodyFormatHTML,
+		Image:       image,
+		Tags:        content.Tags,
+		Status:      content.Status,
+		CategoryID:  categoryID,
+		CreatedById: userID,
+		CreatedAt:   content.PublishedAt,
+	}
+	if err = prepareContentBody(&req); err != nil {
+		return err
+	}
+
+	excerpt := plainText(content.Excerpt)
+	if excerpt == "" {
+		excerpt = req.PlainText
+	}
+	req.Excerpt = truncateRunes(strings.Join(strings.Fields(excerpt), " "), 250)
+
+	// the events go out with the content, as when it is created by hand
+	var redirected bool
+	err = r.service.tx.Transaction(r.ctx, func(ctx context.Context) error {
+		id, ok, err := r.service.importRepo.CreateContent(ctx, req, entity.ImportMappingEntity{
+			SourceType: entity.ImportSourceContent,
+			SourceKey:  content.SourceKey,
+			TargetUrl:  content.SourceUrl,
+		}, redirectPath(content.SourceUrl))
+		if err != nil {
+			return err
+		}
+		redirected = ok
+
+		created := contentForEvent(ctx, r.service.contentRepo, id)
+		return publishContentEvents(ctx, r.service.webhooks, created, append([]string{entity.WebhookEventContentCreated}, statusEvents("", created.Status)...)...)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.service.cache.invalidate(r.ctx, CacheTagContents)
+	r.job.Stats.ContentsCreated++
+	if redirected {
+		r.job.Stats.RedirectsCreated++
+	}
+
+	return nil
+}
+
+// author resolves the author of a content: by email, then by a login seen
+// in the author list, then the user who started the import.
+func (r *importRun) author(author entity.ImportAuthorEntity) (int64, error) {
+	if author.Email != "" {
+		return r.user(author)
+	}
+
+	if userID, ok := r.users["login:"+author.Login]; ok && author.Login != "" {
+		return userID, nil
+	}
+
+	if r.job.CreatedById != 0 {
+		return r.job.CreatedById, nil
+	}
+
+	return 0, ErrImportAuthorRequired
+}
+
+// user finds or creates the user of an author. Created users get a random
+// password and have to have it reset before they can log in.
+func (r *importRun) user(author entity.ImportAuthorEntity) (int64, error) {
+	email := strings.ToLower(strings.TrimSpace(author.Email))
+	if userID, ok := r.users[email]; ok {
+		return userID, nil
+	}
+
+	// hashing takes about a second, so it is left for authors that are new
+	userID, err := r.service.importRepo.GetUserID(r.ctx, email)
+	if err != nil {
+		return 0, err
+	}
+	if userID != 0 {
+		r.users[email] = userID
+		return userID, nil
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return 0, err
+	}
+	password, err := conv.HashPassword(hex.EncodeToString(secret))
+	if err != nil {
+		return 0, err
+	}
+
+	name := author.Name
+	if name == "" {
+		name = author.Login
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(email, "@")
+	}
+
+	userID, created, err := r.service.importRepo.FindOrCreateUser(r.ctx, entity.UserEntity{
+		Name:     truncateRunes(name, 100),
+		Email:    email,
+		Password: password,
+		Role:     entity.RoleAuthor,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if created {
+		r.job.Stats.UsersCreated++
+	}
+	r.users[email] = userID
+
+	return userID, nil
+}
+
+// category finds or creates a category by slug. Contents without one go to
+// "uncategorized".
+func (r *importRun) category(category entity.ImportCategoryEntity) (int64, error) {
+	slug := conv.GenerateSlug(category.Slug)
+	if slug == "" {
+		slug = conv.GenerateSlug(category.Title)
+	}
+	if slug == "" {
+		slug = "uncategorized"
+		category.Title = "Uncategorized"
+	}
+	slug = truncateRunes(slug, 100)
+
+	if categoryID, ok := r.categories[slug]; ok {
+		return categoryID, nil
+	}
+
+	title := strings.TrimSpace(category.Title)
+	if title == "" {
+		title = slug
+	}
+
+	categoryID, created, err := r.service.importRepo.FindOrCreateCategory(r.ctx, entity.CategoryEntity{
+		Title: truncateRunes(html.UnescapeString(title), 200),
+		Slug:  slug,
+		User:  entity.UserEntity{ID: r.job.CreatedById},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if created {
+		r.service.cache.invalidate(r.ctx, CacheTagCategories)
+		r.job.Stats.CategoriesCreated++
+	}
+	r.categories[slug] = categoryID
+
+	return categoryID, nil
+}
+
+// images rehomes every image of a body, leaving the ones that fail to
+// download pointing at the old site.
+func (r *importRun) images(body string) string {
+	for _, src := range importer.ImageSources(body) {
+		rehomed, err := r.image(src)
+		if err != nil {
+			continue
+		}
+
+		body = strings.ReplaceAll(body, src, rehomed)
+		if escaped := html.EscapeString(src); escaped != src {
+			body = strings.ReplaceAll(body, escaped, html.EscapeString(rehomed))
+		}
+	}
+
+	return body
+}
+
+// image copies an image into storage once and returns its new URL.
+func (r *importRun) image(src string) (string, error) {
+	if endpoint := r.service.cfg.IK.UrlEndpoint; endpoint != "" && strings.HasPrefix(src, endpoint) {
+		return src, nil
+	}
+
+	mapping, err := r.service.importRepo.GetMapping(r.ctx, entity.ImportSourceImage, src)
+	if err != nil {
+		return "", err
+	}
+	if mapping != nil {
+		return mapping.TargetUrl, nil
+	}
+
+	rehomed, err := r.service.rehomeImage(r.ctx, src)
+	if err != nil {
+		r.job.Stats.ImagesFailed++
+		r.fail(src, err)
+		return "", err
+	}
+
+	err = r.service.importRepo.SaveMapping(r.ctx, entity.ImportMappingEntity{
+		SourceType: entity.ImportSourceImage,
+		SourceKey:  src,
+		TargetUrl:  rehomed,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	r.job.Stats.ImagesRehomed++
+	return rehomed, nil
+}
+
+func (r *importRun) fail(key string, err error) {
+	if len(r.job.Errors) < maxImportErrors {
+		r.job.Errors = append(r.job.Errors, fmt.Sprintf("%s: %v", key, err))
+	}
+}
+
+func (i *importService) rehomeImage(ctx context.Context, src string) (string, error) {
+	data, err := i.ik.FetchRemoteImage(ctx, src)
+	if err != nil {
+		return "", err
+	}
+
+	maxSize := i.cfg.Import.MaxImageSize
+	if maxSize <= 0 {
+		maxSize = defaultImportImageMax
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("image larger than %d bytes", maxSize)
+	}
+
+	mimeType := http.DetectContentType(data)
+	if !strings.HasPrefix(mimeType, "image/") {
+		return "", fmt.Errorf("not an image: %s", mimeType)
+	}
+
+	ext := strings.ToLower(path.Ext(strings.SplitN(src, "?", 2)[0]))
+	if ext == "" || len(ext) > 5 {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+
+	file, err := os.CreateTemp(i.importDir(), "image-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(src))
+	return i.ik.UploadImage(ctx, &entity.FileUploadEntity{
+		Name: "import-" + hex.EncodeToString(sum[:8]) + ext,
+		Path: file.Name(),
+	})
+}
+
+// download fetches an import file kept in storage, such as a finished
+// resumable upload.
+func (i *importService) download(ctx context.Context, url string) (string, error) {
+	file, err := os.CreateTemp(i.importDir(), "import-*")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+
+	if err = i.ik.DownloadFile(ctx, url, file.Name()); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
+func (i *importService) importDir() string {
+	if i.cfg.Import.Dir != "" {
+		return i.cfg.Import.Dir
+	}
+
+	return defaultImportDir
+}
+
+func (i *importService) inImportDir(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+
+	dir, err := filepath.Abs(i.importDir())
+	if err != nil {
+		return false
+	}
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+
+	return strings.HasPrefix(abs, dir+string(filepath.Separator))
+}
+
+// redirectPath is the old path a content is redirected from; the site root
+// is never redirected.
+func redirectPath(sourceUrl string) string {
+	fromPath := importer.SourcePath(sourceUrl)
+	if fromPath == "/" {
+		return ""
+	}
+
+	return fromPath
+}
+
+func plainText(raw string) string {
+	converted, err := blocks.FromHTML(raw)
+	if err != nil {
+		return ""
+	}
+
+	return blocks.RenderText(converted)
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+
+	return string([]rune(s)[:max])
+}
+
+func NewImportService(importRepo repository.ImportRepository, contentRepo repository.ContentRepository, tx repository.Transactor, cfg *config.Config, ik imagekit.ImageKitAdapter, cacheStore cache.Cache, purger cdn.CachePurger, webhooks WebhookService) ImportService {
+	return &importService{
+		importRepo:  importRepo,
+		contentRepo: contentRepo,
+		tx:          tx,
+		cfg:         cfg,
+		ik:          ik,
+		cache:       newReadCache(cacheStore, purger, time.Duration(cfg.Cache.TtlSeconds)*time.Second),
+		webhooks:    webhooks,
+	}
+}
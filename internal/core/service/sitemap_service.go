@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/permalink"
+	"gonews/lib/sitemap"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	newsSitemapWindow   = 48 * time.Hour
+	newsSitemapMaxItems = 1000
+)
+
+type SitemapService interface {
+	GetSitemapIndex(ctx context.Context) ([]entity.SitemapEntity, error)
+	GetCategorySitemap(ctx context.Context) ([]entity.SitemapEntity, error)
+	GetContentSitemap(ctx context.Context, page int) ([]entity.SitemapEntity, error)
+	GetNewsSitemap(ctx context.Context) ([]entity.NewsSitemapEntity, error)
+}
+
+type sitemapService struct {
+	contentRepo  repository.ContentRepository
+	categoryRepo repository.CategoryRepository
+	cfg          *config.Config
+}
+
+// GetSitemapIndex implements SitemapService.
+func (s *sitemapService) GetSitemapIndex(ctx context.Context) ([]entity.SitemapEntity, error) {
+	countData, err := s.contentRepo.CountPublishedContents(ctx)
+	if err != nil {
+		code = "[SERVICE] GetSitemapIndex - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	baseUrl := s.baseUrl()
+	resps := []entity.SitemapEntity{{Loc: baseUrl + "/sitemaps/categories.xml"}}
+
+	chunkSize := int64(s.chunkSize())
+	for page := int64(1); (page-1)*chunkSize < countData; page++ {
+		resps = append(resps, entity.SitemapEntity{Loc: fmt.Sprintf("%s/sitemaps/contents-%d.xml", baseUrl, page)})
+	}
+
+	return resps, nil
+}
+
+// GetCategorySitemap implements SitemapService. A site without categories
+// gets an empty sitemap.
+func (s *sitemapService) GetCategorySitemap(ctx context.Context) ([]entity.SitemapEntity, error) {
+	results, err := s.categoryRepo.GetCategories(ctx)
+	if err != nil && !errors.Is(err, repository.ErrCategoriesNotFound) {
+		code = "[SERVICE] GetCategorySitemap - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.SitemapEntity{}
+	for _, result := range results {
+		resps = append(resps, entity.SitemapEntity{Loc: permalink.Category(s.cfg.App.FrontendUrl, result.Slug)})
+	}
+
+	return resps, nil
+}
+
+// GetContentSitemap implements SitemapService.
+func (s *sitemapService) GetContentSitemap(ctx context.Context, page int) ([]entity.SitemapEntity, error) {
+	if page < 1 {
+		return nil, sitemap.ErrSitemapNotFound
+	}
+
+	chunkSize := s.chunkSize()
+	results, err := s.contentRepo.GetPublishedContents(ctx, entity.SitemapQueryEntity{
+		Offset: (page - 1) * chunkSize,
+		Limit:  chunkSize,
+	})
+	if err != nil {
+		code = "[SERVICE] GetContentSitemap - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return nil, sitemap.ErrSitemapNotFound
+	}
+
+	resps := []entity.SitemapEntity{}
+	for _, result := range results {
+		resps = append(resps, entity.SitemapEntity{
+			Loc:     permalink.Content(s.cfg.App.FrontendUrl, result),
+			LastMod: result.UpdatedAt,
+		})
+	}
+
+	return resps, nil
+}
+
+// GetNewsSitemap implements SitemapService.
+func (s *sitemapService) GetNewsSitemap(ctx context.Context) ([]entity.NewsSitemapEntity, error) {
+	results, err := s.contentRepo.GetPublishedContents(ctx, entity.SitemapQueryEntity{
+		Since: time.Now().Add(-newsSitemapWindow),
+		Limit: newsSitemapMaxItems,
+	})
+	if err != nil {
+		code = "[SERVICE] GetNewsSitemap - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	resps := []entity.NewsSitemapEntity{}
+	for _, result := range results {
+		resps = append(resps, entity.NewsSitemapEntity{
+			Loc:         permalink.Content(s.cfg.App.FrontendUrl, result),
+			Title:       result.Title,
+			PublishedAt: result.CreatedAt,
+		})
+	}
+
+	return resps, nil
+}
+
+func (s *sitemapService) baseUrl() string {
+	if s.cfg.Sitemap.BaseUrl != "" {
+		return strings.TrimRight(s.cfg.Sitemap.BaseUrl, "/")
+	}
+
+	return strings.TrimRight(s.cfg.App.FrontendUrl, "/")
+}
+
+func (s *sitemapService) chunkSize() int {
+	if s.cfg.Sitemap.ChunkSize <= 0 || s.cfg.Sitemap.ChunkSize > sitemap.MaxURLs {
+		return sitemap.MaxURLs
+	}
+
+	return s.cfg.Sitemap.ChunkSize
+}
+
+func NewSitemapService(contentRepo repository.ContentRepository, categoryRepo repository.CategoryRepository, cfg *config.Config) SitemapService {
+	return &sitemapService{
+		contentRepo:  contentRepo,
+		categoryRepo: categoryRepo,
+		cfg:          cfg,
+	}
+}
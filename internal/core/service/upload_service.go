@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/filestore"
+	"gonews/internal/adapter/imagekit"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/mediaprobe"
+	"gonews/lib/metrics"
+	"gonews/lib/tus"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/google/uuid"
+)
+
+type UploadService interface {
+	CreateUpload(ctx context.Context, req entity.UploadEntity) (*entity.UploadEntity, error)
+	GetUpload(ctx context.Context, id string, userID int64) (*entity.UploadEntity, error)
+	WriteChunk(ctx context.Context, id string, userID int64, offset int64, src io.Reader) (*entity.UploadEntity, error)
+	TerminateUpload(ctx context.Context, id string, userID int64) error
+	PurgeExpiredUploads(ctx context.Context) (int, error)
+}
+
+type uploadService struct {
+	store filestore.TusStore
+	cfg   *config.Config
+	ik    imagekit.ImageKitAdapter
+}
+
+// CreateUpload implements UploadService.
+func (u *uploadService) CreateUpload(ctx context.Context, req entity.UploadEntity) (*entity.UploadEntity, error) {
+	if req.Size <= 0 {
+		return nil, tus.ErrUploadLengthInvalid
+	}
+
+	if u.cfg.Upload.MaxSize > 0 && req.Size > u.cfg.Upload.MaxSize {
+		return nil, tus.ErrMaxSizeExceeded
+	}
+
+	expireIn := u.cfg.Upload.ExpireInHours
+	if expireIn <= 0 {
+		expireIn = 24
+	}
+
+	now := time.Now()
+	req.ID = uuid.NewString()
+	req.Offset = 0
+	req.CreatedAt = now
+	req.ExpiresAt = now.Add(time.Duration(expireIn) * time.Hour)
+
+	if err = u.store.NewUpload(req); err != nil {
+		code = "[SERVICE] CreateUpload - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetUpload implements UploadService. Uploads of other users are reported
+// as not found.
+func (u *uploadService) GetUpload(ctx context.Context, id string, userID int64) (*entity.UploadEntity, error) {
+	result, err := u.store.GetUpload(id)
+	if err != nil {
+		code = "[SERVICE] GetUpload - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	if result.CreatedById != userID {
+		return nil, tus.ErrUploadNotFound
+	}
+
+	if time.Now().After(result.ExpiresAt) {
+		return nil, tus.ErrUploadExpired
+	}
+
+	return result, nil
+}
+
+// WriteChunk implements UploadService.
+func (u *uploadService) WriteChunk(ctx context.Context, id string, userID int64, offset int64, src io.Reader) (*entity.UploadEntity, error) {
+	if _, err = u.GetUpload(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	result, err := u.store.WriteChunk(id, offset, src)
+	if result != nil && result.Offset > offset {
+		metrics.UploadBytes.WithLabelValues(metrics.UploadKindTus).Add(float64(result.Offset - offset))
+	}
+	if err != nil {
+		code = "[SERVICE] WriteChunk - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return result, err
+	}
+
+	if !result.IsComplete() {
+		return result, nil
+	}
+
+	// Concurrent and retried PATCHes at the final offset all end up here,
+	// only the one that claims the upload hands it over to storage.
+	result, claimed, err := u.store.ClaimCompletion(id)
+	if err != nil {
+		code = "[SERVICE] WriteChunk - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+	if !claimed {
+		return result, nil
+	}
+
+	if err = u.completeUpload(ctx, result); err != nil {
+		// Release the claim and keep the chunk data, so an empty PATCH at
+		// the final offset can retry.
+		result.Url, result.State = "", ""
+		if err := u.store.SaveUpload(*result); err != nil {
+			code = "[SERVICE] WriteChunk - 3"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		}
+		return nil, err
+	}
+
+	if err = u.store.DeleteData(id); err != nil {
+		code = "[SERVICE] WriteChunk - 4"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+	}
+
+	return result, nil
+}
+
+// completeUpload hands the assembled file of a claimed upload over to the
+// storage adapter and records where it went.
+func (u *uploadService) completeUpload(ctx context.Context, result *entity.UploadEntity) error {
+	info, _ := mediaprobe.ProbeFile(u.store.BinPath(result.ID))
+	result.Media = ToMediaEntity(info, u.store.BinPath(result.ID))
+
+	reqEntity := entity.FileUploadEntity{
+		Name: fmt.Sprintf("%d-%d%s", result.CreatedById, time.Now().UnixNano(), filepath.Ext(result.Metadata["filename"])),
+		Path: u.store.BinPath(result.ID),
+	}
+
+	url, err := u.ik.UploadImage(ctx, &reqEntity)
+	if err != nil {
+		code = "[SERVICE] completeUpload - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	result.Url = url
+	result.State = entity.UploadStateCompleted
+	if err = u.store.SaveUpload(*result); err != nil {
+		code = "[SERVICE] completeUpload - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// TerminateUpload implements UploadService.
+func (u *uploadService) TerminateUpload(ctx context.Context, id string, userID int64) error {
+	result, err := u.store.GetUpload(id)
+	if err != nil {
+		code = "[SERVICE] TerminateUpload - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	if result.CreatedById != userID {
+		return tus.ErrUploadNotFound
+	}
+
+	if err = u.store.DeleteUpload(id); err != nil {
+		code = "[SERVICE] TerminateUpload - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// PurgeExpiredUploads implements UploadService.
+func (u *uploadService) PurgeExpiredUploads(ctx context.Context) (int, error) {
+	uploads, err := u.store.ListUploads()
+	if err != nil {
+		code = "[SERVICE] PurgeExpiredUploads - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return 0, err
+	}
+
+	purged := 0
+	now := time.Now()
+	for _, upload := range uploads {
+		if now.Before(upload.ExpiresAt) {
+			continue
+		}
+
+		if err = u.store.DeleteUpload(upload.ID); err != nil {
+			code = "[SERVICE] PurgeExpiredUploads - 2"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			continue
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+func NewUploadService(store filestore.TusStore, cfg *config.Config, ik imagekit.ImageKitAdapter) UploadService {
+	return &uploadService{
+		store: store,
+		cfg:   cfg,
+		ik:    ik,
+	}
+}
@@ -2,16 +2,38 @@ package service
 
 import (
 	"context"
+	"errors"
 	"gonews/internal/adapter/repository"
 	"gonews/internal/core/domain/entity"
 	"gonews/lib/conv"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const minPasswordLength = 8
+
+var (
+	ErrUserEmailInvalid     = errors.New("email invalid")
+	ErrUserEmailTaken       = errors.New("a user with this email already exists")
+	ErrUserNameInvalid      = errors.New("name is required and at most 100 characters")
+	ErrUserRoleInvalid      = errors.New("role invalid: must be admin, editor or author")
+	ErrUserPasswordTooShort = errors.New("password must be at least 8 characters")
 )
 
 type UserService interface {
 	UpdatePassword(ctx context.Context, newPass string, id int64) error
 	GetUserByID(ctx context.Context, id int64) (*entity.UserEntity, error)
+
+	CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error)
+	GetUsers(ctx context.Context) ([]entity.UserEntity, error)
+	SetPassword(ctx context.Context, email string, password string) error
+	SetRole(ctx context.Context, email string, role string) error
+	DeactivateUser(ctx context.Context, email string) error
 }
 
 type userService struct {
@@ -23,7 +45,7 @@ func (u *userService) GetUserByID(ctx context.Context, id int64) (*entity.UserEn
 	result, err := u.userRepo.GetUserByID(ctx, id)
 	if err != nil {
 		code := "[SERVICE] GetUserByID - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return nil, err
 	}
 	return result, nil
@@ -34,14 +56,133 @@ func (u *userService) UpdatePassword(ctx context.Context, newPass string, id int
 	password, err := conv.HashPassword(newPass)
 	if err != nil {
 		code := "[SERVICE] UpdatePassword - 1"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
 	err = u.userRepo.UpdatePassword(ctx, password, id)
 	if err != nil {
 		code := "[SERVICE] UpdatePassword - 2"
-		log.Errorw(code, err)
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// CreateUser implements UserService. req.Password is the plain password.
+func (u *userService) CreateUser(ctx context.Context, req entity.UserEntity) (*entity.UserEntity, error) {
+	address, err := mail.ParseAddress(req.Email)
+	if err != nil || address.Address != req.Email || len(req.Email) > 100 {
+		return nil, ErrUserEmailInvalid
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || utf8.RuneCountInString(req.Name) > 100 {
+		return nil, ErrUserNameInvalid
+	}
+
+	if !slices.Contains(entity.Roles, req.Role) {
+		return nil, ErrUserRoleInvalid
+	}
+
+	if utf8.RuneCountInString(req.Password) < minPasswordLength {
+		return nil, ErrUserPasswordTooShort
+	}
+
+	_, err = u.userRepo.GetUserByEmail(ctx, req.Email)
+	if err == nil {
+		return nil, ErrUserEmailTaken
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		code := "[SERVICE] CreateUser - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	req.Email = strings.ToLower(req.Email)
+	req.Password, err = conv.HashPassword(req.Password)
+	if err != nil {
+		code := "[SERVICE] CreateUser - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	result, err := u.userRepo.CreateUser(ctx, req)
+	if err != nil {
+		code := "[SERVICE] CreateUser - 3"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetUsers implements UserService.
+func (u *userService) GetUsers(ctx context.Context) ([]entity.UserEntity, error) {
+	results, err := u.userRepo.GetUsers(ctx)
+	if err != nil {
+		code := "[SERVICE] GetUsers - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetPassword implements UserService.
+func (u *userService) SetPassword(ctx context.Context, email string, password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return ErrUserPasswordTooShort
+	}
+
+	user, err := u.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		code := "[SERVICE] SetPassword - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return u.UpdatePassword(ctx, password, user.ID)
+}
+
+// SetRole implements UserService.
+func (u *userService) SetRole(ctx context.Context, email string, role string) error {
+	if !slices.Contains(entity.Roles, role) {
+		return ErrUserRoleInvalid
+	}
+
+	user, err := u.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		code := "[SERVICE] SetRole - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	err = u.userRepo.UpdateRole(ctx, role, user.ID)
+	if err != nil {
+		code := "[SERVICE] SetRole - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// DeactivateUser implements UserService. Deactivated users can no longer
+// log in; their contents stay.
+func (u *userService) DeactivateUser(ctx context.Context, email string) error {
+	user, err := u.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		code := "[SERVICE] DeactivateUser - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	err = u.userRepo.DeactivateUser(ctx, user.ID)
+	if err != nil {
+		code := "[SERVICE] DeactivateUser - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
 		return err
 	}
 
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gonews/config"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
+	"gonews/lib/netguard"
+	"gonews/lib/permalink"
+	"gonews/lib/tracing"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const (
+	WebhookHeaderID        = "X-Webhook-Id"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+
+	defaultWebhookTimeout      = 10 * time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookBackoff      = 30 * time.Second
+	defaultWebhookDisableAfter = 10
+	maxWebhookBackoff          = 6 * time.Hour
+	maxWebhookResponseBody     = 2048
+	webhookClaimBatch          = 20
+	maxWebhookDeliveries       = 100
+)
+
+var ErrWebhookUrlInvalid = errors.New("webhook url must be an absolute http or https url to a public address")
+
+type WebhookService interface {
+	GetWebhooks(ctx context.Context) ([]entity.WebhookEntity, error)
+	GetWebhookByID(ctx context.Context, id int64) (*entity.WebhookEntity, error)
+	CreateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error)
+	UpdateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error)
+	DeleteWebhook(ctx context.Context, id int64) error
+
+	GetDeliveries(ctx context.Context, webhookID int64) ([]entity.WebhookDeliveryEntity, error)
+	GetDelivery(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error)
+	Redeliver(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error)
+
+	PublishContentEvent(ctx context.Context, event string, content entity.ContentEntity) error
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	cfg         *config.Config
+	client      *http.Client
+}
+
+// webhookPayload is the body every delivery posts. ID identifies the event,
+// so receivers can drop one they got twice.
+type webhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt string      `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type webhookContentData struct {
+	ID         int64    `json:"id"`
+	Title      string   `json:"title,omitempty"`
+	Slug       string   `json:"slug,omitempty"`
+	Excerpt    string   `json:"excerpt,omitempty"`
+	Status     string   `json:"status,omitempty"`
+	CategoryID int64    `json:"category_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Image      string   `json:"image,omitempty"`
+	Url        string   `json:"url,omitempty"`
+	UpdatedAt  string   `json:"updated_at,omitempty"`
+}
+
+// GetWebhooks implements WebhookService.
+func (w *webhookService) GetWebhooks(ctx context.Context) ([]entity.WebhookEntity, error) {
+	results, err := w.webhookRepo.GetWebhooks(ctx)
+	if err != nil {
+		code = "[SERVICE] GetWebhooks - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetWebhookByID implements WebhookService.
+func (w *webhookService) GetWebhookByID(ctx context.Context, id int64) (*entity.WebhookEntity, error) {
+	result, err := w.webhookRepo.GetWebhookByID(ctx, id)
+	if err != nil {
+		code = "[SERVICE] GetWebhookByID - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateWebhook implements WebhookService. The signing secret is generated
+// here and only shown in the result.
+func (w *webhookService) CreateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error) {
+	if err = validateWebhookUrl(req.Url); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		code = "[SERVICE] CreateWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+	req.Secret = "whsec_" + hex.EncodeToString(secret)
+
+	result, err := w.webhookRepo.CreateWebhook(ctx, req)
+	if err != nil {
+		code = "[SERVICE] CreateWebhook - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// UpdateWebhook implements WebhookService.
+func (w *webhookService) UpdateWebhook(ctx context.Context, req entity.WebhookEntity) (*entity.WebhookEntity, error) {
+	if err = validateWebhookUrl(req.Url); err != nil {
+		return nil, err
+	}
+
+	if err = w.webhookRepo.UpdateWebhook(ctx, req); err != nil {
+		code = "[SERVICE] UpdateWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	result, err := w.webhookRepo.GetWebhookByID(ctx, req.ID)
+	if err != nil {
+		code = "[SERVICE] UpdateWebhook - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteWebhook implements WebhookService.
+func (w *webhookService) DeleteWebhook(ctx context.Context, id int64) error {
+	if err = w.webhookRepo.DeleteWebhook(ctx, id); err != nil {
+		code = "[SERVICE] DeleteWebhook - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// GetDeliveries implements WebhookService. It returns the latest
+// deliveries of the webhook.
+func (w *webhookService) GetDeliveries(ctx context.Context, webhookID int64) ([]entity.WebhookDeliveryEntity, error) {
+	if _, err = w.webhookRepo.GetWebhookByID(ctx, webhookID); err != nil {
+		code = "[SERVICE] GetDeliveries - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	results, err := w.webhookRepo.GetDeliveries(ctx, webhookID, maxWebhookDeliveries)
+	if err != nil {
+		code = "[SERVICE] GetDeliveries - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetDelivery implements WebhookService.
+func (w *webhookService) GetDelivery(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error) {
+	result, err := w.webhookRepo.GetDelivery(ctx, webhookID, id)
+	if err != nil {
+		code = "[SERVICE] GetDelivery - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Redeliver implements WebhookService. It queues a new delivery with the
+// original payload; the original keeps its log.
+func (w *webhookService) Redeliver(ctx context.Context, webhookID int64, id int64) (*entity.WebhookDeliveryEntity, error) {
+	original, err := w.webhookRepo.GetDelivery(ctx, webhookID, id)
+	if err != nil {
+		code = "[SERVICE] Redeliver - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	result, err := w.webhookRepo.CreateRedelivery(ctx, *original)
+	if err != nil {
+		code = "[SERVICE] Redeliver - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// PublishContentEvent implements WebhookService. It queues the event for
+// the subscribed webhooks; called in the transaction of the change, a
+// failure rolls the change back with it.
+func (w *webhookService) PublishContentEvent(ctx context.Context, event string, content entity.ContentEntity) error {
+	data := webhookContentData{
+		ID:         content.ID,
+		Title:      content.Title,
+		Slug:       content.Slug,
+		Excerpt:    content.Excerpt,
+		Status:     content.Status,
+		CategoryID: content.CategoryID,
+		Tags:       content.Tags,
+		Image:      content.Image,
+	}
+	if content.Slug != "" {
+		data.Url = permalink.Content(w.cfg.App.FrontendUrl, content)
+	}
+	if !content.UpdatedAt.IsZero() {
+		data.UpdatedAt = content.UpdatedAt.Format(time.RFC3339)
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        uuid.NewString(),
+		Event:     event,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		code = "[SERVICE] PublishContentEvent - 1"
+		zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+		return err
+	}
+
+	if _, err = w.webhookRepo.EnqueueDeliveries(ctx, event, string(payload)); err != nil {
+		code = "[SERVICE] PublishContentEvent - 2"
+		zerolog.Ctx(ctx).Error().Err(err).Str("event", event).Msg(code)
+		return err
+	}
+
+	return nil
+}
+
+// DeliverDue implements WebhookService. It sends due deliveries until none
+// are left and returns how many it sent.
+func (w *webhookService) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		deliveries, err := w.webhookRepo.ClaimDueDeliveries(ctx, webhookClaimBatch, w.timeout()+time.Minute)
+		if err != nil {
+			code = "[SERVICE] DeliverDue - 1"
+			zerolog.Ctx(ctx).Error().Err(err).Msg(code)
+			return delivered, err
+		}
+
+		if len(deliveries) == 0 {
+			return delivered, nil
+		}
+
+		for _, delivery := range deliveries {
+			w.deliver(ctx, delivery)
+			delivered++
+		}
+	}
+}
+
+// deliver makes one attempt at a claimed delivery and records its outcome.
+// A failure is retried with exponential backoff until the attempts run out;
+// a delivery that fails them all counts against the webhook, which is
+// disabled after too many of those in a row.
+func (w *webhookService) deliver(ctx context.Context, delivery entity.WebhookDeliveryEntity) {
+	logger := zerolog.Ctx(ctx).With().Int64("webhook_id", delivery.WebhookID).Int64("delivery_id", delivery.ID).Logger()
+
+	now := time.Now()
+	status, body, sendErr := w.send(ctx, delivery)
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.DurationMs = time.Since(now).Milliseconds()
+	delivery.Error = ""
+
+	succeeded := sendErr == nil
+	switch {
+	case succeeded:
+		delivery.Status = entity.WebhookDeliverySucceeded
+	case delivery.Attempts >= w.maxAttempts():
+		delivery.Error = sendErr.Error()
+		delivery.Status = entity.WebhookDeliveryFailed
+	default:
+		delivery.Error = sendErr.Error()
+		delivery.Status = entity.WebhookDeliveryPending
+		delivery.NextAttemptAt = now.Add(w.backoff(delivery.Attempts))
+	}
+
+	if err := w.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+		logger.Error().Err(err).Msg("[SERVICE] deliver - 1")
+		return
+	}
+
+	if succeeded {
+		if err := w.webhookRepo.ResetWebhookFailures(ctx, delivery.WebhookID); err != nil {
+			logger.Error().Err(err).Msg("[SERVICE] deliver - 2")
+		}
+		return
+	}
+
+	logger.Warn().Str("error", delivery.Error).Int("attempt", delivery.Attempts).Str("status", delivery.Status).Msg("webhook delivery failed")
+	if delivery.Status != entity.WebhookDeliveryFailed {
+		return
+	}
+
+	failures, err := w.webhookRepo.RecordWebhookFailure(ctx, delivery.WebhookID)
+	if err != nil {
+		logger.Error().Err(err).Msg("[SERVICE] deliver - 3")
+		return
+	}
+
+	if failures >= w.disableAfter() {
+		reason := fmt.Sprintf("%d deliveries in a row failed; last error: %s", failures, delivery.Error)
+		if err := w.webhookRepo.DisableWebhook(ctx, delivery.WebhookID, reason); err != nil {
+			logger.Error().Err(err).Msg("[SERVICE] deliver - 4")
+			return
+		}
+		logger.Warn().Int("failures", failures).Msg("webhook disabled")
+	}
+}
+
+// send posts the payload, signed with the webhook secret over the timestamp
+// and the body, and fails on anything but a 2xx answer.
+func (w *webhookService) send(ctx context.Context, delivery entity.WebhookDeliveryEntity) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout())
+	defer cancel()
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	reqHttp, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.Url, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	reqHttp.Header.Set("Content-Type", "application/json")
+	reqHttp.Header.Set("User-Agent", "gonews-webhooks")
+	reqHttp.Header.Set(WebhookHeaderID, strconv.FormatInt(delivery.ID, 10))
+	reqHttp.Header.Set(WebhookHeaderEvent, delivery.Event)
+	reqHttp.Header.Set(WebhookHeaderTimestamp, timestamp)
+	reqHttp.Header.Set(WebhookHeaderSignature, "sha256="+SignWebhookPayload(delivery.Webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := w.client.Do(reqHttp)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(body), fmt.Errorf("webhook answered with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, string(body), nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of timestamp, a dot and
+// the body, keyed with the webhook secret. Receivers compute the same to
+// check a delivery came from us and was not replayed later.
+func SignWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *webhookService) timeout() time.Duration {
+	if w.cfg.Webhook.TimeoutSeconds <= 0 {
+		return defaultWebhookTimeout
+	}
+	return time.Duration(w.cfg.Webhook.TimeoutSeconds) * time.Second
+}
+
+func (w *webhookService) maxAttempts() int {
+	if w.cfg.Webhook.MaxAttempts <= 0 {
+		return defaultWebhookMaxAttempts
+	}
+	return w.cfg.Webhook.MaxAttempts
+}
+
+func (w *webhookService) disableAfter() int {
+	if w.cfg.Webhook.DisableAfterFailures <= 0 {
+		return defaultWebhookDisableAfter
+	}
+	return w.cfg.Webhook.DisableAfterFailures
+}
+
+// backoff is the wait after the attempt-th failed attempt: the base doubled
+// for each earlier failure, up to maxWebhookBackoff.
+func (w *webhookService) backoff(attempt int) time.Duration {
+	delay := defaultWebhookBackoff
+	if w.cfg.Webhook.BackoffSeconds > 0 {
+		delay = time.Duration(w.cfg.Webhook.BackoffSeconds) * time.Second
+	}
+
+	for i := 1; i < attempt && delay < maxWebhookBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookBackoff {
+		delay = maxWebhookBackoff
+	}
+	return delay
+}
+
+// validateWebhookUrl refuses urls that are not http or https, or that name
+// a loopback, private or link-local address. Names are checked again on
+// every delivery, against what they resolve to then.
+func validateWebhookUrl(rawUrl string) error {
+	if netguard.CheckURL(rawUrl) != nil {
+		return ErrWebhookUrlInvalid
+	}
+	return nil
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, cfg *config.Config) WebhookService {
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		cfg:         cfg,
+		client: &http.Client{
+			// deliveries only reach public addresses, so a webhook cannot
+			// be used to probe the internal network
+			Transport: tracing.Transport(netguard.Transport()),
+			// a redirect is an answer like any other; following it could
+			// send the signed payload somewhere else
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
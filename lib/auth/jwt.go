@@ -9,6 +9,10 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Audience is set on every access token and required when verifying, so
+// other tokens signed with the same key are not taken for one.
+const Audience = "access"
+
 type Jwt interface {
 	GenerateToken(data *entity.JwtData) (string, int64, error)
 	VerifyAccessToken(token string) (*entity.JwtData, error)
@@ -25,6 +29,7 @@ func (o *Options) GenerateToken(data *entity.JwtData) (string, int64, error) {
 	expiresAt := now.Add(time.Hour * 24)
 	data.RegisteredClaims.ExpiresAt = jwt.NewNumericDate(expiresAt)
 	data.RegisteredClaims.Issuer = o.issuer
+	data.RegisteredClaims.Audience = jwt.ClaimStrings{Audience}
 	data.RegisteredClaims.NotBefore = jwt.NewNumericDate(now)
 	acToken := jwt.NewWithClaims(jwt.SigningMethodHS256, data)
 	accesToken, err := acToken.SignedString([]byte(o.signingKey))
@@ -41,7 +46,7 @@ func (o *Options) VerifyAccessToken(token string) (*entity.JwtData, error) {
 			return nil, fmt.Errorf("signing method invalid")
 		}
 		return []byte(o.signingKey), nil
-	})
+	}, jwt.WithAudience(Audience))
 
 	if err != nil {
 		return nil, err
@@ -53,8 +58,17 @@ func (o *Options) VerifyAccessToken(token string) (*entity.JwtData, error) {
 			return nil, err
 		}
 
+		// tokens without a user, such as preview tokens, are no access tokens
+		userID, ok := claim["user_id"].(float64)
+		if !ok {
+			return nil, fmt.Errorf("token is not valid")
+		}
+
+		role, _ := claim["role"].(string)
+
 		jwtData := &entity.JwtData{
-			UserID: claim["user_id"].(float64),
+			UserID: userID,
+			Role:   role,
 		}
 
 		return jwtData, nil
@@ -0,0 +1,238 @@
+// Package backup reads and writes site archives: a zip file holding one
+// NDJSON file per table and a manifest. The manifest is written last, so an
+// archive cut off halfway through is never mistaken for a complete one.
+package backup
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Version is the archive layout written by this package.
+const Version = 1
+
+const (
+	FileManifest   = "manifest.json"
+	FileUsers      = "users.ndjson"
+	FileCategories = "categories.ndjson"
+	FileContents   = "contents.ndjson"
+	FileImages     = "images.ndjson"
+	FileMedia      = "media.ndjson"
+)
+
+// Filter narrows an export down to the contents created in [From, To) and
+// to a single category.
+type Filter struct {
+	From     *time.Time `json:"from,omitempty"`
+	To       *time.Time `json:"to,omitempty"`
+	Category string     `json:"category,omitempty"`
+}
+
+// IsZero reports whether the filter exports the whole site.
+func (f Filter) IsZero() bool {
+	return f.From == nil && f.To == nil && f.Category == ""
+}
+
+// ParseFilter parses the from and to dates of an export. A to date without
+// a time includes the whole day.
+func ParseFilter(from, to, category string) (Filter, error) {
+	filter := Filter{Category: category}
+
+	if from != "" {
+		t, _, err := parseDate(from)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = &t
+	}
+
+	if to != "" {
+		t, dateOnly, err := parseDate(to)
+		if err != nil {
+			return filter, err
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = &t
+	}
+
+	return filter, nil
+}
+
+func parseDate(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, false, ErrDateInvalid
+	}
+
+	return t, false, nil
+}
+
+type Manifest struct {
+	Version   int              `json:"version"`
+	CreatedAt time.Time        `json:"created_at"`
+	Filter    Filter           `json:"filter"`
+	Counts    map[string]int64 `json:"counts"`
+}
+
+// Writer writes the files of an archive one after the other. Only one file
+// is open at a time, as with zip.Writer.
+type Writer struct {
+	zw     *zip.Writer
+	enc    *json.Encoder
+	name   string
+	counts map[string]int64
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		zw:     zip.NewWriter(w),
+		counts: map[string]int64{},
+	}
+}
+
+// Create starts a new NDJSON file, ending the previous one.
+func (w *Writer) Create(name string) error {
+	f, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	w.name = name
+	w.enc = json.NewEncoder(f)
+	w.counts[name] = 0
+	return nil
+}
+
+// Write appends a record to the current file.
+func (w *Writer) Write(record interface{}) error {
+	if w.enc == nil {
+		return fmt.Errorf("backup: Write called before Create")
+	}
+
+	if err := w.enc.Encode(record); err != nil {
+		return err
+	}
+
+	w.counts[w.name]++
+	return nil
+}
+
+// Close writes the manifest with the record count of every file and
+// finishes the archive.
+func (w *Writer) Close(filter Filter) (*Manifest, error) {
+	manifest := Manifest{
+		Version:   Version,
+		CreatedAt: time.Now().UTC(),
+		Filter:    filter,
+		Counts:    w.counts,
+	}
+
+	f, err := w.zw.Create(FileManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(manifest); err != nil {
+		return nil, err
+	}
+
+	if err = w.zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return &manifest, nil
+}
+
+type Reader struct {
+	zr       *zip.ReadCloser
+	files    map[string]*zip.File
+	Manifest Manifest
+}
+
+// Open opens an archive and checks its manifest.
+func Open(path string) (*Reader, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveInvalid, err)
+	}
+
+	r := &Reader{
+		zr:    zr,
+		files: map[string]*zip.File{},
+	}
+	for _, f := range zr.File {
+		r.files[f.Name] = f
+	}
+
+	f, ok := r.files[FileManifest]
+	if !ok {
+		zr.Close()
+		return nil, ErrArchiveIncomplete
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("%w: %v", ErrArchiveInvalid, err)
+	}
+	defer rc.Close()
+
+	if err = json.NewDecoder(rc).Decode(&r.Manifest); err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("%w: %v", ErrArchiveInvalid, err)
+	}
+
+	if r.Manifest.Version != Version {
+		zr.Close()
+		return nil, fmt.Errorf("%w: %d", ErrVersionUnsupported, r.Manifest.Version)
+	}
+
+	return r, nil
+}
+
+// Each decodes the records of an NDJSON file one at a time. A file missing
+// from the archive has no records.
+func Each[T any](r *Reader, name string, fn func(record T) error) error {
+	f, ok := r.files[name]
+	if !ok {
+		return nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrArchiveInvalid, err)
+	}
+	defer rc.Close()
+
+	d := json.NewDecoder(bufio.NewReader(rc))
+	for line := 1; ; line++ {
+		var record T
+		err = d.Decode(&record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s record %d: %v", ErrArchiveInvalid, name, line, err)
+		}
+
+		if err = fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *Reader) Close() error {
+	return r.zr.Close()
+}
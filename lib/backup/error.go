@@ -0,0 +1,10 @@
+package backup
+
+import "errors"
+
+var (
+	ErrArchiveInvalid     = errors.New("backup archive invalid")
+	ErrArchiveIncomplete  = errors.New("backup archive incomplete: manifest.json is missing")
+	ErrVersionUnsupported = errors.New("backup archive version unsupported")
+	ErrDateInvalid        = errors.New("date invalid: must be YYYY-MM-DD or RFC 3339")
+)
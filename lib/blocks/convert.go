@@ -0,0 +1,215 @@
+package blocks
+
+import (
+	"bytes"
+	"gonews/internal/core/domain/entity"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FromHTML converts a legacy HTML description into blocks. Only elements
+// that map to a block type survive; inline markup is kept as far as the
+// inline allowlist permits and everything else is reduced to its text.
+func FromHTML(raw string) ([]entity.ContentBlock, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(raw), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &converter{blocks: []entity.ContentBlock{}}
+	for _, node := range nodes {
+		c.walk(node)
+	}
+	c.flush()
+
+	return c.blocks, nil
+}
+
+type converter struct {
+	blocks []entity.ContentBlock
+	inline bytes.Buffer
+}
+
+// flush turns pending inline content into a paragraph.
+func (c *converter) flush() {
+	text := sanitizeInline(c.inline.String())
+	c.inline.Reset()
+	if stripTags(text) != "" {
+		c.blocks = append(c.blocks, entity.ContentBlock{Type: entity.BlockParagraph, Text: text})
+	}
+}
+
+func (c *converter) add(block entity.ContentBlock) {
+	c.flush()
+	c.blocks = append(c.blocks, block)
+}
+
+func (c *converter) walk(node *html.Node) {
+	if node.Type == html.TextNode {
+		c.inline.WriteString(html.EscapeString(node.Data))
+		return
+	}
+	if node.Type != html.ElementNode {
+		return
+	}
+
+	switch node.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template:
+		return
+	case atom.P:
+		c.flush()
+		c.walkChildren(node)
+		c.flush()
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		if text := sanitizeInline(innerHTML(node)); stripTags(text) != "" {
+			c.add(entity.ContentBlock{Type: entity.BlockHeading, Level: int(node.Data[1] - '0'), Text: text})
+		}
+	case atom.Blockquote:
+		block := entity.ContentBlock{Type: entity.BlockQuote}
+		if cite := findFirst(node, atom.Cite); cite != nil {
+			block.Caption = sanitizeInline(innerHTML(cite))
+			cite.Parent.RemoveChild(cite)
+		}
+		block.Text = sanitizeInline(strings.Join(childBlocksHTML(node), "<br>"))
+		if stripTags(block.Text) != "" {
+			c.add(block)
+		}
+	case atom.Ul, atom.Ol:
+		block := entity.ContentBlock{Type: entity.BlockList, Ordered: node.DataAtom == atom.Ol}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.DataAtom == atom.Li {
+				if item := sanitizeInline(innerHTML(child)); stripTags(item) != "" {
+					block.Items = append(block.Items, item)
+				}
+			}
+		}
+		if len(block.Items) > 0 {
+			c.add(block)
+		}
+	case atom.Pre:
+		block := entity.ContentBlock{Type: entity.BlockCode, Text: textContent(node)}
+		if code := findFirst(node, atom.Code); code != nil {
+			for _, class := range strings.Fields(attr(code, "class")) {
+				if lang := strings.TrimPrefix(class, "language-"); lang != class && languagePattern.MatchString(lang) {
+					block.Language = lang
+				}
+			}
+		}
+		if strings.TrimSpace(block.Text) != "" {
+			c.add(block)
+		}
+	case atom.Figure:
+		c.walkFigure(node)
+	case atom.Img:
+		if src := attr(node, "src"); isHttpUrl(src) {
+			c.add(entity.ContentBlock{Type: entity.BlockImage, Url: src, Alt: attr(node, "alt")})
+		}
+	case atom.Iframe:
+		if src := attr(node, "src"); isHttpUrl(src) {
+			c.add(entity.ContentBlock{Type: entity.BlockEmbed, Url: src})
+		}
+	case atom.Br:
+		c.inline.WriteString("<br>")
+	case atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Aside, atom.Body, atom.Html:
+		c.flush()
+		c.walkChildren(node)
+		c.flush()
+	default:
+		// Inline element: keep the markup and let the inline allowlist
+		// decide what survives.
+		var buf bytes.Buffer
+		html.Render(&buf, node)
+		c.inline.Write(buf.Bytes())
+	}
+}
+
+func (c *converter) walkChildren(node *html.Node) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		c.walk(child)
+	}
+}
+
+func (c *converter) walkFigure(node *html.Node) {
+	caption := ""
+	if figcaption := findFirst(node, atom.Figcaption); figcaption != nil {
+		caption = sanitizeInline(innerHTML(figcaption))
+	}
+
+	if img := findFirst(node, atom.Img); img != nil && isHttpUrl(attr(img, "src")) {
+		c.add(entity.ContentBlock{Type: entity.BlockImage, Url: attr(img, "src"), Alt: attr(img, "alt"), Caption: caption})
+		return
+	}
+
+	if iframe := findFirst(node, atom.Iframe); iframe != nil && isHttpUrl(attr(iframe, "src")) {
+		c.add(entity.ContentBlock{Type: entity.BlockEmbed, Url: attr(iframe, "src"), Caption: caption})
+		return
+	}
+
+	c.flush()
+	c.walkChildren(node)
+	c.flush()
+}
+
+func innerHTML(node *html.Node) string {
+	var buf bytes.Buffer
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		html.Render(&buf, child)
+	}
+
+	return buf.String()
+}
+
+// childBlocksHTML returns the inner HTML of each paragraph of a container,
+// or the whole content when it holds no paragraphs.
+func childBlocksHTML(node *html.Node) []string {
+	parts := []string{}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.P {
+			parts = append(parts, innerHTML(child))
+		}
+	}
+
+	if len(parts) == 0 {
+		return []string{innerHTML(node)}
+	}
+
+	return parts
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+
+	return b.String()
+}
+
+func findFirst(node *html.Node, target atom.Atom) *html.Node {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == target {
+			return child
+		}
+		if found := findFirst(child, target); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func attr(node *html.Node, key string) string {
+	for _, a := range node.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
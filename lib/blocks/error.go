@@ -0,0 +1,5 @@
+package blocks
+
+import "errors"
+
+var ErrBlocksInvalid = errors.New("content blocks invalid")
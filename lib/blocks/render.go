@@ -0,0 +1,142 @@
+package blocks
+
+import (
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	youtubeIDPattern = regexp.MustCompile(`^[\w-]{6,20}$`)
+	vimeoIDPattern   = regexp.MustCompile(`^\d+$`)
+)
+
+// RenderHTML renders blocks to HTML. Inline markup in text fields is limited
+// to the inline allowlist and the result is sanitized once more as a whole.
+func RenderHTML(blocks []entity.ContentBlock) string {
+	var b strings.Builder
+	for _, block := range blocks {
+		switch block.Type {
+		case entity.BlockParagraph:
+			fmt.Fprintf(&b, "<p>%s</p>\n", sanitizeInline(block.Text))
+		case entity.BlockHeading:
+			level := block.Level
+			if level == 0 {
+				level = 2
+			}
+			fmt.Fprintf(&b, "<h%d>%s</h%d>\n", level, sanitizeInline(block.Text), level)
+		case entity.BlockImage:
+			b.WriteString("<figure>")
+			fmt.Fprintf(&b, `<img src="%s" alt="%s" loading="lazy">`, html.EscapeString(block.Url), html.EscapeString(block.Alt))
+			if block.Caption != "" {
+				fmt.Fprintf(&b, "<figcaption>%s</figcaption>", sanitizeInline(block.Caption))
+			}
+			b.WriteString("</figure>\n")
+		case entity.BlockQuote:
+			fmt.Fprintf(&b, "<blockquote><p>%s</p>", sanitizeInline(block.Text))
+			if block.Caption != "" {
+				fmt.Fprintf(&b, "<cite>%s</cite>", sanitizeInline(block.Caption))
+			}
+			b.WriteString("</blockquote>\n")
+		case entity.BlockEmbed:
+			if src := embedSrc(block.Url); src != "" {
+				fmt.Fprintf(&b, `<figure class="embed"><iframe src="%s" loading="lazy" allowfullscreen></iframe>`, src)
+			} else {
+				fmt.Fprintf(&b, `<figure class="embed"><a href="%s">%s</a>`, html.EscapeString(block.Url), html.EscapeString(block.Url))
+			}
+			if block.Caption != "" {
+				fmt.Fprintf(&b, "<figcaption>%s</figcaption>", sanitizeInline(block.Caption))
+			}
+			b.WriteString("</figure>\n")
+		case entity.BlockList:
+			tag := "ul"
+			if block.Ordered {
+				tag = "ol"
+			}
+			fmt.Fprintf(&b, "<%s>", tag)
+			for _, item := range block.Items {
+				fmt.Fprintf(&b, "<li>%s</li>", sanitizeInline(item))
+			}
+			fmt.Fprintf(&b, "</%s>\n", tag)
+		case entity.BlockCode:
+			if block.Language != "" && languagePattern.MatchString(block.Language) {
+				fmt.Fprintf(&b, `<pre><code class="language-%s">%s</code></pre>`+"\n", block.Language, html.EscapeString(block.Text))
+			} else {
+				fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(block.Text))
+			}
+		}
+	}
+
+	return SanitizeHTML(b.String())
+}
+
+// RenderText renders blocks to plain text, one paragraph per block.
+func RenderText(blocks []entity.ContentBlock) string {
+	parts := []string{}
+	for _, block := range blocks {
+		switch block.Type {
+		case entity.BlockParagraph, entity.BlockHeading:
+			parts = append(parts, stripTags(block.Text))
+		case entity.BlockQuote:
+			text := stripTags(block.Text)
+			if block.Caption != "" {
+				text += "\n— " + stripTags(block.Caption)
+			}
+			parts = append(parts, text)
+		case entity.BlockImage, entity.BlockEmbed:
+			if block.Caption != "" {
+				parts = append(parts, stripTags(block.Caption))
+			}
+		case entity.BlockList:
+			items := make([]string, 0, len(block.Items))
+			for i, item := range block.Items {
+				prefix := "- "
+				if block.Ordered {
+					prefix = fmt.Sprintf("%d. ", i+1)
+				}
+				items = append(items, prefix+stripTags(item))
+			}
+			parts = append(parts, strings.Join(items, "\n"))
+		case entity.BlockCode:
+			parts = append(parts, block.Text)
+		}
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// embedSrc maps a YouTube or Vimeo page URL to its player URL. Other
+// providers are rendered as plain links.
+func embedSrc(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+
+	host := strings.TrimPrefix(parsed.Hostname(), "www.")
+	switch host {
+	case "youtube.com", "m.youtube.com":
+		id := parsed.Query().Get("v")
+		if strings.HasPrefix(parsed.Path, "/embed/") {
+			id = strings.TrimPrefix(parsed.Path, "/embed/")
+		}
+		if youtubeIDPattern.MatchString(id) {
+			return "https://www.youtube-nocookie.com/embed/" + id
+		}
+	case "youtu.be":
+		id := strings.TrimPrefix(parsed.Path, "/")
+		if youtubeIDPattern.MatchString(id) {
+			return "https://www.youtube-nocookie.com/embed/" + id
+		}
+	case "vimeo.com", "player.vimeo.com":
+		id := parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]
+		if vimeoIDPattern.MatchString(id) {
+			return "https://player.vimeo.com/video/" + id
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,68 @@
+package blocks
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+var (
+	embedSrcPattern = regexp.MustCompile(`^https://(www\.youtube-nocookie\.com/embed/|player\.vimeo\.com/video/)[\w-]+$`)
+	anchorPattern   = regexp.MustCompile(`^[\w:-]+$`)
+)
+
+var (
+	inlinePolicy = newInlinePolicy()
+	htmlPolicy   = newHTMLPolicy()
+	textPolicy   = bluemonday.StrictPolicy()
+)
+
+func newInlinePolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("b", "strong", "i", "em", "u", "s", "code", "br", "sub", "sup", "mark")
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireParseableURLs(true)
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return policy
+}
+
+func newHTMLPolicy() *bluemonday.Policy {
+	policy := newInlinePolicy()
+	policy.AllowElements("p", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "cite", "ul", "ol", "li",
+		"pre", "figure", "figcaption", "hr")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(language-[\w+#-]+|embed)$`)).OnElements("code", "figure")
+	policy.AllowAttrs("src", "alt").OnElements("img")
+	policy.AllowAttrs("loading").Matching(regexp.MustCompile(`^lazy$`)).OnElements("img", "iframe")
+	policy.AllowAttrs("src").Matching(embedSrcPattern).OnElements("iframe")
+	policy.AllowAttrs("allowfullscreen").OnElements("iframe")
+
+	// headings anchors, tables and footnotes as rendered from Markdown
+	policy.AllowRelativeURLs(true)
+	policy.AllowAttrs("id").Matching(anchorPattern).OnElements("h1", "h2", "h3", "h4", "h5", "h6", "li", "sup")
+	policy.AllowElements("table", "thead", "tbody", "tr", "th", "td", "div")
+	policy.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^(footnotes|footnote-ref|footnote-backref)$`)).OnElements("div", "a")
+	policy.AllowAttrs("role").Matching(regexp.MustCompile(`^doc-(noteref|backlink|endnotes)$`)).OnElements("a", "div")
+
+	return policy
+}
+
+// SanitizeHTML runs an HTML body through the allowlist. Anything that is
+// not a known text element, image or a whitelisted video embed is removed.
+func SanitizeHTML(raw string) string {
+	return strings.TrimSpace(htmlPolicy.Sanitize(raw))
+}
+
+func sanitizeInline(raw string) string {
+	return strings.TrimSpace(inlinePolicy.Sanitize(raw))
+}
+
+// stripTags returns the text content of an HTML fragment.
+func stripTags(raw string) string {
+	return strings.TrimSpace(html.UnescapeString(textPolicy.Sanitize(raw)))
+}
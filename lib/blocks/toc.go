@@ -0,0 +1,83 @@
+package blocks
+
+import (
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var anchorInvalidPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// TableOfContents collects the headings of a sanitized HTML body. Headings
+// without an id get one derived from their text so every entry can be
+// linked; the returned HTML carries those ids.
+func TableOfContents(raw string) (string, []entity.TocEntry, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(raw), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return "", nil, err
+	}
+
+	used := map[string]bool{}
+	var headings []*html.Node
+	var collect func(node *html.Node)
+	collect = func(node *html.Node) {
+		if node.Type == html.ElementNode {
+			if _, ok := headingLevels[node.DataAtom]; ok {
+				headings = append(headings, node)
+				if id := attr(node, "id"); id != "" {
+					used[id] = true
+				}
+				return
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			collect(child)
+		}
+	}
+	for _, node := range nodes {
+		collect(node)
+	}
+
+	toc := []entity.TocEntry{}
+	for _, node := range headings {
+		text := strings.Join(strings.Fields(textContent(node)), " ")
+		id := attr(node, "id")
+		if id == "" {
+			id = uniqueAnchor(text, used)
+			node.Attr = append(node.Attr, html.Attribute{Key: "id", Val: id})
+		}
+		toc = append(toc, entity.TocEntry{Level: headingLevels[node.DataAtom], ID: id, Text: text})
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		if err := html.Render(&b, node); err != nil {
+			return "", nil, err
+		}
+	}
+
+	return b.String(), toc, nil
+}
+
+func uniqueAnchor(text string, used map[string]bool) string {
+	base := strings.Trim(anchorInvalidPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if base == "" {
+		base = "heading"
+	}
+
+	id := base
+	for i := 1; used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[id] = true
+
+	return id
+}
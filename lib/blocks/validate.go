@@ -0,0 +1,77 @@
+// Package blocks validates structured content bodies and renders them to
+// sanitized HTML and plain text.
+package blocks
+
+import (
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const maxBlocks = 1000
+
+var languagePattern = regexp.MustCompile(`^[a-zA-Z0-9_+#-]{1,30}$`)
+
+func Validate(blocks []entity.ContentBlock) error {
+	// an empty list would save the content with no body at all
+	if len(blocks) == 0 {
+		return fmt.Errorf("%w: at least one block is required", ErrBlocksInvalid)
+	}
+	if len(blocks) > maxBlocks {
+		return fmt.Errorf("%w: at most %d blocks allowed", ErrBlocksInvalid, maxBlocks)
+	}
+
+	for i, block := range blocks {
+		if err := validateBlock(block); err != nil {
+			return fmt.Errorf("%w: block %d (%s): %s", ErrBlocksInvalid, i+1, block.Type, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func validateBlock(block entity.ContentBlock) error {
+	switch block.Type {
+	case entity.BlockParagraph, entity.BlockQuote:
+		if strings.TrimSpace(block.Text) == "" {
+			return fmt.Errorf("text is required")
+		}
+	case entity.BlockHeading:
+		if strings.TrimSpace(block.Text) == "" {
+			return fmt.Errorf("text is required")
+		}
+		if block.Level < 0 || block.Level > 6 {
+			return fmt.Errorf("level must be between 1 and 6")
+		}
+	case entity.BlockImage, entity.BlockEmbed:
+		if !isHttpUrl(block.Url) {
+			return fmt.Errorf("url must be an http or https url")
+		}
+	case entity.BlockList:
+		if len(block.Items) == 0 {
+			return fmt.Errorf("items are required")
+		}
+	case entity.BlockCode:
+		if block.Text == "" {
+			return fmt.Errorf("text is required")
+		}
+		if block.Language != "" && !languagePattern.MatchString(block.Language) {
+			return fmt.Errorf("language invalid")
+		}
+	default:
+		return fmt.Errorf("unknown block type")
+	}
+
+	return nil
+}
+
+func isHttpUrl(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
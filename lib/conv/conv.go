@@ -1,6 +1,9 @@
 package conv
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -17,9 +20,22 @@ func CheckPasswordHash(password, hash string) bool {
 	return err == nil
 }
 
+var slugInvalidPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// GenerateSlug joins the runs of ASCII letters and digits of title with
+// dashes. A title without any, such as one written in Arabic, CJK or Cyrillic
+// script, gets a short hash of itself instead, so only a blank title gives an
+// empty slug and the same title always gives the same slug.
 func GenerateSlug(title string) string {
 	slug := strings.ToLower(title)
-	slug = strings.ReplaceAll(slug, " ", "-")
+	slug = slugInvalidPattern.ReplaceAllString(slug, "-")
+	slug = strings.Trim(slug, "-")
+
+	if slug == "" && strings.TrimSpace(title) != "" {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(title)))
+		// the leading letter keeps it from reading as an ID
+		slug = "x" + hex.EncodeToString(sum[:5])
+	}
 
 	return slug
 }
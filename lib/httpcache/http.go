@@ -0,0 +1,67 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const localsKey = "httpcache"
+
+type validators struct {
+	lastModified time.Time
+	keys         []string
+}
+
+// Mark makes a successful response cacheable. lastModified is when the
+// newest thing in it changed, zero when that is not known, and keys are the
+// surrogate keys a CDN can purge it by.
+func Mark(c *fiber.Ctx, lastModified time.Time, keys ...string) {
+	c.Locals(localsKey, &validators{lastModified: lastModified, keys: keys})
+}
+
+// New adds validators and Cache-Control to GET responses marked with Mark and
+// answers conditional requests for them with 304 Not Modified. The ETag is a
+// hash of the body, so it changes whenever anything in the response does.
+// Responses that were not marked, such as errors and previews, pass through
+// untouched.
+func New(policy Policy) fiber.Handler {
+	cacheControl := policy.CacheControl()
+
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		v, ok := c.Locals(localsKey).(*validators)
+		if !ok || c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return nil
+		}
+
+		sum := sha256.Sum256(c.Response().Body())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		c.Set(fiber.HeaderETag, etag)
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+		if !v.lastModified.IsZero() {
+			c.Set(fiber.HeaderLastModified, v.lastModified.UTC().Format(http.TimeFormat))
+		}
+		if policy.SurrogateKeyHeader != "" && len(v.keys) > 0 {
+			c.Set(policy.SurrogateKeyHeader, policy.surrogateKeys(v.keys))
+		}
+
+		if notModified(c.Get(fiber.HeaderIfNoneMatch), c.Get(fiber.HeaderIfModifiedSince), etag, v.lastModified) {
+			c.Response().ResetBody()
+			c.Response().Header.Del(fiber.HeaderContentType)
+			c.Status(fiber.StatusNotModified)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy is what responses tell caches about themselves. Browsers keep them
+// for MaxAge, shared caches such as CDNs for SharedMaxAge, and both may serve
+// a stale copy while revalidating it in the background or while the origin
+// fails.
+type Policy struct {
+	MaxAge               time.Duration
+	SharedMaxAge         time.Duration
+	StaleWhileRevalidate time.Duration
+	StaleIfError         time.Duration
+	// SurrogateKeyHeader names the header listing the keys a CDN can purge
+	// the response by; none is sent when it is empty.
+	SurrogateKeyHeader string
+}
+
+// CacheControl renders the policy as a Cache-Control value.
+func (p Policy) CacheControl() string {
+	directives := []string{"public", "max-age=" + seconds(p.MaxAge)}
+	if p.SharedMaxAge > 0 {
+		directives = append(directives, "s-maxage="+seconds(p.SharedMaxAge))
+	}
+	if p.StaleWhileRevalidate > 0 {
+		directives = append(directives, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	}
+	if p.StaleIfError > 0 {
+		directives = append(directives, "stale-if-error="+seconds(p.StaleIfError))
+	}
+	return strings.Join(directives, ", ")
+}
+
+// surrogateKeys joins keys the way the configured header expects: Cloudflare
+// separates cache tags with commas, Fastly and Varnish surrogate keys with
+// spaces.
+func (p Policy) surrogateKeys(keys []string) string {
+	if strings.EqualFold(p.SurrogateKeyHeader, "Cache-Tag") {
+		return strings.Join(keys, ",")
+	}
+	return strings.Join(keys, " ")
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since against the
+// validators of a response, as in RFC 9110 section 13.2.2: If-Modified-Since
+// only counts when If-None-Match is absent.
+func notModified(ifNoneMatch, ifModifiedSince, etag string, lastModified time.Time) bool {
+	if ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag)
+	}
+
+	if ifModifiedSince == "" || lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches is the weak comparison of If-None-Match: W/ prefixes are
+// ignored and any tag of the list may match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(d / time.Second))
+}
@@ -0,0 +1,11 @@
+package imgtransform
+
+import "errors"
+
+var (
+	ErrSignatureInvalid = errors.New("image signature invalid")
+	ErrSizeInvalid      = errors.New("image size invalid: w and h must be between 0 and the maximum dimension")
+	ErrFitInvalid       = errors.New("image fit invalid: must be cover or contain")
+	ErrFormatInvalid    = errors.New("image format invalid: must be jpeg or png")
+	ErrSourceTooLarge   = errors.New("source image is too large")
+)
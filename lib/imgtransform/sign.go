@@ -0,0 +1,48 @@
+package imgtransform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"net/url"
+	"strconv"
+)
+
+// CanonicalQuery encodes the transformation parameters in a fixed order so
+// the same request always produces the same signature.
+func CanonicalQuery(opts entity.ImageTransformEntity) url.Values {
+	query := url.Values{}
+	query.Set("fit", opts.Fit)
+	query.Set("fmt", opts.Format)
+	query.Set("h", strconv.Itoa(opts.Height))
+	query.Set("w", strconv.Itoa(opts.Width))
+
+	return query
+}
+
+func Sign(secret string, id int64, opts entity.ImageTransformEntity) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d?%s", id, CanonicalQuery(opts).Encode())
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func Verify(secret string, id int64, opts entity.ImageTransformEntity, signature string) error {
+	if secret == "" || signature == "" {
+		return ErrSignatureInvalid
+	}
+
+	expected := Sign(secret, id, opts)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// SignedPath builds the public path of a transformed image.
+func SignedPath(secret string, id int64, opts entity.ImageTransformEntity) string {
+	return fmt.Sprintf("/img/%d?%s&sig=%s", id, CanonicalQuery(opts).Encode(), Sign(secret, id, opts))
+}
@@ -0,0 +1,141 @@
+// Package imgtransform crops and resizes images around a focal point.
+package imgtransform
+
+import (
+	"bytes"
+	"gonews/internal/core/domain/entity"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	FitCover   = "cover"
+	FitContain = "contain"
+
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+
+	maxSourcePixels = 50_000_000
+)
+
+// Normalize fills in defaults and validates the transformation parameters.
+func Normalize(opts entity.ImageTransformEntity, maxDimension int) (entity.ImageTransformEntity, error) {
+	if opts.Width < 0 || opts.Height < 0 || opts.Width > maxDimension || opts.Height > maxDimension || (opts.Width == 0 && opts.Height == 0) {
+		return opts, ErrSizeInvalid
+	}
+
+	if opts.Fit == "" {
+		opts.Fit = FitCover
+	}
+	if opts.Fit != FitCover && opts.Fit != FitContain {
+		return opts, ErrFitInvalid
+	}
+
+	if opts.Format == "" || opts.Format == "jpg" {
+		opts.Format = FormatJPEG
+	}
+	if opts.Format != FormatJPEG && opts.Format != FormatPNG {
+		return opts, ErrFormatInvalid
+	}
+
+	return opts, nil
+}
+
+// Decode reads an image after checking its dimensions, so a tiny file that
+// claims to be a huge image is rejected before it is decoded.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Width*config.Height > maxSourcePixels {
+		return nil, ErrSourceTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Transform resizes src to the requested box. With cover the image is
+// cropped to the target aspect ratio keeping the focal point (fractions of
+// width and height) as close to the centre as possible. A dimension left
+// out follows the source aspect ratio; when that would exceed maxDimension
+// both shrink to fit, so a panorama cannot blow up the output.
+func Transform(src image.Image, opts entity.ImageTransformEntity, focalX, focalY float64, maxDimension int) image.Image {
+	width, height := opts.Width, opts.Height
+	bounds := src.Bounds()
+	srcW, srcH := float64(bounds.Dx()), float64(bounds.Dy())
+
+	if width == 0 {
+		width = int(math.Round(srcW * float64(height) / srcH))
+		if width > maxDimension {
+			width = maxDimension
+			height = int(math.Round(srcH * float64(width) / srcW))
+		}
+	}
+	if height == 0 {
+		height = int(math.Round(srcH * float64(width) / srcW))
+		if height > maxDimension {
+			height = maxDimension
+			width = int(math.Round(srcW * float64(height) / srcH))
+		}
+	}
+	width, height = max(1, width), max(1, height)
+
+	crop := bounds
+	if opts.Fit == FitContain {
+		scale := math.Min(float64(width)/srcW, float64(height)/srcH)
+		width = max(1, int(math.Round(srcW*scale)))
+		height = max(1, int(math.Round(srcH*scale)))
+	} else {
+		aspect := float64(width) / float64(height)
+		cropW, cropH := srcW, srcH
+		if srcW/srcH > aspect {
+			cropW = srcH * aspect
+		} else {
+			cropH = srcW / aspect
+		}
+
+		x0 := clamp(focalX*srcW-cropW/2, 0, srcW-cropW)
+		y0 := clamp(focalY*srcH-cropH/2, 0, srcH-cropH)
+		crop = image.Rect(
+			bounds.Min.X+int(math.Round(x0)),
+			bounds.Min.Y+int(math.Round(y0)),
+			bounds.Min.X+int(math.Round(x0+cropW)),
+			bounds.Min.Y+int(math.Round(y0+cropH)),
+		)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	return dst
+}
+
+func Encode(w io.Writer, img image.Image, format string) error {
+	if format == FormatPNG {
+		return png.Encode(w, img)
+	}
+
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
+
+func ContentType(format string) string {
+	if format == FormatPNG {
+		return "image/png"
+	}
+
+	return "image/jpeg"
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(v, hi))
+}
@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	blockTagPattern  = regexp.MustCompile(`(?i)^<(p|div|h[1-6]|ul|ol|li|blockquote|pre|table|figure|hr|iframe|section|article)[\s>/]`)
+	blankLinePattern = regexp.MustCompile(`\n\s*\n`)
+	blockComment     = regexp.MustCompile(`<!--\s*/?wp:[^>]*-->`)
+)
+
+// Autop turns the blank-line separated text WordPress stores in classic
+// editor posts into paragraphs, the way wpautop does when rendering. Chunks
+// that already start with a block element and Gutenberg block comments are
+// left alone.
+func Autop(raw string) string {
+	raw = blockComment.ReplaceAllString(raw, "")
+	raw = strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n")
+
+	parts := []string{}
+	for _, chunk := range blankLinePattern.Split(raw, -1) {
+		chunk = strings.TrimSpace(chunk)
+		if chunk == "" {
+			continue
+		}
+
+		if blockTagPattern.MatchString(chunk) {
+			parts = append(parts, chunk)
+			continue
+		}
+
+		parts = append(parts, "<p>"+strings.ReplaceAll(chunk, "\n", "<br>\n")+"</p>")
+	}
+
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var csvColumns = []string{
+	"source_id", "source_url", "title", "slug", "excerpt", "description", "status",
+	"author_login", "author_email", "author_name", "category_slug", "category_title",
+	"tags", "image", "published_at",
+}
+
+// ParseCSV streams the documented CSV import format: a header row naming
+// some of csvColumns in any order, then one content per row. Authors and
+// categories are taken from the content columns.
+func ParseCSV(r io.ReadSeeker, h Handler) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFileInvalid, err)
+	}
+
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	if _, ok := index["title"]; !ok {
+		return fmt.Errorf("%w: missing title column, expected columns are %s", ErrFileInvalid, strings.Join(csvColumns, ","))
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrFileInvalid, err)
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		content, err := jsonContent{
+			SourceID:    field("source_id"),
+			SourceUrl:   field("source_url"),
+			Title:       field("title"),
+			Slug:        field("slug"),
+			Excerpt:     field("excerpt"),
+			Description: field("description"),
+			Status:      field("status"),
+			Author:      jsonAuthor{Login: field("author_login"), Email: field("author_email"), Name: field("author_name")},
+			Category:    jsonCategory{Slug: field("category_slug"), Title: field("category_title")},
+			Tags:        SplitTags(field("tags")),
+			Image:       field("image"),
+			PublishedAt: field("published_at"),
+		}.toEntity()
+		if err != nil {
+			return err
+		}
+
+		if err = h.Content(content); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package importer
+
+import "errors"
+
+var (
+	ErrFormatUnsupported = errors.New("import format unsupported: must be wxr, json or csv")
+	ErrFileInvalid       = errors.New("import file invalid")
+)
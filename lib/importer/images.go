@@ -0,0 +1,38 @@
+package importer
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ImageSources returns the distinct absolute http(s) image URLs referenced
+// by <img src> in an HTML body, in document order.
+func ImageSources(body string) []string {
+	seen := map[string]bool{}
+	sources := []string{}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return sources
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data != "img" {
+				continue
+			}
+
+			for _, attr := range token.Attr {
+				if attr.Key != "src" {
+					continue
+				}
+				src := strings.TrimSpace(attr.Val)
+				if (strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://")) && !seen[src] {
+					seen[src] = true
+					sources = append(sources, src)
+				}
+			}
+		}
+	}
+}
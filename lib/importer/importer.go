@@ -0,0 +1,65 @@
+// Package importer reads articles exported from other systems. Files are
+// streamed item by item into a Handler so exports with tens of thousands of
+// articles never have to fit in memory.
+package importer
+
+import (
+	"gonews/internal/core/domain/entity"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Handler receives the records of an import file in order: authors and
+// categories first where the format lists them separately, then contents.
+type Handler interface {
+	Author(author entity.ImportAuthorEntity) error
+	Category(category entity.ImportCategoryEntity) error
+	Content(content entity.ImportContentEntity) error
+}
+
+// Parse streams an import file of the given format into h. WXR files are
+// read twice to resolve featured images, hence the io.ReadSeeker.
+func Parse(format string, r io.ReadSeeker, h Handler) error {
+	switch format {
+	case entity.ImportFormatWXR:
+		return ParseWXR(r, h)
+	case entity.ImportFormatJSON:
+		return ParseJSON(r, h)
+	case entity.ImportFormatCSV:
+		return ParseCSV(r, h)
+	}
+
+	return ErrFormatUnsupported
+}
+
+// SourcePath returns the path and query of an old article URL, which is
+// what redirects are matched on.
+func SourcePath(rawUrl string) string {
+	u, err := url.Parse(strings.TrimSpace(rawUrl))
+	if err != nil || (u.Path == "" && u.RawQuery == "") {
+		return ""
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	return path
+}
+
+// SplitTags splits a comma separated tag list, dropping blanks.
+func SplitTags(raw string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(raw, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,161 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"io"
+	"time"
+)
+
+type jsonAuthor struct {
+	Login string `json:"login"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+type jsonCategory struct {
+	Slug  string `json:"slug"`
+	Title string `json:"title"`
+}
+
+type jsonContent struct {
+	SourceID    string       `json:"source_id"`
+	SourceUrl   string       `json:"source_url"`
+	Title       string       `json:"title"`
+	Slug        string       `json:"slug"`
+	Excerpt     string       `json:"excerpt"`
+	Description string       `json:"description"`
+	Status      string       `json:"status"`
+	Author      jsonAuthor   `json:"author"`
+	Category    jsonCategory `json:"category"`
+	Tags        []string     `json:"tags"`
+	Image       string       `json:"image"`
+	PublishedAt string       `json:"published_at"`
+}
+
+// ParseJSON streams the documented JSON import format: an object with
+// optional "authors" and "categories" arrays and a "contents" array. Each
+// array is decoded one element at a time.
+func ParseJSON(r io.ReadSeeker, h Handler) error {
+	d := json.NewDecoder(r)
+	if err := expectDelim(d, '{'); err != nil {
+		return err
+	}
+
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrFileInvalid, err)
+		}
+
+		key, _ := token.(string)
+		switch key {
+		case "authors":
+			err = decodeArray(d, func() error {
+				var author jsonAuthor
+				if err := d.Decode(&author); err != nil {
+					return err
+				}
+				return h.Author(entity.ImportAuthorEntity(author))
+			})
+		case "categories":
+			err = decodeArray(d, func() error {
+				var category jsonCategory
+				if err := d.Decode(&category); err != nil {
+					return err
+				}
+				return h.Category(entity.ImportCategoryEntity(category))
+			})
+		case "contents":
+			err = decodeArray(d, func() error {
+				var content jsonContent
+				if err := d.Decode(&content); err != nil {
+					return err
+				}
+				resp, err := content.toEntity()
+				if err != nil {
+					return err
+				}
+				return h.Content(resp)
+			})
+		default:
+			var skip json.RawMessage
+			err = d.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c jsonContent) toEntity() (entity.ImportContentEntity, error) {
+	resp := entity.ImportContentEntity{
+		SourceKey:   c.SourceID,
+		SourceUrl:   c.SourceUrl,
+		Title:       c.Title,
+		Slug:        c.Slug,
+		Excerpt:     c.Excerpt,
+		Description: c.Description,
+		Status:      importStatus(c.Status),
+		Author:      entity.ImportAuthorEntity(c.Author),
+		Category:    entity.ImportCategoryEntity(c.Category),
+		Tags:        c.Tags,
+		Image:       c.Image,
+	}
+	if resp.SourceKey == "" {
+		resp.SourceKey = c.SourceUrl
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+
+	if c.PublishedAt != "" {
+		publishedAt, err := time.Parse(time.RFC3339, c.PublishedAt)
+		if err != nil {
+			return resp, fmt.Errorf("%w: published_at of %q must be RFC 3339", ErrFileInvalid, c.SourceID)
+		}
+		resp.PublishedAt = publishedAt
+	}
+
+	return resp, nil
+}
+
+func expectDelim(d *json.Decoder, delim json.Delim) error {
+	token, err := d.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFileInvalid, err)
+	}
+	if token != delim {
+		return fmt.Errorf("%w: expected %q", ErrFileInvalid, delim)
+	}
+
+	return nil
+}
+
+func decodeArray(d *json.Decoder, fn func() error) error {
+	if err := expectDelim(d, '['); err != nil {
+		return err
+	}
+
+	for d.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(d, ']')
+}
+
+// importStatus maps the status of the import formats onto content
+// statuses; anything but "publish" is imported as a draft.
+func importStatus(status string) string {
+	switch status {
+	case "publish", "published", "PUBLISH":
+		return "PUBLISH"
+	}
+
+	return "DRAFT"
+}
@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"io"
+	"strings"
+	"time"
+)
+
+const contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+
+type wxrAuthor struct {
+	Login       string `xml:"author_login"`
+	Email       string `xml:"author_email"`
+	DisplayName string `xml:"author_display_name"`
+}
+
+type wxrCategory struct {
+	Nicename string `xml:"category_nicename"`
+	Name     string `xml:"cat_name"`
+}
+
+type wxrEncoded struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type wxrTerm struct {
+	Domain   string `xml:"domain,attr"`
+	Nicename string `xml:"nicename,attr"`
+	Name     string `xml:",chardata"`
+}
+
+type wxrMeta struct {
+	Key   string `xml:"meta_key"`
+	Value string `xml:"meta_value"`
+}
+
+type wxrItem struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Guid          string       `xml:"guid"`
+	Creator       string       `xml:"creator"`
+	Encoded       []wxrEncoded `xml:"encoded"`
+	PostID        string       `xml:"post_id"`
+	PostDateGmt   string       `xml:"post_date_gmt"`
+	PostDate      string       `xml:"post_date"`
+	PostName      string       `xml:"post_name"`
+	Status        string       `xml:"status"`
+	PostType      string       `xml:"post_type"`
+	AttachmentUrl string       `xml:"attachment_url"`
+	Terms         []wxrTerm    `xml:"category"`
+	Meta          []wxrMeta    `xml:"postmeta"`
+}
+
+// ParseWXR streams a WordPress eXtended RSS export. The first pass collects
+// attachment URLs so featured images (_thumbnail_id) can be resolved; the
+// second emits authors, categories and posts. Pages, attachments, menu
+// items and trashed or auto-draft posts are skipped.
+func ParseWXR(r io.ReadSeeker, h Handler) error {
+	attachments := map[string]string{}
+	err := walkWXR(r, func(name string, d *xml.Decoder, start *xml.StartElement) error {
+		if name != "item" {
+			return d.Skip()
+		}
+
+		var item wxrItem
+		if err := d.DecodeElement(&item, start); err != nil {
+			return err
+		}
+		if item.PostType == "attachment" && item.AttachmentUrl != "" {
+			attachments[item.PostID] = strings.TrimSpace(item.AttachmentUrl)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	authors := map[string]entity.ImportAuthorEntity{}
+	return walkWXR(r, func(name string, d *xml.Decoder, start *xml.StartElement) error {
+		switch name {
+		case "author":
+			var author wxrAuthor
+			if err := d.DecodeElement(&author, start); err != nil {
+				return err
+			}
+			resp := entity.ImportAuthorEntity{
+				Login: strings.TrimSpace(author.Login),
+				Email: strings.TrimSpace(author.Email),
+				Name:  strings.TrimSpace(author.DisplayName),
+			}
+			authors[resp.Login] = resp
+
+			return h.Author(resp)
+		case "category":
+			var category wxrCategory
+			if err := d.DecodeElement(&category, start); err != nil {
+				return err
+			}
+
+			return h.Category(entity.ImportCategoryEntity{
+				Slug:  strings.TrimSpace(category.Nicename),
+				Title: strings.TrimSpace(category.Name),
+			})
+		case "item":
+			var item wxrItem
+			if err := d.DecodeElement(&item, start); err != nil {
+				return err
+			}
+			if item.PostType != "post" {
+				return nil
+			}
+
+			content, ok := wxrContent(item, authors, attachments)
+			if !ok {
+				return nil
+			}
+
+			return h.Content(content)
+		}
+
+		return d.Skip()
+	})
+}
+
+// walkWXR calls fn for every direct child of <channel>.
+func walkWXR(r io.Reader, fn func(name string, d *xml.Decoder, start *xml.StartElement) error) error {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+
+	inChannel := false
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			if !inChannel {
+				return fmt.Errorf("%w: no rss channel found", ErrFileInvalid)
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrFileInvalid, err)
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			if !inChannel {
+				inChannel = el.Name.Local == "channel"
+				continue
+			}
+			if err := fn(el.Name.Local, d, &el); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			if el.Name.Local == "channel" {
+				return nil
+			}
+		}
+	}
+}
+
+func wxrContent(item wxrItem, authors map[string]entity.ImportAuthorEntity, attachments map[string]string) (entity.ImportContentEntity, bool) {
+	status := "DRAFT"
+	switch item.Status {
+	case "publish":
+		status = "PUBLISH"
+	case "draft", "pending", "private", "future":
+	default:
+		return entity.ImportContentEntity{}, false
+	}
+
+	content := entity.ImportContentEntity{
+		SourceKey: strings.TrimSpace(item.Guid),
+		SourceUrl: strings.TrimSpace(item.Link),
+		Title:     strings.TrimSpace(item.Title),
+		Slug:      strings.TrimSpace(item.PostName),
+		Status:    status,
+		Tags:      []string{},
+	}
+	if content.SourceKey == "" {
+		content.SourceKey = "wp:" + item.PostID
+	}
+
+	for _, encoded := range item.Encoded {
+		if encoded.XMLName.Space == contentNamespace {
+			content.Description = Autop(encoded.Value)
+		} else {
+			content.Excerpt = strings.TrimSpace(encoded.Value)
+		}
+	}
+
+	if author, ok := authors[item.Creator]; ok {
+		content.Author = author
+	} else {
+		content.Author = entity.ImportAuthorEntity{Login: item.Creator}
+	}
+
+	for _, term := range item.Terms {
+		switch term.Domain {
+		case "category":
+			if content.Category.Slug == "" {
+				content.Category = entity.ImportCategoryEntity{Slug: term.Nicename, Title: strings.TrimSpace(term.Name)}
+			}
+		case "post_tag":
+			content.Tags = append(content.Tags, strings.TrimSpace(term.Name))
+		}
+	}
+
+	for _, meta := range item.Meta {
+		if meta.Key == "_thumbnail_id" {
+			content.Image = attachments[strings.TrimSpace(meta.Value)]
+		}
+	}
+
+	for _, value := range []string{item.PostDateGmt, item.PostDate} {
+		if publishedAt, err := time.Parse("2006-01-02 15:04:05", strings.TrimSpace(value)); err == nil && publishedAt.Year() > 1 {
+			content.PublishedAt = publishedAt
+			break
+		}
+	}
+
+	return content, true
+}
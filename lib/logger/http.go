@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"gonews/lib/tracing"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+const maxRequestIDLength = 128
+
+// Middleware gives every request an X-Request-ID, keeping a well formed one
+// sent by the client or a proxy, and hands handlers a logger through
+// c.UserContext() that stamps each line with the request id, route and
+// latency so far. It logs one line per request when the handler returns.
+func Middleware(base zerolog.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		hook := &requestHook{c: c, start: time.Now()}
+
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(fiber.HeaderXRequestID, requestID)
+
+		fields := base.With().
+			Str("request_id", requestID).
+			Str("method", c.Method()).
+			Str("path", c.Path())
+		if traceID := tracing.TraceID(c.UserContext()); traceID != "" {
+			fields = fields.Str("trace_id", traceID)
+		}
+		l := fields.Logger().Hook(hook)
+		c.SetUserContext(l.WithContext(c.UserContext()))
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		hook.finish(status)
+
+		level := zerolog.InfoLevel
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = zerolog.ErrorLevel
+		case status >= fiber.StatusBadRequest:
+			level = zerolog.WarnLevel
+		}
+
+		zerolog.Ctx(c.UserContext()).WithLevel(level).
+			Err(err).
+			Int("status", status).
+			Str("ip", c.IP()).
+			Int("bytes", len(c.Response().Body())).
+			Msg("request")
+
+		return err
+	}
+}
+
+// SetUser adds the authenticated user to every line the request logs from
+// here on.
+func SetUser(c *fiber.Ctx, userID int64) {
+	zerolog.Ctx(c.UserContext()).UpdateContext(func(l zerolog.Context) zerolog.Context {
+		return l.Int64("user_id", userID)
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// requestHook adds the route and the time since the request started to each
+// line. The route is read from the live context while the request runs and
+// frozen once it returns, as Fiber reuses the context afterwards.
+type requestHook struct {
+	c     *fiber.Ctx
+	start time.Time
+
+	mu    sync.Mutex
+	route string
+	done  bool
+}
+
+func (h *requestHook) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	e.Str("route", h.currentRoute()).Dur("latency", time.Since(h.start))
+}
+
+func (h *requestHook) currentRoute() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.done {
+		return h.c.Route().Path
+	}
+	return h.route
+}
+
+func (h *requestHook) finish(status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.route = h.c.Route().Path
+	if status == fiber.StatusNotFound && h.route == "/" {
+		h.route = "unmatched"
+	}
+	h.done = true
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"gonews/config"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// New builds the application logger: JSON lines when cfg.Log.Format is json,
+// or when it is empty and the app runs in production, readable console
+// output otherwise.
+func New(cfg *config.Config) zerolog.Logger {
+	level, err := zerolog.ParseLevel(strings.ToLower(cfg.Log.Level))
+	if err != nil || level == zerolog.NoLevel {
+		level = zerolog.InfoLevel
+	}
+
+	format := strings.ToLower(cfg.Log.Format)
+	if format == "" {
+		format = FormatConsole
+		if cfg.App.AppEnv == "production" {
+			format = FormatJSON
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if format == FormatConsole {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+
+	return zerolog.New(out).Level(level).With().Timestamp().Caller().Logger()
+}
+
+// Setup makes l the logger for code that logs without a request, both
+// through zerolog.Ctx and the zerolog/log package.
+func Setup(l zerolog.Logger) {
+	log.Logger = l
+	zerolog.DefaultContextLogger = &l
+}
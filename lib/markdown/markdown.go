@@ -0,0 +1,29 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+var md = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Footnote,
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// Render converts CommonMark with the table and footnote extensions to HTML.
+// Raw HTML in the source is dropped by the renderer; the output still has to
+// go through the sanitizer before it is stored.
+func Render(source string) (string, error) {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -0,0 +1,8 @@
+package mediaprobe
+
+import "errors"
+
+var (
+	ErrUnknownFormat = errors.New("unknown media format")
+	ErrInvalidMedia  = errors.New("invalid or truncated media file")
+)
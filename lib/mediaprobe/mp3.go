@@ -0,0 +1,229 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+var (
+	mp3BitratesV1 = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3BitratesV2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+	mp3Samplerate = [3]int{44100, 48000, 32000}
+)
+
+const maxID3Size = 16 << 20
+
+type mp3Frame struct {
+	mpeg1      bool
+	bitrate    int
+	sampleRate int
+	channels   int
+}
+
+func (f mp3Frame) samplesPerFrame() int {
+	if f.mpeg1 {
+		return 1152
+	}
+	return 576
+}
+
+// sideInfoSize is the offset of a Xing/Info header after the frame header.
+func (f mp3Frame) sideInfoSize() int {
+	switch {
+	case f.mpeg1 && f.channels == 1:
+		return 17
+	case f.mpeg1:
+		return 32
+	case f.channels == 1:
+		return 9
+	default:
+		return 17
+	}
+}
+
+func probeMP3(r io.ReadSeeker, size int64) (*Info, error) {
+	info := &Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3"}
+
+	var audioStart int64
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, ErrInvalidMedia
+	}
+
+	if string(header[0:3]) == "ID3" {
+		tagSize := int64(syncsafe(header[6:10]))
+		audioStart = 10 + tagSize
+		if header[5]&0x10 != 0 {
+			audioStart += 10
+		}
+
+		if tagSize <= maxID3Size {
+			tag := make([]byte, tagSize)
+			if _, err := io.ReadFull(r, tag); err == nil {
+				info.Artwork, info.ArtworkMime = parseID3Artwork(tag, header[3])
+			}
+		}
+	}
+
+	if _, err := r.Seek(audioStart, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Look for the first frame sync within a reasonable window, some
+	// encoders pad between the tag and the audio data.
+	buf := make([]byte, 64<<10)
+	n, _ := io.ReadFull(r, buf)
+	buf = buf[:n]
+
+	for i := 0; i+4 <= len(buf); i++ {
+		frame, ok := parseMP3Header(buf[i : i+4])
+		if !ok {
+			continue
+		}
+
+		info.SampleRate = frame.sampleRate
+		info.Channels = frame.channels
+
+		frames := readMP3FrameCount(buf[i:], frame)
+		switch {
+		case frames > 0:
+			info.DurationMs = int64(frames) * int64(frame.samplesPerFrame()) * 1000 / int64(frame.sampleRate)
+		case frame.bitrate > 0 && size > audioStart+int64(i):
+			// the bitrate is in bits per second
+			info.DurationMs = (size - audioStart - int64(i)) * 8 * 1000 / int64(frame.bitrate)
+		}
+
+		return info, nil
+	}
+
+	return nil, ErrInvalidMedia
+}
+
+func parseMP3Header(b []byte) (mp3Frame, bool) {
+	if b[0] != 0xFF || b[1]&0xE0 != 0xE0 {
+		return mp3Frame{}, false
+	}
+
+	version := (b[1] >> 3) & 0x03
+	layer := (b[1] >> 1) & 0x03
+	bitrateIndex := b[2] >> 4
+	sampleIndex := (b[2] >> 2) & 0x03
+
+	// Only MPEG audio layer III is supported; version 1 is reserved.
+	if version == 1 || layer != 1 || bitrateIndex == 0x0F || sampleIndex == 0x03 {
+		return mp3Frame{}, false
+	}
+
+	frame := mp3Frame{mpeg1: version == 3, channels: 2}
+	if b[3]>>6 == 0x03 {
+		frame.channels = 1
+	}
+
+	frame.sampleRate = mp3Samplerate[sampleIndex]
+	switch version {
+	case 3:
+		frame.bitrate = mp3BitratesV1[bitrateIndex] * 1000
+	case 2:
+		frame.sampleRate /= 2
+		frame.bitrate = mp3BitratesV2[bitrateIndex] * 1000
+	case 0:
+		frame.sampleRate /= 4
+		frame.bitrate = mp3BitratesV2[bitrateIndex] * 1000
+	}
+
+	return frame, true
+}
+
+// readMP3FrameCount reads the frame count from a Xing/Info or VBRI header in
+// the first frame, returning 0 for plain CBR files.
+func readMP3FrameCount(buf []byte, frame mp3Frame) int {
+	xing := 4 + frame.sideInfoSize()
+	if len(buf) >= xing+12 {
+		tag := string(buf[xing : xing+4])
+		if (tag == "Xing" || tag == "Info") && buf[xing+7]&0x01 != 0 {
+			return int(binary.BigEndian.Uint32(buf[xing+8 : xing+12]))
+		}
+	}
+
+	vbri := 4 + 32
+	if len(buf) >= vbri+18 && string(buf[vbri:vbri+4]) == "VBRI" {
+		return int(binary.BigEndian.Uint32(buf[vbri+14 : vbri+18]))
+	}
+
+	return 0
+}
+
+// parseID3Artwork returns the first attached picture (APIC frame) of an
+// ID3v2.3 or ID3v2.4 tag.
+func parseID3Artwork(tag []byte, version byte) ([]byte, string) {
+	if version != 3 && version != 4 {
+		return nil, ""
+	}
+
+	for len(tag) >= 10 && tag[0] != 0 {
+		id := string(tag[0:4])
+		frameSize := int(binary.BigEndian.Uint32(tag[4:8]))
+		if version == 4 {
+			frameSize = int(syncsafe(tag[4:8]))
+		}
+
+		if frameSize <= 0 || 10+frameSize > len(tag) {
+			return nil, ""
+		}
+
+		if id == "APIC" {
+			return parseAPIC(tag[10 : 10+frameSize])
+		}
+
+		tag = tag[10+frameSize:]
+	}
+
+	return nil, ""
+}
+
+func parseAPIC(frame []byte) ([]byte, string) {
+	if len(frame) < 4 {
+		return nil, ""
+	}
+
+	encoding := frame[0]
+	frame = frame[1:]
+
+	end := bytes.IndexByte(frame, 0)
+	if end < 0 {
+		return nil, ""
+	}
+	mime := string(frame[:end])
+	frame = frame[end+1:]
+
+	// Skip the picture type byte and the description.
+	if len(frame) < 1 {
+		return nil, ""
+	}
+	frame = frame[1:]
+
+	terminator := []byte{0}
+	if encoding == 1 || encoding == 2 {
+		terminator = []byte{0, 0}
+	}
+
+	for i := 0; i+len(terminator) <= len(frame); i += len(terminator) {
+		if bytes.Equal(frame[i:i+len(terminator)], terminator) {
+			frame = frame[i+len(terminator):]
+			if mime == "" || mime == "-->" {
+				return nil, ""
+			}
+			if mime == "jpg" || mime == "JPG" {
+				mime = "image/jpeg"
+			}
+			return frame, mime
+		}
+	}
+
+	return nil, ""
+}
+
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
@@ -0,0 +1,142 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// mpeg1Frame is the header of an MPEG-1 layer III frame at 128kbps and
+// 44.1kHz.
+var mpeg1Frame = []byte{0xFF, 0xFB, 0x90, 0x00}
+
+// mp3Fixture is a file of size bytes that starts with prefix and a frame
+// header, with the first frame filled in by fill.
+func mp3Fixture(prefix []byte, size int, header []byte, fill func(frame []byte)) []byte {
+	file := make([]byte, size)
+	copy(file, prefix)
+	frame := file[len(prefix):]
+	copy(frame, header)
+	if fill != nil {
+		fill(frame)
+	}
+	return file
+}
+
+// id3Tag is an ID3v2.3 tag holding frames.
+func id3Tag(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	size := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0, byte(size >> 21 & 0x7F), byte(size >> 14 & 0x7F), byte(size >> 7 & 0x7F), byte(size & 0x7F)}
+	return append(header, body...)
+}
+
+func id3Frame(id string, payload []byte) []byte {
+	frame := make([]byte, 10, 10+len(payload))
+	copy(frame, id)
+	binary.BigEndian.PutUint32(frame[4:8], uint32(len(payload)))
+	return append(frame, payload...)
+}
+
+func TestProbeMP3(t *testing.T) {
+	picture := []byte{0x89, 'P', 'N', 'G'}
+	apic := append([]byte("\x00image/png\x00\x03cover\x00"), picture...)
+	tag := id3Tag(id3Frame("APIC", apic))
+
+	tests := []struct {
+		name string
+		file []byte
+		// size is the size reported to Probe, the length of file when 0.
+		size    int64
+		want    Info
+		wantErr error
+	}{
+		{
+			name: "constant bitrate",
+			file: mp3Fixture(nil, 16000, mpeg1Frame, nil),
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", DurationMs: 1000, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "constant bitrate after an id3 tag with artwork",
+			file: mp3Fixture(tag, len(tag)+32000, mpeg1Frame, nil),
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", DurationMs: 2000, SampleRate: 44100, Channels: 2, Artwork: picture, ArtworkMime: "image/png"},
+		},
+		{
+			name: "mono mpeg 2",
+			file: mp3Fixture(nil, 8000, []byte{0xFF, 0xF3, 0x80, 0xC0}, nil),
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", DurationMs: 1000, SampleRate: 22050, Channels: 1},
+		},
+		{
+			name: "xing header",
+			file: mp3Fixture(nil, 4000, mpeg1Frame, func(frame []byte) {
+				copy(frame[36:], "Xing")
+				frame[43] = 0x01
+				binary.BigEndian.PutUint32(frame[44:48], 100)
+			}),
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", DurationMs: 2612, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "vbri header",
+			file: mp3Fixture(nil, 4000, mpeg1Frame, func(frame []byte) {
+				copy(frame[36:], "VBRI")
+				binary.BigEndian.PutUint32(frame[50:54], 441)
+			}),
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", DurationMs: 11520, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "reported size before the first frame",
+			file: mp3Fixture(tag, len(tag)+32000, mpeg1Frame, nil),
+			size: 16,
+			want: Info{Container: "mp3", MimeType: "audio/mpeg", AudioCodec: "mp3", SampleRate: 44100, Channels: 2, Artwork: picture, ArtworkMime: "image/png"},
+		},
+		{
+			name:    "tag longer than the file",
+			file:    mp3Fixture(nil, 64, []byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0x10, 0}, nil),
+			wantErr: ErrInvalidMedia,
+		},
+		{
+			name:    "no frame",
+			file:    append(id3Tag(), make([]byte, 1000)...),
+			wantErr: ErrInvalidMedia,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.file))
+			}
+
+			got, err := Probe(bytes.NewReader(tt.file), size)
+			assertInfo(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
+
+func TestProbeUnknownFormat(t *testing.T) {
+	file := []byte("%PDF-1.7 not media")
+	if _, err := Probe(bytes.NewReader(file), int64(len(file))); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("Probe() error = %v, want %v", err, ErrUnknownFormat)
+	}
+}
+
+func assertInfo(t *testing.T, got *Info, err error, want Info, wantErr error) {
+	t.Helper()
+
+	if wantErr != nil {
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("Probe() error = %v, want %v", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Probe() = %+v, want %+v", *got, want)
+	}
+}
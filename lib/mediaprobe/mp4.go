@@ -0,0 +1,238 @@
+package mediaprobe
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+)
+
+const maxMoovSize = 64 << 20
+
+type mp4Box struct {
+	kind    string
+	payload []byte
+}
+
+// readMP4Boxes splits buf into its child boxes.
+func readMP4Boxes(buf []byte) []mp4Box {
+	var boxes []mp4Box
+	for len(buf) >= 8 {
+		size := uint64(binary.BigEndian.Uint32(buf[0:4]))
+		kind := string(buf[4:8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(buf))
+		case 1:
+			if len(buf) < 16 {
+				return boxes
+			}
+			size = binary.BigEndian.Uint64(buf[8:16])
+			header = 16
+		}
+
+		if size < header || size > uint64(len(buf)) {
+			return boxes
+		}
+
+		boxes = append(boxes, mp4Box{kind: kind, payload: buf[header:size]})
+		buf = buf[size:]
+	}
+
+	return boxes
+}
+
+func findMP4Box(boxes []mp4Box, path ...string) *mp4Box {
+	for i := range boxes {
+		if boxes[i].kind != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return &boxes[i]
+		}
+
+		payload := boxes[i].payload
+		// meta is a full box in ISO files but a plain container in QuickTime.
+		if boxes[i].kind == "meta" && len(payload) >= 8 && string(payload[4:8]) != "hdlr" {
+			payload = payload[4:]
+		}
+
+		if found := findMP4Box(readMP4Boxes(payload), path[1:]...); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+func probeMP4(r io.ReadSeeker, size int64) (*Info, error) {
+	moov, err := readMP4Moov(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &Info{Container: "mp4", MimeType: "video/mp4"}
+	boxes := readMP4Boxes(moov)
+
+	if mvhd := findMP4Box(boxes, "mvhd"); mvhd != nil {
+		timescale, duration := parseMP4Duration(mvhd.payload)
+		// Fragmented files leave mvhd empty and carry the total in mvex/mehd.
+		if mehd := findMP4Box(boxes, "mvex", "mehd"); duration == 0 && mehd != nil {
+			duration = parseMP4FragmentDuration(mehd.payload)
+		}
+		if timescale > 0 {
+			info.DurationMs = int64(duration * 1000 / timescale)
+		}
+	}
+
+	for _, trak := range boxes {
+		if trak.kind != "trak" {
+			continue
+		}
+		parseMP4Track(readMP4Boxes(trak.payload), info)
+	}
+
+	if covr := findMP4Box(boxes, "udta", "meta", "ilst", "covr", "data"); covr != nil && len(covr.payload) > 8 {
+		info.Artwork = covr.payload[8:]
+		info.ArtworkMime = "image/jpeg"
+		if binary.BigEndian.Uint32(covr.payload[0:4])&0xFFFFFF == 14 {
+			info.ArtworkMime = "image/png"
+		}
+	}
+
+	if !info.HasVideo() && info.AudioCodec != "" {
+		info.MimeType = "audio/mp4"
+	}
+
+	return info, nil
+}
+
+// readMP4Moov walks the top level boxes and loads the moov box into memory.
+func readMP4Moov(r io.ReadSeeker, size int64) ([]byte, error) {
+	var offset int64
+	header := make([]byte, 16)
+	for offset+8 <= size {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return nil, ErrInvalidMedia
+		}
+
+		boxSize := int64(binary.BigEndian.Uint32(header[0:4]))
+		kind := string(header[4:8])
+		headerSize := int64(8)
+
+		switch boxSize {
+		case 0:
+			boxSize = size - offset
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, ErrInvalidMedia
+			}
+			boxSize = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+
+		if boxSize < headerSize {
+			return nil, ErrInvalidMedia
+		}
+
+		if kind == "moov" {
+			if boxSize-headerSize > maxMoovSize {
+				return nil, ErrInvalidMedia
+			}
+			moov := make([]byte, boxSize-headerSize)
+			if _, err := io.ReadFull(r, moov); err != nil {
+				return nil, ErrInvalidMedia
+			}
+			return moov, nil
+		}
+
+		offset += boxSize
+	}
+
+	return nil, ErrInvalidMedia
+}
+
+func parseMP4Duration(payload []byte) (timescale, duration uint64) {
+	if len(payload) < 1 {
+		return 0, 0
+	}
+
+	if payload[0] == 1 {
+		if len(payload) < 32 {
+			return 0, 0
+		}
+		return uint64(binary.BigEndian.Uint32(payload[20:24])), binary.BigEndian.Uint64(payload[24:32])
+	}
+
+	if len(payload) < 20 {
+		return 0, 0
+	}
+	return uint64(binary.BigEndian.Uint32(payload[12:16])), uint64(binary.BigEndian.Uint32(payload[16:20]))
+}
+
+func parseMP4FragmentDuration(payload []byte) uint64 {
+	if len(payload) >= 12 && payload[0] == 1 {
+		return binary.BigEndian.Uint64(payload[4:12])
+	}
+	if len(payload) >= 8 {
+		return uint64(binary.BigEndian.Uint32(payload[4:8]))
+	}
+
+	return 0
+}
+
+func parseMP4Track(trak []mp4Box, info *Info) {
+	hdlr := findMP4Box(trak, "mdia", "hdlr")
+	stsd := findMP4Box(trak, "mdia", "minf", "stbl", "stsd")
+	if hdlr == nil || stsd == nil || len(hdlr.payload) < 12 || len(stsd.payload) < 16 {
+		return
+	}
+
+	handler := string(hdlr.payload[8:12])
+	entry := stsd.payload[8:]
+	codec := strings.TrimSpace(string(entry[4:8]))
+
+	switch handler {
+	case "vide":
+		if info.VideoCodec != "" {
+			return
+		}
+		info.VideoCodec = codec
+		if len(entry) >= 36 {
+			info.Width = int(binary.BigEndian.Uint16(entry[32:34]))
+			info.Height = int(binary.BigEndian.Uint16(entry[34:36]))
+		}
+		if tkhd := findMP4Box(trak, "tkhd"); tkhd != nil && (info.Width == 0 || info.Height == 0) {
+			info.Width, info.Height = parseMP4TrackSize(tkhd.payload)
+		}
+	case "soun":
+		if info.AudioCodec != "" {
+			return
+		}
+		info.AudioCodec = codec
+		if len(entry) >= 36 {
+			info.Channels = int(binary.BigEndian.Uint16(entry[24:26]))
+			info.SampleRate = int(binary.BigEndian.Uint32(entry[32:36]) >> 16)
+		}
+	}
+}
+
+func parseMP4TrackSize(payload []byte) (int, int) {
+	offset := 76
+	if len(payload) > 0 && payload[0] == 1 {
+		offset = 88
+	}
+
+	if len(payload) < offset+8 {
+		return 0, 0
+	}
+
+	width := binary.BigEndian.Uint32(payload[offset : offset+4])
+	height := binary.BigEndian.Uint32(payload[offset+4 : offset+8])
+
+	return int(width >> 16), int(height >> 16)
+}
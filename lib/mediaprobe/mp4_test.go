@@ -0,0 +1,114 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func box(kind string, children ...[]byte) []byte {
+	payload := bytes.Join(children, nil)
+	box := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(box[0:4], uint32(8+len(payload)))
+	copy(box[4:8], kind)
+	return append(box, payload...)
+}
+
+// mvhd is a version 0 movie header.
+func mvhd(timescale, duration uint32) []byte {
+	payload := make([]byte, 100)
+	binary.BigEndian.PutUint32(payload[12:16], timescale)
+	binary.BigEndian.PutUint32(payload[16:20], duration)
+	return box("mvhd", payload)
+}
+
+// mp4Track is a trak with a handler and a single sample description, whose
+// bytes after the codec are filled in by fill.
+func mp4Track(handler, codec string, fill func(entry []byte)) []byte {
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:12], handler)
+
+	entry := make([]byte, 36)
+	binary.BigEndian.PutUint32(entry[0:4], uint32(len(entry)))
+	copy(entry[4:8], codec)
+	fill(entry)
+
+	stsd := append(make([]byte, 8), entry...)
+	binary.BigEndian.PutUint32(stsd[4:8], 1)
+
+	return box("trak",
+		box("mdia",
+			box("hdlr", hdlr),
+			box("minf", box("stbl", box("stsd", stsd))),
+		),
+	)
+}
+
+func videoTrack(width, height uint16) []byte {
+	return mp4Track("vide", "avc1", func(entry []byte) {
+		binary.BigEndian.PutUint16(entry[32:34], width)
+		binary.BigEndian.PutUint16(entry[34:36], height)
+	})
+}
+
+func audioTrack(channels uint16, sampleRate uint32) []byte {
+	return mp4Track("soun", "mp4a", func(entry []byte) {
+		binary.BigEndian.PutUint16(entry[24:26], channels)
+		binary.BigEndian.PutUint32(entry[32:36], sampleRate<<16)
+	})
+}
+
+func TestProbeMP4(t *testing.T) {
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41"))
+	mdat := box("mdat", make([]byte, 256))
+	cover := []byte{0xFF, 0xD8, 0xFF}
+	covr := box("udta", box("meta", make([]byte, 4), box("hdlr", make([]byte, 24)), box("ilst", box("covr", box("data", []byte{0, 0, 0, 13, 0, 0, 0, 0}, cover)))))
+
+	mehd := make([]byte, 8)
+	binary.BigEndian.PutUint32(mehd[4:8], 90000)
+
+	tests := []struct {
+		name    string
+		file    []byte
+		want    Info
+		wantErr error
+	}{
+		{
+			name: "video with audio",
+			file: bytes.Join([][]byte{ftyp, box("moov", mvhd(1000, 5000), videoTrack(640, 360), audioTrack(2, 44100)), mdat}, nil),
+			want: Info{Container: "mp4", MimeType: "video/mp4", DurationMs: 5000, VideoCodec: "avc1", AudioCodec: "mp4a", Width: 640, Height: 360, SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "moov after mdat",
+			file: bytes.Join([][]byte{ftyp, mdat, box("moov", mvhd(600, 1500), videoTrack(1920, 1080))}, nil),
+			want: Info{Container: "mp4", MimeType: "video/mp4", DurationMs: 2500, VideoCodec: "avc1", Width: 1920, Height: 1080},
+		},
+		{
+			name: "audio only with cover art",
+			file: bytes.Join([][]byte{ftyp, box("moov", mvhd(44100, 441000), audioTrack(1, 48000), covr), mdat}, nil),
+			want: Info{Container: "mp4", MimeType: "audio/mp4", DurationMs: 10000, AudioCodec: "mp4a", SampleRate: 48000, Channels: 1, Artwork: cover, ArtworkMime: "image/jpeg"},
+		},
+		{
+			name: "fragmented",
+			file: bytes.Join([][]byte{ftyp, box("moov", mvhd(90000, 0), box("mvex", box("mehd", mehd)), videoTrack(320, 240))}, nil),
+			want: Info{Container: "mp4", MimeType: "video/mp4", DurationMs: 1000, VideoCodec: "avc1", Width: 320, Height: 240},
+		},
+		{
+			name:    "no moov",
+			file:    bytes.Join([][]byte{ftyp, mdat}, nil),
+			wantErr: ErrInvalidMedia,
+		},
+		{
+			name:    "truncated moov",
+			file:    bytes.Join([][]byte{ftyp, box("moov", mvhd(1000, 5000))[:40]}, nil),
+			wantErr: ErrInvalidMedia,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Probe(bytes.NewReader(tt.file), int64(len(tt.file)))
+			assertInfo(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
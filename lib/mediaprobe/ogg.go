@@ -0,0 +1,167 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+type oggStream struct {
+	codec      string
+	video      bool
+	sampleRate int
+	preSkip    int64
+}
+
+type oggPage struct {
+	headerType byte
+	granule    int64
+	serial     uint32
+	data       []byte
+}
+
+func readOggPage(r io.Reader) (*oggPage, error) {
+	header := make([]byte, 27)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	if string(header[0:4]) != "OggS" {
+		return nil, ErrInvalidMedia
+	}
+
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(r, segments); err != nil {
+		return nil, err
+	}
+
+	length := 0
+	for _, segment := range segments {
+		length += int(segment)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	return &oggPage{
+		headerType: header[5],
+		granule:    int64(binary.LittleEndian.Uint64(header[6:14])),
+		serial:     binary.LittleEndian.Uint32(header[14:18]),
+		data:       data,
+	}, nil
+}
+
+func probeOgg(r io.ReadSeeker, size int64) (*Info, error) {
+	info := &Info{Container: "ogg", MimeType: "audio/ogg"}
+	streams := map[uint32]*oggStream{}
+
+	// Every logical stream starts with a beginning-of-stream page carrying
+	// its identification header, and all of them come first in the file.
+	for {
+		page, err := readOggPage(r)
+		if err != nil {
+			break
+		}
+		if page.headerType&0x02 == 0 {
+			break
+		}
+
+		stream := parseOggIdentification(page.data)
+		if stream == nil {
+			continue
+		}
+		streams[page.serial] = stream
+
+		if stream.video {
+			info.VideoCodec = stream.codec
+			info.Width, info.Height = parseTheoraSize(page.data)
+			info.MimeType = "video/ogg"
+		} else if info.AudioCodec == "" {
+			info.AudioCodec = stream.codec
+			info.SampleRate = stream.sampleRate
+			info.Channels = parseOggChannels(stream.codec, page.data)
+		}
+	}
+
+	if len(streams) == 0 {
+		return nil, ErrInvalidMedia
+	}
+
+	info.DurationMs = readOggDuration(r, size, streams)
+
+	return info, nil
+}
+
+func parseOggIdentification(data []byte) *oggStream {
+	switch {
+	case len(data) >= 16 && bytes.HasPrefix(data, []byte("\x01vorbis")):
+		return &oggStream{codec: "vorbis", sampleRate: int(binary.LittleEndian.Uint32(data[12:16]))}
+	case len(data) >= 19 && bytes.HasPrefix(data, []byte("OpusHead")):
+		// Opus granule positions always count 48kHz samples.
+		return &oggStream{codec: "opus", sampleRate: 48000, preSkip: int64(binary.LittleEndian.Uint16(data[10:12]))}
+	case len(data) >= 7 && bytes.HasPrefix(data, []byte("\x80theora")):
+		return &oggStream{codec: "theora", video: true}
+	}
+
+	return nil
+}
+
+func parseOggChannels(codec string, data []byte) int {
+	switch codec {
+	case "vorbis":
+		return int(data[11])
+	case "opus":
+		return int(data[9])
+	}
+
+	return 0
+}
+
+func parseTheoraSize(data []byte) (int, int) {
+	if len(data) < 20 {
+		return 0, 0
+	}
+
+	width := int(data[14])<<16 | int(data[15])<<8 | int(data[16])
+	height := int(data[17])<<16 | int(data[18])<<8 | int(data[19])
+
+	return width, height
+}
+
+// readOggDuration scans the tail of the file for the last page of an audio
+// stream and converts its granule position to milliseconds.
+func readOggDuration(r io.ReadSeeker, size int64, streams map[uint32]*oggStream) int64 {
+	tailSize := int64(256 << 10)
+	if tailSize > size {
+		tailSize = size
+	}
+
+	if _, err := r.Seek(size-tailSize, io.SeekStart); err != nil {
+		return 0
+	}
+
+	tail := make([]byte, tailSize)
+	if _, err := io.ReadFull(r, tail); err != nil {
+		return 0
+	}
+
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if i+27 > len(tail) {
+			continue
+		}
+
+		granule := int64(binary.LittleEndian.Uint64(tail[i+6 : i+14]))
+		serial := binary.LittleEndian.Uint32(tail[i+14 : i+18])
+
+		stream, ok := streams[serial]
+		if !ok || stream.video || stream.sampleRate == 0 || granule <= 0 {
+			continue
+		}
+
+		return (granule - stream.preSkip) * 1000 / int64(stream.sampleRate)
+	}
+
+	return 0
+}
@@ -0,0 +1,106 @@
+package mediaprobe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+const (
+	oggBeginOfStream = 0x02
+	oggEndOfStream   = 0x04
+)
+
+// oggPageFixture is a page with a single segment, so data must be shorter
+// than 255 bytes.
+func oggPageFixture(headerType byte, serial uint32, granule int64, data []byte) []byte {
+	page := make([]byte, 27, 28+len(data))
+	copy(page, "OggS")
+	page[5] = headerType
+	binary.LittleEndian.PutUint64(page[6:14], uint64(granule))
+	binary.LittleEndian.PutUint32(page[14:18], serial)
+	page[26] = 1
+	page = append(page, byte(len(data)))
+	return append(page, data...)
+}
+
+func vorbisHeader(channels byte, sampleRate uint32) []byte {
+	header := make([]byte, 30)
+	copy(header, "\x01vorbis")
+	header[11] = channels
+	binary.LittleEndian.PutUint32(header[12:16], sampleRate)
+	return header
+}
+
+func opusHeader(channels byte, preSkip uint16) []byte {
+	header := make([]byte, 19)
+	copy(header, "OpusHead")
+	header[8] = 1
+	header[9] = channels
+	binary.LittleEndian.PutUint16(header[10:12], preSkip)
+	binary.LittleEndian.PutUint32(header[12:16], 44100)
+	return header
+}
+
+func theoraHeader(width, height int) []byte {
+	header := make([]byte, 42)
+	copy(header, "\x80theora")
+	header[14], header[15], header[16] = byte(width>>16), byte(width>>8), byte(width)
+	header[17], header[18], header[19] = byte(height>>16), byte(height>>8), byte(height)
+	return header
+}
+
+func TestProbeOgg(t *testing.T) {
+	audio := make([]byte, 200)
+
+	tests := []struct {
+		name    string
+		file    []byte
+		want    Info
+		wantErr error
+	}{
+		{
+			name: "vorbis",
+			file: bytes.Join([][]byte{
+				oggPageFixture(oggBeginOfStream, 1, 0, vorbisHeader(2, 44100)),
+				oggPageFixture(0, 1, 44100, audio),
+				oggPageFixture(oggEndOfStream, 1, 132300, audio),
+			}, nil),
+			want: Info{Container: "ogg", MimeType: "audio/ogg", DurationMs: 3000, AudioCodec: "vorbis", SampleRate: 44100, Channels: 2},
+		},
+		{
+			name: "opus with pre-skip",
+			file: bytes.Join([][]byte{
+				oggPageFixture(oggBeginOfStream, 7, 0, opusHeader(1, 312)),
+				oggPageFixture(oggEndOfStream, 7, 96312, audio),
+			}, nil),
+			want: Info{Container: "ogg", MimeType: "audio/ogg", DurationMs: 2000, AudioCodec: "opus", SampleRate: 48000, Channels: 1},
+		},
+		{
+			name: "theora with vorbis",
+			file: bytes.Join([][]byte{
+				oggPageFixture(oggBeginOfStream, 1, 0, theoraHeader(640, 480)),
+				oggPageFixture(oggBeginOfStream, 2, 0, vorbisHeader(2, 48000)),
+				oggPageFixture(0, 2, 48000, audio),
+				oggPageFixture(oggEndOfStream, 2, 240000, audio),
+				oggPageFixture(oggEndOfStream, 1, 1<<20, nil),
+			}, nil),
+			want: Info{Container: "ogg", MimeType: "video/ogg", DurationMs: 5000, VideoCodec: "theora", AudioCodec: "vorbis", Width: 640, Height: 480, SampleRate: 48000, Channels: 2},
+		},
+		{
+			name: "unknown codec",
+			file: bytes.Join([][]byte{
+				oggPageFixture(oggBeginOfStream, 1, 0, []byte("fLaC")),
+				oggPageFixture(oggEndOfStream, 1, 44100, audio),
+			}, nil),
+			wantErr: ErrInvalidMedia,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Probe(bytes.NewReader(tt.file), int64(len(tt.file)))
+			assertInfo(t, got, err, tt.want, tt.wantErr)
+		})
+	}
+}
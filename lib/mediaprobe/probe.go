@@ -0,0 +1,84 @@
+// Package mediaprobe reads duration, codec and dimension metadata out of
+// MP4, MP3 and OGG containers without decoding any media.
+package mediaprobe
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+)
+
+type Info struct {
+	Container  string
+	MimeType   string
+	DurationMs int64
+	VideoCodec string
+	AudioCodec string
+	Width      int
+	Height     int
+	SampleRate int
+	Channels   int
+
+	// Artwork holds an embedded cover image (ID3 APIC or MP4 covr), if any.
+	Artwork     []byte
+	ArtworkMime string
+}
+
+func (i *Info) HasVideo() bool {
+	return i.VideoCodec != ""
+}
+
+func ProbeFile(path string) (*Info, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return Probe(file, stat.Size())
+}
+
+func Probe(r io.ReadSeeker, size int64) (*Info, error) {
+	head := make([]byte, 12)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, ErrInvalidMedia
+	}
+	head = head[:n]
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case len(head) >= 8 && string(head[4:8]) == "ftyp":
+		return probeMP4(r, size)
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return probeOgg(r, size)
+	case bytes.HasPrefix(head, []byte("ID3")), len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return probeMP3(r, size)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// DetectMimeType sniffs the first bytes of a file, falling back to the
+// generic binary type.
+func DetectMimeType(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+
+	return http.DetectContentType(head[:n])
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+// GormPlugin times every query GORM runs by operation and table.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("metrics:before_create", before); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("metrics:after_create", after("create")); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("metrics:before_query", before); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("metrics:after_query", after("query")); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("metrics:before_update", before); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("metrics:after_update", after("update")); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("metrics:before_row", before); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("metrics:after_row", after("row")); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw"))
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		DbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			DbQueryErrors.WithLabelValues(operation, table).Inc()
+		}
+	}
+}
+
+// RegisterDBStats exports the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
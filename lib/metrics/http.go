@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// unmatchedRoute labels requests no route matched, so scanners probing
+// random paths do not create a series per path.
+const unmatchedRoute = "unmatched"
+
+// Middleware records every request under the template of the route that
+// served it, such as /api/admin/contents/:contentID.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		HttpRequestsInFlight.Inc()
+		defer HttpRequestsInFlight.Dec()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+		}
+
+		route := unmatchedRoute
+		if r := c.Route(); r != nil && !(status == fiber.StatusNotFound && r.Path == "/") {
+			route = r.Path
+		}
+
+		labels := []string{c.Method(), route, strconv.Itoa(status)}
+		HttpRequests.WithLabelValues(labels...).Inc()
+		HttpRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,116 @@
+// Package metrics holds the Prometheus collectors of the API and the
+// registry /metrics serves. Collectors are package level so adapters and
+// services can record without threading a registry through constructors.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "gonews"
+
+var Registry = prometheus.NewRegistry()
+
+var (
+	HttpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HttpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	})
+
+	DbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	DbQueryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database queries by operation and table, not counting record not found.",
+	}, []string{"operation", "table"})
+
+	UploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upload_bytes_total",
+		Help:      "Bytes received in uploads by kind.",
+	}, []string{"kind"})
+
+	ImageKitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "imagekit_request_duration_seconds",
+		Help:      "ImageKit call latency by operation.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"operation"})
+
+	ImageKitErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "imagekit_errors_total",
+		Help:      "Failed ImageKit calls by operation.",
+	}, []string{"operation"})
+
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_logins_total",
+		Help:      "Login attempts by result.",
+	}, []string{"result"})
+
+	CdnPurges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cdn_purges_total",
+		Help:      "CDN purges by result: succeeded, failed after every retry, or dropped on a full queue.",
+	}, []string{"result"})
+)
+
+const (
+	UploadKindTus        = "tus"
+	UploadKindImage      = "image"
+	UploadKindAttachment = "attachment"
+	UploadKindImport     = "import"
+
+	LoginSuccess = "success"
+	LoginFailure = "failure"
+
+	CdnPurgeSucceeded = "succeeded"
+	CdnPurgeFailed    = "failed"
+	CdnPurgeDropped   = "dropped"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequests,
+		HttpRequestDuration,
+		HttpRequestsInFlight,
+		DbQueryDuration,
+		DbQueryErrors,
+		UploadBytes,
+		ImageKitDuration,
+		ImageKitErrors,
+		Logins,
+		CdnPurges,
+	)
+
+	for _, result := range []string{LoginSuccess, LoginFailure} {
+		Logins.WithLabelValues(result)
+	}
+	for _, result := range []string{CdnPurgeSucceeded, CdnPurgeFailed, CdnPurgeDropped} {
+		CdnPurges.WithLabelValues(result)
+	}
+}
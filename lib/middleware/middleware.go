@@ -3,7 +3,11 @@ package middleware
 import (
 	"gonews/config"
 	"gonews/internal/adapter/handler/response"
+	"gonews/internal/adapter/repository"
+	"gonews/internal/core/domain/entity"
 	"gonews/lib/auth"
+	"gonews/lib/logger"
+	"gonews/lib/tracing"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,10 +15,12 @@ import (
 
 type Middleware interface {
 	CheckToken() fiber.Handler
+	RequireRole(roles ...string) fiber.Handler
 }
 
 type Options struct {
-	authJwt auth.Jwt
+	authJwt  auth.Jwt
+	userRepo repository.UserRepository
 }
 
 
@@ -36,15 +42,48 @@ func (o *Options) CheckToken() func(*fiber.Ctx) error {
 			return c.Status(fiber.StatusUnauthorized).JSON(errorResponse)
 		}
 
+		// the token outlives deactivations and role changes, so the stored
+		// user has the last word on both
+		user, err := o.userRepo.GetUserByID(c.UserContext(), int64(claims.UserID))
+		if err != nil || user.DeactivatedAt != nil {
+			errorResponse.Meta.Status = false
+			errorResponse.Meta.Message = "Invalid token"
+			return c.Status(fiber.StatusUnauthorized).JSON(errorResponse)
+		}
+		claims.Role = user.Role
+
 		c.Locals("user", claims)
+		tracing.SetUser(c, int64(claims.UserID))
+		logger.SetUser(c, int64(claims.UserID))
 
 		return c.Next()
 	}
 }
 
-func NewMiddleware(cfg *config.Config) Middleware {
+// RequireRole lets only users with one of roles through. It goes after
+// CheckToken.
+func (o *Options) RequireRole(roles ...string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("user").(*entity.JwtData)
+		if ok {
+			for _, role := range roles {
+				if claims.Role == role {
+					return c.Next()
+				}
+			}
+		}
+
+		var errorResponse response.ErrorResponseDefault
+		errorResponse.Meta.Status = false
+		errorResponse.Meta.Message = "Forbidden"
+		return c.Status(fiber.StatusForbidden).JSON(errorResponse)
+	}
+}
+
+func NewMiddleware(cfg *config.Config, userRepo repository.UserRepository) Middleware {
 	opt := new(Options)
 	opt.authJwt = auth.NewJwt(cfg)
+	opt.userRepo = userRepo
 
 	return opt
 }
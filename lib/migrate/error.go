@@ -0,0 +1,14 @@
+package migrate
+
+import "errors"
+
+var (
+	ErrFileNameInvalid = errors.New("migration file name invalid: must be NNNNNN_name.up.sql or NNNNNN_name.down.sql")
+	ErrDuplicate       = errors.New("migration version duplicated")
+	ErrUpMissing       = errors.New("migration has no up file")
+	ErrDownMissing     = errors.New("migration has an empty down file and cannot be reverted")
+	ErrDirty           = errors.New("database is dirty: a migration failed halfway and has to be fixed by hand")
+	ErrUnknownVersion  = errors.New("database version has no migration file")
+	ErrStepsInvalid    = errors.New("steps must be a positive number")
+	ErrNameInvalid     = errors.New("migration name invalid: use letters, digits and underscores")
+)
@@ -0,0 +1,366 @@
+// Package migrate applies the versioned SQL migrations in
+// database/migrations. Applied versions are tracked in the same
+// schema_migrations table golang-migrate uses, so databases migrated with
+// that tool carry on where they were.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const table = "schema_migrations"
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^\w+$`)
+
+	// lockKey is the Postgres advisory lock held while migrating, so pods
+	// starting at the same time take turns instead of racing.
+	lockKey = int64(crc32.ChecksumIEEE([]byte("gonews:" + table)))
+)
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied bool
+}
+
+// Load reads the migrations of dir in fsys, sorted by version.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint64]*Migration{}
+	hasUp := map[uint64]bool{}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", ErrFileNameInvalid, entry.Name())
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrFileNameInvalid, entry.Name())
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicate, version)
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+			hasUp[version] = true
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if !hasUp[m.Version] {
+			return nil, fmt.Errorf("%w: %d_%s", ErrUpMissing, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Create writes an empty up and down migration to dir, numbered after the
+// last one there, and returns their paths.
+func Create(dir, name string) (string, string, error) {
+	if !namePattern.MatchString(name) {
+		return "", "", ErrNameInvalid
+	}
+
+	migrations, err := Load(os.DirFS(dir), ".")
+	if err != nil {
+		return "", "", err
+	}
+
+	var version uint64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%06d_%s", version, name))
+	upPath, downPath := base+".up.sql", base+".down.sql"
+	for _, p := range []string{upPath, downPath} {
+		if err = os.WriteFile(p, nil, 0644); err != nil {
+			return "", "", err
+		}
+	}
+
+	return upPath, downPath, nil
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{db: db, migrations: migrations}
+}
+
+// Version returns the version the database is at, 0 before the first
+// migration. It never writes, so it is safe to call from health checks.
+func (m *Migrator) Version(ctx context.Context) (uint64, bool, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	return version(ctx, m.db)
+}
+
+// Latest returns the version of the last migration known to the binary.
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Pending returns the migrations after version current.
+func (m *Migrator) Pending(current uint64) []Migration {
+	pending := []Migration{}
+	for _, migration := range m.migrations {
+		if migration.Version > current {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending
+}
+
+// Status lists every migration and whether it is applied.
+func (m *Migrator) Status(ctx context.Context) (uint64, bool, []MigrationStatus, error) {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return 0, false, nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range m.migrations {
+		statuses = append(statuses, MigrationStatus{
+			Migration: migration,
+			Applied:   migration.Version <= current,
+		})
+	}
+
+	return current, dirty, statuses, nil
+}
+
+// Up applies up to steps pending migrations, all of them when steps is 0.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 0 {
+		return nil, ErrStepsInvalid
+	}
+
+	applied := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := m.checkVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version <= current {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+
+			if err = m.run(ctx, conn, migration.Up, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, ErrStepsInvalid
+	}
+
+	reverted := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := m.checkVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if migration.Version > current {
+				continue
+			}
+
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("%w: %d_%s", ErrDownMissing, migration.Version, migration.Name)
+			}
+
+			var previous uint64
+			if i > 0 {
+				previous = m.migrations[i-1].Version
+			}
+
+			if err = m.run(ctx, conn, migration.Down, previous); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// run executes a migration and records the new version in one
+// transaction. Postgres DDL is transactional, so a failing migration
+// leaves the database as it was.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, query string, newVersion uint64) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(query) != "" {
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+		return err
+	}
+	if newVersion > 0 {
+		if _, err = tx.ExecContext(ctx, "INSERT INTO "+table+" (version, dirty) VALUES ($1, false)", newVersion); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkVersion refuses to migrate a dirty database or one at a version
+// this binary does not know about.
+func (m *Migrator) checkVersion(ctx context.Context, conn *sql.Conn) (uint64, error) {
+	current, dirty, err := version(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	if dirty {
+		return 0, fmt.Errorf("%w (version %d)", ErrDirty, current)
+	}
+	if current == 0 {
+		return 0, nil
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version == current {
+			return current, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+}
+
+// locked runs fn on a single connection holding the migration advisory
+// lock; session locks belong to the connection that took them.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	if err = m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+type execQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (m *Migrator) ensureTable(ctx context.Context, db execQuerier) error {
+	_, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+table+" (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)")
+	return err
+}
+
+func version(ctx context.Context, db execQuerier) (uint64, bool, error) {
+	var (
+		current uint64
+		dirty   bool
+	)
+
+	err := db.QueryRowContext(ctx, "SELECT version, dirty FROM "+table+" LIMIT 1").Scan(&current, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return current, dirty, nil
+}
@@ -0,0 +1,88 @@
+// Package netguard keeps requests to URLs given by users, such as webhooks
+// and images of an import, away from the servers' own network.
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrURLInvalid       = errors.New("url must be an absolute http or https url")
+	ErrAddressForbidden = errors.New("address is not public")
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Public reports whether ip can be reached from the internet: it is not
+// loopback, private, link-local (cloud metadata services live there),
+// multicast or unspecified.
+func Public(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL accepts absolute http and https URLs whose host is a name or a
+// public address. Names are only resolved when connecting, by Transport.
+func CheckURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return ErrURLInvalid
+	}
+
+	if ip, err := netip.ParseAddr(parsed.Hostname()); err == nil && !Public(ip) {
+		return ErrAddressForbidden
+	}
+
+	return nil
+}
+
+// control runs right before each connection is made, on the address the
+// name resolved to, so a name cannot point somewhere else by the time it is
+// dialed.
+func control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !Public(ip) {
+		return fmt.Errorf("%s: %w", host, ErrAddressForbidden)
+	}
+
+	return nil
+}
+
+// Transport is http.DefaultTransport that only connects to public
+// addresses, redirects included. It ignores proxy settings, since a proxy
+// would connect on its behalf.
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}).DialContext
+
+	return transport
+}
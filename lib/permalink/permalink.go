@@ -0,0 +1,28 @@
+package permalink
+
+import (
+	"fmt"
+	"gonews/internal/core/domain/entity"
+	"net/url"
+	"strings"
+)
+
+// Content returns the public front-end URL of a content, built from the
+// category and content slugs. Contents without a slug fall back to their ID.
+func Content(baseUrl string, content entity.ContentEntity) string {
+	if content.Slug == "" || content.Category.Slug == "" {
+		return fmt.Sprintf("%s/contents/%d", strings.TrimRight(baseUrl, "/"), content.ID)
+	}
+
+	return fmt.Sprintf("%s/%s/%s", strings.TrimRight(baseUrl, "/"), url.PathEscape(content.Category.Slug), url.PathEscape(content.Slug))
+}
+
+// Category returns the public front-end URL of a category listing.
+func Category(baseUrl string, slug string) string {
+	return fmt.Sprintf("%s/categories/%s", strings.TrimRight(baseUrl, "/"), url.PathEscape(slug))
+}
+
+// Tag returns the public front-end URL of a tag listing.
+func Tag(baseUrl string, tag string) string {
+	return fmt.Sprintf("%s/tags/%s", strings.TrimRight(baseUrl, "/"), url.PathEscape(tag))
+}
@@ -0,0 +1,8 @@
+package preview
+
+import "errors"
+
+var (
+	ErrTokenInvalid = errors.New("preview token invalid")
+	ErrTokenExpired = errors.New("preview token expired")
+)
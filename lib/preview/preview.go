@@ -0,0 +1,58 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audience is set on every preview token so that neither access tokens nor
+// preview tokens are accepted in place of the other.
+const Audience = "preview"
+
+type claims struct {
+	ContentID int64 `json:"content_id"`
+	jwt.RegisteredClaims
+}
+
+// Sign issues a token granting read access to one content until expiresAt.
+func Sign(key string, contentID int64, expiresAt time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		ContentID: contentID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{Audience},
+			Subject:   fmt.Sprintf("%d", contentID),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	return token.SignedString([]byte(key))
+}
+
+// Parse verifies a preview token and returns the content ID it grants
+// access to.
+func Parse(key string, token string) (int64, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
+		return []byte(key), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return 0, ErrTokenExpired
+		}
+		return 0, ErrTokenInvalid
+	}
+
+	if c.ContentID <= 0 {
+		return 0, ErrTokenInvalid
+	}
+
+	return c.ContentID, nil
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"fmt"
+	"gonews/internal/adapter/handler/response"
+	"gonews/internal/core/domain/entity"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+)
+
+const (
+	HeaderLimit     = "RateLimit-Limit"
+	HeaderRemaining = "RateLimit-Remaining"
+	HeaderReset     = "RateLimit-Reset"
+	HeaderPolicy    = "RateLimit-Policy"
+)
+
+// ExposedHeaders lists the headers browsers need to be allowed to read.
+var ExposedHeaders = []string{HeaderLimit, HeaderRemaining, HeaderReset, HeaderPolicy, fiber.HeaderRetryAfter}
+
+// KeyFunc names the client a request is counted against.
+type KeyFunc func(c *fiber.Ctx) string
+
+// ByIP counts requests per client address.
+func ByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// ByUser counts requests per authenticated user, falling back to the client
+// address before authentication.
+func ByUser(c *fiber.Ctx) string {
+	if claims, ok := c.Locals("user").(*entity.JwtData); ok && claims.UserID != 0 {
+		return "user:" + strconv.FormatInt(int64(claims.UserID), 10)
+	}
+	return ByIP(c)
+}
+
+// New limits requests by policy, counting them against key. Responses carry
+// the RateLimit headers, and a request over the limit gets a 429 with
+// Retry-After. When the store fails the request goes through: an outage of
+// the store should not take the API down with it.
+func New(store Store, policy Policy, key KeyFunc) fiber.Handler {
+	if !policy.Enabled() {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	policyHeader := fmt.Sprintf("%d;w=%d;burst=%d", policy.Limit, int(policy.Period.Seconds()), int(policy.Capacity()))
+
+	return func(c *fiber.Ctx) error {
+		tokens, allowed, err := store.Take(c.UserContext(), policy.Name+":"+key(c), policy.Capacity(), policy.Rate())
+		if err != nil {
+			zerolog.Ctx(c.UserContext()).Error().Err(err).Str("policy", policy.Name).Msg("[MIDDLEWARE] RateLimit - 1")
+			return c.Next()
+		}
+
+		result := newResult(policy, tokens, allowed)
+		c.Set(HeaderLimit, strconv.Itoa(result.Limit))
+		c.Set(HeaderRemaining, strconv.Itoa(result.Remaining))
+		c.Set(HeaderReset, ceilSeconds(result.Reset))
+		c.Set(HeaderPolicy, policyHeader)
+
+		if !result.Allowed {
+			var errorResponse response.ErrorResponseDefault
+			errorResponse.Meta.Status = false
+			errorResponse.Meta.Message = "Too many requests"
+
+			c.Set(fiber.HeaderRetryAfter, ceilSeconds(result.RetryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(errorResponse)
+		}
+
+		return c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore keeps buckets in process. Each instance limits on its own, so
+// behind a load balancer the effective limit is multiplied by the number of
+// instances.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (m *MemoryStore) Take(_ context.Context, key string, capacity float64, rate float64) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	var allowed bool
+	b.tokens, allowed = refill(b.tokens, now.Sub(b.updatedAt), capacity, rate)
+	b.updatedAt = now
+
+	return b.tokens, allowed, nil
+}
+
+func (m *MemoryStore) Sweep(_ context.Context, idle time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := time.Now().Add(-idle)
+	var swept int64
+	for key, b := range m.buckets {
+		if b.updatedAt.Before(before) {
+			delete(m.buckets, key)
+			swept++
+		}
+	}
+
+	return swept, nil
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// takeQuery refills and takes from a bucket in one statement. Every SET
+// expression sees the row as it was before the update, so allowed and tokens
+// agree on the refilled amount.
+const takeQuery = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES ($1, $2::double precision - 1, true, now())
+ON CONFLICT (key) DO UPDATE SET
+	allowed = LEAST($2::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * $3::double precision) >= 1,
+	tokens = LEAST($2::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * $3::double precision)
+		- CASE WHEN LEAST($2::double precision, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::double precision * $3::double precision) >= 1 THEN 1 ELSE 0 END,
+	updated_at = now()
+RETURNING tokens, allowed`
+
+// PostgresStore shares buckets between instances through the
+// rate_limit_buckets table, using the database clock so instances with
+// drifting clocks agree.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (p *PostgresStore) Take(ctx context.Context, key string, capacity float64, rate float64) (float64, bool, error) {
+	var tokens float64
+	var allowed bool
+	err := p.db.QueryRowContext(ctx, takeQuery, key, capacity, rate).Scan(&tokens, &allowed)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return tokens, allowed, nil
+}
+
+func (p *PostgresStore) Sweep(ctx context.Context, idle time.Duration) (int64, error) {
+	result, err := p.db.ExecContext(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < now() - $1::double precision * interval '1 second'`,
+		idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Policy is a token bucket: it holds up to Burst requests and refills at
+// Limit requests per Period, so a client may burst and then settles to the
+// steady rate.
+type Policy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+// Enabled reports whether the policy limits anything; a zero limit or period
+// turns it off.
+func (p Policy) Enabled() bool {
+	return p.Limit > 0 && p.Period > 0
+}
+
+// Capacity is the size of the bucket, the limit when no burst is set.
+func (p Policy) Capacity() float64 {
+	if p.Burst > 0 {
+		return float64(p.Burst)
+	}
+	return float64(p.Limit)
+}
+
+// Rate is the refill rate in tokens per second.
+func (p Policy) Rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// RefillTime is how long an empty bucket takes to fill up again.
+func (p Policy) RefillTime() time.Duration {
+	return seconds(p.Capacity() / p.Rate())
+}
+
+// Store keeps the buckets. Take refills the bucket under key for the time
+// since it was last used, removes one token when there is one and returns
+// the tokens left and whether the request may go ahead. It must be atomic,
+// as instances sharing a store race for the same bucket.
+type Store interface {
+	Take(ctx context.Context, key string, capacity float64, rate float64) (tokens float64, allowed bool, err error)
+	// Sweep drops buckets idle for longer than idle, which by then have
+	// refilled and behave as if they did not exist.
+	Sweep(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+// Result describes a bucket after a request, in the terms of the RateLimit
+// headers.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+func newResult(p Policy, tokens float64, allowed bool) Result {
+	capacity, rate := p.Capacity(), p.Rate()
+	if tokens < 0 {
+		tokens = 0
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     int(capacity),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((capacity - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return result
+}
+
+func seconds(value float64) time.Duration {
+	if value <= 0 {
+		return 0
+	}
+	return time.Duration(value * float64(time.Second))
+}
+
+// refill is the token bucket arithmetic shared by the stores that keep the
+// bucket in Go.
+func refill(tokens float64, elapsed time.Duration, capacity float64, rate float64) (float64, bool) {
+	tokens = math.Min(capacity, tokens+elapsed.Seconds()*rate)
+	if tokens >= 1 {
+		return tokens - 1, true
+	}
+	return tokens, false
+}
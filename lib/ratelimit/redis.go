@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript is the token bucket as a Lua script, so the read and write run
+// atomically on the server. Tokens travel as strings because Lua numbers are
+// truncated to integers in replies. The key expires once the bucket would be
+// full again.
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1])
+local updated = tonumber(bucket[2])
+if tokens == nil or updated == nil then
+	tokens = capacity
+	updated = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - updated) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate * 1000) + 1000)
+return {allowed, tostring(tokens)}`
+
+var takeScriptRedis = redis.NewScript(takeScript)
+
+var errRedisReply = errors.New("unexpected reply from the take script")
+
+const redisKeyPrefix = "gonews:ratelimit:"
+
+// RedisStore shares buckets between instances through Redis or a compatible
+// server, using the server clock.
+type RedisStore struct {
+	client *redis.Client
+}
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (r *RedisStore) Take(ctx context.Context, key string, capacity float64, rate float64) (float64, bool, error) {
+	values, err := takeScriptRedis.Run(ctx, r.client, []string{redisKeyPrefix + key},
+		strconv.FormatFloat(capacity, 'f', -1, 64),
+		strconv.FormatFloat(rate, 'f', -1, 64)).Slice()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if len(values) != 2 {
+		return 0, false, errRedisReply
+	}
+	allowed, _ := values[0].(int64)
+	tokensValue, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensValue, 64)
+	if err != nil {
+		return 0, false, errRedisReply
+	}
+
+	return tokens, allowed == 1, nil
+}
+
+// Sweep does nothing, as keys expire on their own.
+func (r *RedisStore) Sweep(_ context.Context, _ time.Duration) (int64, error) {
+	return 0, nil
+}
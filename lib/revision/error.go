@@ -0,0 +1,9 @@
+package revision
+
+import "errors"
+
+var (
+	ErrRevisionConflict = errors.New("resource was modified since it was fetched")
+	ErrIfMatchRequired  = errors.New("If-Match header is required")
+	ErrIfMatchInvalid   = errors.New("If-Match header invalid: must be a single strong etag")
+)
@@ -0,0 +1,41 @@
+// Package revision maps the version column of editable records onto HTTP
+// entity tags so clients can make conditional writes with If-Match.
+package revision
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Any is returned by ParseIfMatch for "If-Match: *", which matches every
+// existing version.
+const Any int64 = 0
+
+// ETag returns the strong entity tag of a version.
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseIfMatch returns the version an If-Match header asks for. Weak tags
+// are rejected because If-Match uses strong comparison.
+func ParseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, ErrIfMatchRequired
+	}
+
+	if header == "*" {
+		return Any, nil
+	}
+
+	if len(header) < 3 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, ErrIfMatchInvalid
+	}
+
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, ErrIfMatchInvalid
+	}
+
+	return version, nil
+}
@@ -0,0 +1,5 @@
+package sitemap
+
+import "errors"
+
+var ErrSitemapNotFound = errors.New("sitemap not found")
@@ -0,0 +1,126 @@
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the number of URLs the sitemap protocol allows in one file.
+const MaxURLs = 50000
+
+const (
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	newsNamespace    = "http://www.google.com/schemas/sitemap-news/0.9"
+	lastModFormat    = time.RFC3339
+)
+
+type URL struct {
+	Loc     string
+	LastMod time.Time
+}
+
+type NewsURL struct {
+	Loc             string
+	Title           string
+	PublicationDate time.Time
+}
+
+type Publication struct {
+	Name     string
+	Language string
+}
+
+type indexXML struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []locElement `xml:"sitemap"`
+}
+
+type urlSetXML struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []locElement `xml:"url"`
+}
+
+type locElement struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type newsURLSetXML struct {
+	XMLName   xml.Name         `xml:"urlset"`
+	Xmlns     string           `xml:"xmlns,attr"`
+	XmlnsNews string           `xml:"xmlns:news,attr"`
+	URLs      []newsURLElement `xml:"url"`
+}
+
+type newsURLElement struct {
+	Loc  string      `xml:"loc"`
+	News newsElement `xml:"news:news"`
+}
+
+type newsElement struct {
+	Publication     publicationElement `xml:"news:publication"`
+	PublicationDate string             `xml:"news:publication_date"`
+	Title           string             `xml:"news:title"`
+}
+
+type publicationElement struct {
+	Name     string `xml:"news:name"`
+	Language string `xml:"news:language"`
+}
+
+// Index renders a sitemap index pointing at the given sitemaps.
+func Index(sitemaps []URL) ([]byte, error) {
+	doc := indexXML{Xmlns: sitemapNamespace, Sitemaps: toLocElements(sitemaps)}
+	return marshal(doc)
+}
+
+// URLSet renders a sitemap. Callers are responsible for keeping it within
+// MaxURLs entries.
+func URLSet(urls []URL) ([]byte, error) {
+	doc := urlSetXML{Xmlns: sitemapNamespace, URLs: toLocElements(urls)}
+	return marshal(doc)
+}
+
+// News renders a Google News sitemap for the given publication.
+func News(publication Publication, urls []NewsURL) ([]byte, error) {
+	doc := newsURLSetXML{Xmlns: sitemapNamespace, XmlnsNews: newsNamespace, URLs: []newsURLElement{}}
+	for _, u := range urls {
+		doc.URLs = append(doc.URLs, newsURLElement{
+			Loc: u.Loc,
+			News: newsElement{
+				Publication: publicationElement{
+					Name:     publication.Name,
+					Language: publication.Language,
+				},
+				PublicationDate: u.PublicationDate.UTC().Format(lastModFormat),
+				Title:           u.Title,
+			},
+		})
+	}
+
+	return marshal(doc)
+}
+
+func toLocElements(urls []URL) []locElement {
+	elements := []locElement{}
+	for _, u := range urls {
+		element := locElement{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			element.LastMod = u.LastMod.UTC().Format(lastModFormat)
+		}
+		elements = append(elements, element)
+	}
+
+	return elements
+}
+
+func marshal(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,7 @@
+package tracing
+
+import "errors"
+
+var (
+	ErrExporterUnknown = errors.New("unknown tracing exporter")
+)
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const spanKey = "tracing:span"
+
+// GormPlugin opens a client span per query under the span of the context
+// passed with db.WithContext. Queries outside a traced request, such as the
+// background workers polling, are skipped. Statements are recorded with
+// placeholders, so bound values never reach the exporter.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("tracing:before_create", before("create")); err != nil {
+		return err
+	}
+	if err := cb.Create().After("gorm:create").Register("tracing:after_create", after); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tracing:before_query", before("query")); err != nil {
+		return err
+	}
+	if err := cb.Query().After("gorm:query").Register("tracing:after_query", after); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tracing:before_update", before("update")); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("tracing:after_update", after); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tracing:before_delete", before("delete")); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("tracing:after_delete", after); err != nil {
+		return err
+	}
+	if err := cb.Row().Before("gorm:row").Register("tracing:before_row", before("row")); err != nil {
+		return err
+	}
+	if err := cb.Row().After("gorm:row").Register("tracing:after_row", after); err != nil {
+		return err
+	}
+	if err := cb.Raw().Before("gorm:raw").Register("tracing:before_raw", before("raw")); err != nil {
+		return err
+	}
+	return cb.Raw().After("gorm:raw").Register("tracing:after_raw", after)
+}
+
+func before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if !trace.SpanContextFromContext(db.Statement.Context).IsValid() {
+			return
+		}
+
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+
+		_, span := otel.Tracer(instrumentationName).Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func after(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
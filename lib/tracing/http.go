@@ -0,0 +1,120 @@
+package tracing
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderTraceID carries the trace id on every traced response, so a report
+// can be matched to its trace even when the body is not JSON.
+const HeaderTraceID = "X-Trace-Id"
+
+// Middleware opens a server span per request, continuing the trace of an
+// incoming traceparent header, and hands it to handlers through
+// c.UserContext(). JSON error bodies get a trace_id field.
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		ctx, span := otel.Tracer(instrumentationName).Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+			),
+		)
+		defer span.End()
+
+		c.SetUserContext(ctx)
+
+		traceID := TraceID(ctx)
+		if traceID != "" {
+			c.Set(HeaderTraceID, traceID)
+		}
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			} else {
+				status = fiber.StatusInternalServerError
+			}
+			span.RecordError(err)
+		}
+
+		if r := c.Route(); r != nil && !(status == fiber.StatusNotFound && r.Path == "/") {
+			span.SetName(c.Method() + " " + r.Path)
+			span.SetAttributes(semconv.HTTPRoute(r.Path))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, strconv.Itoa(status))
+		}
+
+		if err == nil && status >= fiber.StatusBadRequest && traceID != "" {
+			addTraceID(c, traceID)
+		}
+
+		return err
+	}
+}
+
+// addTraceID appends trace_id to a JSON object error body such as
+// response.ErrorResponseDefault, leaving any other body alone.
+func addTraceID(c *fiber.Ctx, traceID string) {
+	if !bytes.HasPrefix(c.Response().Header.ContentType(), []byte(fiber.MIMEApplicationJSON)) {
+		return
+	}
+
+	body := bytes.TrimSpace(c.Response().Body())
+	if len(body) < 2 || body[0] != '{' || body[len(body)-1] != '}' || bytes.Contains(body, []byte(`"trace_id"`)) {
+		return
+	}
+
+	field := `"trace_id":"` + traceID + `"}`
+	if len(bytes.TrimSpace(body[1:len(body)-1])) > 0 {
+		field = "," + field
+	}
+
+	out := make([]byte, 0, len(body)+len(field))
+	out = append(out, body[:len(body)-1]...)
+	out = append(out, field...)
+	c.Response().SetBodyRaw(out)
+}
+
+// SetUser tags the request span with the authenticated user.
+func SetUser(c *fiber.Ctx, userID int64) {
+	trace.SpanFromContext(c.UserContext()).SetAttributes(attribute.Int64("enduser.id", userID))
+}
+
+// headerCarrier reads propagation headers off a Fiber request.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key string, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for key := range headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,15 @@
+package tus
+
+import "errors"
+
+var (
+	ErrUploadNotFound      = errors.New("upload not found")
+	ErrUploadExpired       = errors.New("upload has expired")
+	ErrOffsetMismatch      = errors.New("upload offset does not match current offset")
+	ErrMaxSizeExceeded     = errors.New("upload size exceeds maximum allowed size")
+	ErrUploadLengthInvalid = errors.New("upload length invalid: must be a positive number")
+	ErrUploadOffsetInvalid = errors.New("upload offset invalid: must be a number")
+	ErrMetadataInvalid     = errors.New("upload metadata invalid")
+	ErrContentTypeInvalid  = errors.New("content type must be " + ContentType)
+	ErrVersionNotSupported = errors.New("tus version not supported")
+)
@@ -0,0 +1,76 @@
+package tus
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+)
+
+const (
+	Version     = "1.0.0"
+	Extensions  = "creation,termination,expiration"
+	ContentType = "application/offset+octet-stream"
+
+	HeaderResumable   = "Tus-Resumable"
+	HeaderVersion     = "Tus-Version"
+	HeaderExtension   = "Tus-Extension"
+	HeaderMaxSize     = "Tus-Max-Size"
+	HeaderOffset      = "Upload-Offset"
+	HeaderLength      = "Upload-Length"
+	HeaderMetadata    = "Upload-Metadata"
+	HeaderExpires     = "Upload-Expires"
+	ExpiresTimeFormat = "Mon, 02 Jan 2006 15:04:05 GMT"
+)
+
+// ExposedHeaders lists the response headers browser clients need to read.
+var ExposedHeaders = []string{
+	HeaderResumable, HeaderVersion, HeaderExtension, HeaderMaxSize,
+	HeaderOffset, HeaderLength, HeaderMetadata, HeaderExpires, "Location",
+}
+
+// ParseMetadata decodes an Upload-Metadata header, a comma separated list of
+// "key base64(value)" pairs where the value may be omitted.
+func ParseMetadata(header string) (map[string]string, error) {
+	meta := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 || len(parts) > 2 {
+			return nil, ErrMetadataInvalid
+		}
+
+		value := ""
+		if len(parts) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, ErrMetadataInvalid
+			}
+			value = string(decoded)
+		}
+		meta[parts[0]] = value
+	}
+
+	return meta, nil
+}
+
+func EncodeMetadata(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for key := range meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if meta[key] == "" {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(meta[key])))
+	}
+
+	return strings.Join(pairs, ",")
+}